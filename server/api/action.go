@@ -0,0 +1,79 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// actionSwap handles the Accept and Decline buttons of a swap request. The
+// post is updated with the outcome; errors are shown to the user as an
+// ephemeral message, leaving the buttons in place.
+func (s *Service) actionSwap(w http.ResponseWriter, r *http.Request) {
+	mattermostUserID := r.Header.Get("Mattermost-User-ID")
+	if mattermostUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+	request := model.PostActionIntegrationRequestFromJson(r.Body)
+	if request == nil || request.UserId != mattermostUserID {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	swapID, _ := request.Context["swap_id"].(string)
+	accept, _ := request.Context["accept"].(bool)
+
+	in := sl.InRespondSwap{
+		SwapID: types.ID(swapID),
+		Accept: accept,
+	}
+	withNow(&in.Time)
+
+	response := &model.PostActionIntegrationResponse{}
+	out, err := s.sl.ActingAs(types.ID(mattermostUserID)).RespondSwap(in)
+	if err != nil {
+		response.EphemeralText = "Error: " + err.Error()
+	} else {
+		response.Update = &model.Post{
+			Message: out.MD.String(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(response.ToJson())
+}
+
+// actionVolunteer handles the Volunteer button of a pending task
+// announcement. The outcome is shown to the user as an ephemeral message.
+func (s *Service) actionVolunteer(w http.ResponseWriter, r *http.Request) {
+	mattermostUserID := r.Header.Get("Mattermost-User-ID")
+	if mattermostUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+	request := model.PostActionIntegrationRequestFromJson(r.Body)
+	if request == nil || request.UserId != mattermostUserID {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	taskID, _ := request.Context["task_id"].(string)
+
+	response := &model.PostActionIntegrationResponse{}
+	out, err := s.sl.ActingAs(types.ID(mattermostUserID)).Volunteer(sl.InVolunteer{
+		TaskID: types.ID(taskID),
+	})
+	if err != nil {
+		response.EphemeralText = "Error: " + err.Error()
+	} else {
+		response.EphemeralText = out.MD.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(response.ToJson())
+}
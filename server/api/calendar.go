@@ -0,0 +1,37 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/ics"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// calendarFeed serves the user's calendar, or a rotation's tasks, as an
+// iCalendar feed. The calendar apps subscribe without a Mattermost session,
+// so the request is authenticated with the user's calendar token in the
+// URL.
+func (s *Service) calendarFeed(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	out, err := s.sl.ActingAs(types.ID(vars["userID"])).ExportCalendar(sl.InExportCalendar{
+		Token:      vars["token"],
+		RotationID: types.ID(vars["rotationID"]),
+	})
+	if err == sl.ErrInvalidCalendarToken {
+		s.handleErrorWithCode(w, http.StatusUnauthorized, "Not authorized", err)
+		return
+	}
+	if err != nil {
+		s.handleErrorWithCode(w, http.StatusNotFound, "Failed to export calendar", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	_ = ics.Write(w, out.Name, out.Events)
+}
@@ -16,17 +16,24 @@ func (s *Service) executeCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	args := model.CommandArgsFromJson(r.Body)
+	if args == nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	// Act as the authenticated user, not as the one in the request body.
+	args.UserId = userID
 
 	command := command.Command{
 		Context:   &plugin.Context{},
 		Args:      args,
 		ChannelID: args.ChannelId,
-		SL:        s.sl.ActingAs(types.ID(args.UserId)),
+		SL:        s.sl.ActingAs(types.ID(userID)),
 	}
 	out, err := command.Handle()
-	if err == nil {
-		s.sl.Logger.Errorf("Error while handling command", err.Error())
-		s.handleErrorWithCode(w, http.StatusInternalServerError, "Error while handling command", err)
+	if err != nil {
+		s.sl.Logger.Errorf("Error while handling command: %v", err)
+		s.handleErrorWithCode(w, errorCode(err), "Error while handling command", err)
+		return
 	}
 
 	w.Write([]byte(out.String()))
@@ -0,0 +1,174 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// maxRequestSize limits the size of the REST API request bodies.
+const maxRequestSize = 1024 * 1024
+
+// restHandler serves a REST API request, acting as the requesting user. The
+// returned value is sent as JSON.
+type restHandler func(SL sl.SL, r *http.Request) (interface{}, error)
+
+// statusError is an error with the HTTP status code to respond with.
+type statusError struct {
+	error
+	code int
+}
+
+func (e *statusError) Cause() error {
+	return e.error
+}
+
+func newStatusError(code int, err error) error {
+	return &statusError{error: err, code: code}
+}
+
+func (s *Service) initREST(apiRouter *mux.Router) {
+	for _, route := range []struct {
+		method, path string
+		handler      restHandler
+	}{
+		{"GET", "/rotations", restListRotations},
+		{"POST", "/rotations", restCreateRotation},
+		{"GET", "/rotations/{rotationID}", restGetRotation},
+		{"PATCH", "/rotations/{rotationID}", restUpdateRotation},
+		{"POST", "/rotations/{rotationID}/archive", restArchiveRotation},
+		{"POST", "/rotations/{rotationID}/forecast", restForecast},
+		{"GET", "/rotations/{rotationID}/autopilot", restAutopilotHistory},
+		{"POST", "/rotations/{rotationID}/autopilot", restRunAutopilot},
+		{"POST", "/rotations/{rotationID}/shifts", restCreateShift},
+		{"POST", "/rotations/{rotationID}/tickets", restCreateTicket},
+		{"POST", "/autopilot", restRunAutopilotAll},
+
+		{"GET", "/tasks/{taskID}", restGetTask},
+		{"POST", "/tasks/{taskID}/assign", restAssignTask},
+		{"POST", "/tasks/{taskID}/unassign", restUnassignTask},
+		{"POST", "/tasks/{taskID}/fill", restFillTask},
+		{"POST", "/tasks/{taskID}/transition", restTransitionTask},
+		{"POST", "/tasks/{taskID}/swap", restRequestSwap},
+		{"POST", "/tasks/{taskID}/volunteer", restVolunteer},
+		{"POST", "/swaps/{swapID}", restRespondSwap},
+
+		{"GET", "/users", restGetUsers},
+		{"POST", "/users/join", restJoinRotation},
+		{"POST", "/users/leave", restLeaveRotation},
+		{"POST", "/users/qualify", restQualify},
+		{"POST", "/users/disqualify", restDisqualify},
+		{"POST", "/users/hours", restSetWorkingHours},
+		{"POST", "/users/holidays", restSetUserHolidays},
+		{"POST", "/users/unavailable", restAddToCalendar},
+		{"POST", "/users/unavailable/clear", restClearCalendar},
+		{"POST", "/users/history", restServiceHistory},
+
+		{"GET", "/skills", restListSkills},
+		{"POST", "/skills", restAddSkill},
+		{"DELETE", "/skills/{skill}", restDeleteSkill},
+		{"GET", "/skills/tree", restGetSkillTree},
+		{"PUT", "/skills/{skill}/parent", restSetSkillParent},
+		{"POST", "/skills/audit", restAuditSkills},
+	} {
+		apiRouter.HandleFunc(route.path, s.rest(route.handler)).Methods(route.method)
+	}
+}
+
+// rest authenticates the request by the Mattermost-User-ID header, and
+// responds with the handler's result, or error as JSON.
+func (s *Service) rest(handler restHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mattermostUserID := r.Header.Get("Mattermost-User-ID")
+		if mattermostUserID == "" {
+			s.handleErrorWithCode(w, http.StatusUnauthorized, "Not authorized", errors.New("Mattermost-User-ID is required"))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+
+		out, err := handler(s.sl.ActingAs(types.ID(mattermostUserID)), r)
+		if err != nil {
+			code := errorCode(err)
+			s.handleErrorWithCode(w, code, http.StatusText(code), err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	}
+}
+
+// errorCode maps the errors to the HTTP status codes. The errors not
+// otherwise known are the invalid requests.
+func errorCode(err error) int {
+	if se, ok := err.(*statusError); ok {
+		return se.code
+	}
+	switch errors.Cause(err) {
+	case kvstore.ErrNotFound:
+		return http.StatusNotFound
+	case sl.ErrAlreadyExists, sl.ErrWrongState:
+		return http.StatusConflict
+	case sl.ErrInvalidCalendarToken:
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
+
+// decode reads the JSON request body into in. An empty body leaves in
+// unchanged.
+func decode(r *http.Request, in interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(in)
+	if err != nil && err != io.EOF {
+		return newStatusError(http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+	}
+	return nil
+}
+
+// withNow sets the zero time to now.
+func withNow(t *types.Time) {
+	if t.IsZero() {
+		*t = types.NewTime(time.Now())
+	}
+}
+
+// withActingUser sets the empty users to the acting user.
+func withActingUser(SL sl.SL, mattermostUserIDs **types.IDSet) error {
+	if *mattermostUserIDs != nil && !(*mattermostUserIDs).IsEmpty() {
+		return nil
+	}
+	user, err := SL.ActingUser()
+	if err != nil {
+		return err
+	}
+	*mattermostUserIDs = types.NewIDSet(user.MattermostUserID)
+	return nil
+}
+
+// rotationID returns the rotation from the request path, if it is active.
+func rotationID(SL sl.SL, r *http.Request) (types.ID, error) {
+	id := types.ID(mux.Vars(r)["rotationID"])
+	active, err := SL.LoadActiveRotations()
+	if err != nil {
+		return "", err
+	}
+	if !active.Contains(id) {
+		return "", newStatusError(http.StatusNotFound, errors.Errorf("rotation %s is not found", id))
+	}
+	return id, nil
+}
+
+func taskID(r *http.Request) types.ID {
+	return types.ID(mux.Vars(r)["taskID"])
+}
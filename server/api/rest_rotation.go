@@ -0,0 +1,263 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/queue"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/solarlottery"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func restListRotations(SL sl.SL, r *http.Request) (interface{}, error) {
+	return SL.LoadActiveRotations()
+}
+
+type restRotation struct {
+	*sl.Rotation
+
+	// Name of the new rotation, the RotationID is made from it.
+	Name string
+}
+
+// restCreateRotation creates a rotation with the settings in the request.
+// The defaults are the same as /lotto rotation new's.
+func restCreateRotation(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := restRotation{Rotation: sl.NewRotation()}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name == "" {
+		return nil, errors.New("must specify rotation name")
+	}
+
+	rotation, err := SL.MakeRotation(in.Name)
+	if err != nil {
+		return nil, err
+	}
+	// The users, and the tasks are added by their own endpoints.
+	in.Rotation.RotationID = rotation.RotationID
+	in.Rotation.IsArchived = false
+	in.Rotation.MattermostUserIDs = types.NewIDSet()
+	in.Rotation.TraineeMattermostUserIDs = types.NewIDSet()
+	in.Rotation.TaskIDs = types.NewIDSet()
+	in.Rotation.TaskSettings.Seq = 0
+	in.Rotation.Init()
+	if in.Rotation.FillerType == "" {
+		in.Rotation.FillerType = solarlottery.Type
+	}
+	if in.Rotation.TaskType == "" {
+		in.Rotation.TaskType = sl.TaskTypeShift
+	}
+	if in.Rotation.FillSettings.Beginning.IsZero() {
+		in.Rotation.FillSettings.Beginning = types.NewTime(time.Now())
+	}
+	if in.Rotation.FillSettings.Period.Period == "" {
+		in.Rotation.FillSettings.Period.Period = types.EveryWeek
+	}
+	if in.Rotation.TaskSettings.Require.IsEmpty() {
+		in.Rotation.TaskSettings.Require.Set(sl.NeedOneAnyLevel)
+	}
+	err = validateRotation(SL, in.Rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	err = SL.AddRotation(in.Rotation)
+	if err != nil {
+		return nil, err
+	}
+	return in.Rotation, nil
+}
+
+func restGetRotation(SL sl.SL, r *http.Request) (interface{}, error) {
+	rotationID, err := rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	return SL.LoadRotation(rotationID)
+}
+
+// restUpdateRotation updates the rotation's settings with the ones in the
+// request. The users, and the tasks are managed by their own endpoints.
+func restUpdateRotation(SL sl.SL, r *http.Request) (interface{}, error) {
+	rotationID, err := rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	return SL.UpdateRotation(rotationID, func(rotation *sl.Rotation) error {
+		orig := *rotation
+		err := decode(r, rotation)
+		if err != nil {
+			return err
+		}
+		rotation.RotationID = orig.RotationID
+		rotation.IsArchived = orig.IsArchived
+		rotation.MattermostUserIDs = orig.MattermostUserIDs
+		rotation.TraineeMattermostUserIDs = orig.TraineeMattermostUserIDs
+		rotation.TaskIDs = orig.TaskIDs
+		rotation.TaskSettings.Seq = orig.TaskSettings.Seq
+		rotation.Init()
+		return validateRotation(SL, rotation)
+	})
+}
+
+// validateRotation checks the settings the same as the /lotto rotation
+// commands do, since the request may set any of them.
+func validateRotation(SL sl.SL, r *sl.Rotation) error {
+	switch r.FillerType {
+	case solarlottery.Type, queue.Type:
+	default:
+		return errors.Errorf("%s is not a valid filler type, please use %s or %s", r.FillerType, solarlottery.Type, queue.Type)
+	}
+	switch r.TaskType {
+	case sl.TaskTypeShift, sl.TaskTypeTicket:
+	default:
+		return errors.Errorf("%s is not a valid task type, please use %s or %s", r.TaskType, sl.TaskTypeShift, sl.TaskTypeTicket)
+	}
+
+	period := r.FillSettings.Period
+	switch period.Period {
+	case types.EveryDay, types.EveryWeek, types.EveryTwoWeeks, types.EveryMonth:
+	case types.EveryDuration:
+		if period.Duration <= 0 {
+			return errors.Errorf("invalid period duration %v", period.Duration)
+		}
+	case types.EveryRRule:
+		_, err := types.ParseRRule(period.RRule)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("invalid period %s", period.Period)
+	}
+	if r.FillSettings.WorkingHours != "" && !sl.WorkingHoursPolicies.Contains(r.FillSettings.WorkingHours) {
+		return errors.Errorf("invalid working hours policy %s", r.FillSettings.WorkingHours)
+	}
+	if r.FillSettings.Volunteers != "" && !sl.VolunteerPolicies.Contains(r.FillSettings.Volunteers) {
+		return errors.Errorf("invalid volunteer policy %s", r.FillSettings.Volunteers)
+	}
+
+	ts := r.TaskSettings
+	if ts.Conflicts != "" && !sl.ConflictPolicies.Contains(ts.Conflicts) {
+		return errors.Errorf("invalid conflict policy %s", ts.Conflicts)
+	}
+	if ts.HolidayPolicy != "" && !sl.HolidayPolicies.Contains(ts.HolidayPolicy) {
+		return errors.Errorf("invalid holiday policy %s", ts.HolidayPolicy)
+	}
+	if ts.Holidays != "" {
+		_, err := SL.LoadHolidayCalendar(ts.Holidays)
+		if err != nil {
+			return err
+		}
+	}
+	if ts.Workload.MaxTasks < 0 || ts.Workload.MaxHours < 0 || ts.Workload.Window < 0 {
+		return errors.New("workload cap can not be negative")
+	}
+	if (ts.Workload.MaxTasks > 0 || ts.Workload.MaxHours > 0) && ts.Workload.Window == 0 {
+		return errors.New("workload cap requires a window")
+	}
+	return nil
+}
+
+func restArchiveRotation(SL sl.SL, r *http.Request) (interface{}, error) {
+	rotationID, err := rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	return SL.ArchiveRotation(rotationID)
+}
+
+func restForecast(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InForecast{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.RotationID, err = rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	return SL.Forecast(in)
+}
+
+func restAutopilotHistory(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InAutopilotHistory{}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, newStatusError(http.StatusBadRequest, errors.Wrap(err, "invalid limit"))
+		}
+		in.Limit = n
+	}
+	var err error
+	in.RotationID, err = rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	return SL.LoadAutopilotHistory(in)
+}
+
+func restRunAutopilot(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := &sl.InRunAutopilot{}
+	err := decode(r, in)
+	if err != nil {
+		return nil, err
+	}
+	in.RotationID, err = rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	// Only the plugin's own ticks are scheduled.
+	in.Trigger = sl.AutopilotTriggerManual
+	return SL.RunAutopilot(in)
+}
+
+func restRunAutopilotAll(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := &sl.InRunAutopilotAll{}
+	err := decode(r, in)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	in.Trigger = sl.AutopilotTriggerManual
+	return SL.RunAutopilotAll(in)
+}
+
+func restCreateShift(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InCreateShift{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.RotationID, err = rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	return SL.CreateShift(in)
+}
+
+func restCreateTicket(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InCreateTicket{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.RotationID, err = rotationID(SL, r)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	return SL.CreateTicket(in)
+}
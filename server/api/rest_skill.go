@@ -0,0 +1,76 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func restListSkills(SL sl.SL, r *http.Request) (interface{}, error) {
+	return SL.ListKnownSkills()
+}
+
+func restAddSkill(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := struct {
+		Skill types.ID
+	}{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	if in.Skill == "" {
+		return nil, errors.New("must specify skill")
+	}
+	err = SL.AddKnownSkill(in.Skill)
+	if err != nil {
+		return nil, err
+	}
+	return SL.ListKnownSkills()
+}
+
+func restDeleteSkill(SL sl.SL, r *http.Request) (interface{}, error) {
+	err := SL.DeleteKnownSkill(types.ID(mux.Vars(r)["skill"]))
+	if err != nil {
+		return nil, err
+	}
+	return SL.ListKnownSkills()
+}
+
+func restGetSkillTree(SL sl.SL, r *http.Request) (interface{}, error) {
+	return SL.LoadSkillTree()
+}
+
+// restSetSkillParent makes the skill imply the parent skill, or removes its
+// parent if empty.
+func restSetSkillParent(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := struct {
+		Parent      types.ID
+		LevelOffset int64
+	}{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	err = SL.SetSkillParent(types.ID(mux.Vars(r)["skill"]), in.Parent, in.LevelOffset)
+	if err != nil {
+		return nil, err
+	}
+	return SL.LoadSkillTree()
+}
+
+func restAuditSkills(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InAuditSkills{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	return SL.AuditSkills(in)
+}
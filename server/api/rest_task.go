@@ -0,0 +1,93 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func restGetTask(SL sl.SL, r *http.Request) (interface{}, error) {
+	return SL.LoadTask(taskID(r))
+}
+
+func restAssignTask(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InAssignTask{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.TaskID = taskID(r)
+	withNow(&in.Time)
+	return SL.AssignTask(in)
+}
+
+func restUnassignTask(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InAssignTask{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.TaskID = taskID(r)
+	withNow(&in.Time)
+	return SL.UnassignTask(in)
+}
+
+func restFillTask(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InFillTask{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.TaskID = taskID(r)
+	withNow(&in.Time)
+	return SL.FillTask(in)
+}
+
+func restTransitionTask(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InTransitionTask{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.TaskID = taskID(r)
+	withNow(&in.Time)
+	return SL.TransitionTask(in)
+}
+
+func restRequestSwap(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InRequestSwap{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.TaskID = taskID(r)
+	withNow(&in.Time)
+	return SL.RequestSwap(in)
+}
+
+func restVolunteer(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InVolunteer{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.TaskID = taskID(r)
+	return SL.Volunteer(in)
+}
+
+func restRespondSwap(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InRespondSwap{}
+	err := decode(r, &in)
+	if err != nil {
+		return nil, err
+	}
+	in.SwapID = types.ID(mux.Vars(r)["swapID"])
+	withNow(&in.Time)
+	return SL.RespondSwap(in)
+}
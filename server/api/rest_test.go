@@ -0,0 +1,212 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/config"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/solarlottery"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/mock_sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func getTestAPI(t testing.TB, ctrl *gomock.Controller) *Service {
+	pluginAPI := mock_sl.NewMockPluginAPI(ctrl)
+	pluginAPI.EXPECT().GetMattermostUser(gomock.Any()).AnyTimes().DoAndReturn(func(id string) (*model.User, error) {
+		return &model.User{
+			Id:       id,
+			Username: id,
+			Timezone: model.StringMap{
+				"useAutomaticTimezone": "false",
+				"manualTimezone":       "America/Los_Angeles",
+			},
+		}, nil
+	})
+
+	pluginAPI.EXPECT().GetMattermostUserByUsername(gomock.Any()).AnyTimes().DoAndReturn(func(username string) (*model.User, error) {
+		return &model.User{
+			Id:       username,
+			Username: username,
+			Timezone: model.StringMap{
+				"useAutomaticTimezone": "false",
+				"manualTimezone":       "America/Los_Angeles",
+			},
+		}, nil
+	})
+
+	conf := config.NewTestService(&config.Config{
+		StoredConfig: &config.StoredConfig{},
+		BuildConfig: &config.BuildConfig{
+			PluginVersion: "test-plugin-version",
+		},
+		PluginURL: "https://pluginurl",
+	})
+	service := sl.Service{
+		PluginAPI: pluginAPI,
+		Config:    conf,
+		TaskFillers: map[types.ID]sl.TaskFiller{
+			solarlottery.Type: solarlottery.New(),
+		},
+		Logger: &bot.NilLogger{},
+		Poster: &bot.NilPoster{},
+		Store:  kvstore.NewStore(kvstore.NewCacheKVStore(nil)),
+	}
+	return NewService(conf, mux.NewRouter(), service)
+}
+
+// do sends the request as test-user.
+func do(s *Service, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, PathAPI+path, strings.NewReader(body))
+	r.Header.Set("Mattermost-User-ID", "test-user")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+// mustDo sends the request as test-user, and decodes the JSON response into
+// out.
+func mustDo(t testing.TB, s *Service, method, path, body string, out interface{}) {
+	w := do(s, method, path, body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	if out != nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), out), w.Body.String())
+	}
+}
+
+func TestREST(t *testing.T) {
+	t.Run("not authorized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s := getTestAPI(t, ctrl)
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", PathAPI+"/rotations", nil))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("rotation, and task lifecycle", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s := getTestAPI(t, ctrl)
+
+		r := sl.NewRotation()
+		mustDo(t, s, "POST", "/rotations", `{"Name": "test-rotation", "FillSettings": {"Beginning": "2020-03-01T17:00:00Z"}}`, r)
+		require.Equal(t, types.ID("test-rotation"), r.RotationID)
+		require.Equal(t, sl.TaskTypeShift, r.TaskType)
+		require.Equal(t, types.EveryWeek, r.FillSettings.Period.Period)
+
+		ids := types.NewIDSet()
+		mustDo(t, s, "GET", "/rotations", "", ids)
+		require.Equal(t, []types.ID{"test-rotation"}, ids.IDs())
+		require.Equal(t, http.StatusNotFound, do(s, "GET", "/rotations/nothing", "").Code)
+
+		r = sl.NewRotation()
+		mustDo(t, s, "PATCH", "/rotations/test-rotation", `{"RotationID": "other", "TaskSettings": {"Duration": 28800000000000}}`, r)
+		require.Equal(t, types.ID("test-rotation"), r.RotationID)
+		require.Equal(t, 8*time.Hour, r.TaskSettings.Duration)
+
+		joined := sl.OutJoinRotation{Modified: sl.NewUsers()}
+		mustDo(t, s, "POST", "/users/join", `{"RotationID": "test-rotation", "MattermostUserIDs": ["test-user1", "test-user2"], "Starting": "2020-01-01T00:00:00Z"}`, &joined)
+		require.Equal(t, []types.ID{"test-user1", "test-user2"}, joined.Modified.IDs())
+
+		created := sl.OutCreateTask{}
+		mustDo(t, s, "POST", "/rotations/test-rotation/shifts", `{"Number": 1}`, &created)
+		require.Equal(t, types.ID("test-rotation#1"), created.Task.TaskID)
+		require.Equal(t, "2020-03-08T17:00", created.Task.ExpectedStart.String())
+
+		filled := sl.OutFillTask{Changed: sl.NewUsers()}
+		mustDo(t, s, "POST", "/tasks/test-rotation%231/fill", "", &filled)
+		require.Equal(t, 1, filled.Task.MattermostUserIDs.Len())
+
+		transitioned := sl.OutTransitionTask{}
+		mustDo(t, s, "POST", "/tasks/test-rotation%231/transition", `{"State": "scheduled"}`, &transitioned)
+		require.Equal(t, sl.TaskStateScheduled, transitioned.Task.State)
+		require.Equal(t, http.StatusConflict, do(s, "POST", "/tasks/test-rotation%231/fill", "").Code)
+
+		task := sl.NewTask("")
+		mustDo(t, s, "GET", "/tasks/test-rotation%231", "", task)
+		require.Equal(t, sl.TaskStateScheduled, task.State)
+		require.Equal(t, http.StatusNotFound, do(s, "GET", "/tasks/test-rotation%2399", "").Code)
+	})
+
+	t.Run("rotation settings are validated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s := getTestAPI(t, ctrl)
+
+		for _, body := range []string{
+			`{"Name": "test-rotation", "FillerType": "magic"}`,
+			`{"Name": "test-rotation", "FillSettings": {"WorkingHours": "sometimes"}}`,
+			`{"Name": "test-rotation", "TaskSettings": {"HolidayPolicy": "party"}}`,
+			`{"Name": "test-rotation", "TaskSettings": {"Holidays": "nowhere"}}`,
+			`{"Name": "test-rotation", "TaskSettings": {"Workload": {"MaxTasks": 2}}}`,
+		} {
+			require.Equal(t, http.StatusBadRequest, do(s, "POST", "/rotations", body).Code, body)
+		}
+		require.Equal(t, http.StatusNotFound, do(s, "GET", "/rotations/test-rotation", "").Code)
+
+		mustDo(t, s, "POST", "/rotations", `{"Name": "test-rotation"}`, sl.NewRotation())
+		require.Equal(t, http.StatusBadRequest, do(s, "PATCH", "/rotations/test-rotation", `{"FillSettings": {"Volunteers": "all"}}`).Code)
+		r := sl.NewRotation()
+		mustDo(t, s, "GET", "/rotations/test-rotation", "", r)
+		require.Equal(t, types.ID(""), r.FillSettings.Volunteers)
+
+		// Only the plugin's own runs are scheduled.
+		mustDo(t, s, "POST", "/rotations/test-rotation/autopilot", `{"Trigger": "scheduled"}`, &sl.OutRunAutopilot{})
+		h := &sl.OutAutopilotHistory{}
+		mustDo(t, s, "GET", "/rotations/test-rotation/autopilot", "", h)
+		require.Len(t, h.History.Runs, 1)
+		require.Equal(t, sl.AutopilotTriggerManual, h.History.Runs[0].Trigger)
+	})
+
+	t.Run("users, and skills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s := getTestAPI(t, ctrl)
+
+		skills := types.NewIDSet()
+		mustDo(t, s, "POST", "/skills", `{"Skill": "webapp"}`, skills)
+		require.Equal(t, []types.ID{"webapp"}, skills.IDs())
+
+		qualified := sl.OutQualify{Users: sl.NewUsers()}
+		mustDo(t, s, "POST", "/users/qualify", `{"SkillLevels": [{"Skill": "webapp", "Level": 2}]}`, &qualified)
+		require.Equal(t, []types.ID{"test-user"}, qualified.Users.IDs())
+
+		users := sl.NewUsers()
+		mustDo(t, s, "GET", "/users", "", users)
+		require.Equal(t, int64(2), users.Get("test-user").SkillLevels.Get("webapp"))
+
+		require.Equal(t, http.StatusBadRequest, do(s, "POST", "/users/unavailable", `{}`).Code)
+		require.Equal(t, http.StatusBadRequest, do(s, "POST", "/skills", `{"Skill":`).Code)
+		mustDo(t, s, "DELETE", "/skills/webapp", "", skills)
+		require.Empty(t, skills.IDs())
+	})
+
+	t.Run("execute command", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		s := getTestAPI(t, ctrl)
+
+		w := do(s, "POST", "/execute_command", `{"command": "/lotto nothing", "user_id": "someone-else"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "unknown command: nothing")
+
+		w = do(s, "POST", "/execute_command", `{"command": "/lotto skill list"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// restGetUsers returns the users in the "id" query parameters, or the
+// acting user.
+func restGetUsers(SL sl.SL, r *http.Request) (interface{}, error) {
+	ids := types.NewIDSet()
+	for _, id := range r.URL.Query()["id"] {
+		ids.Set(types.ID(id))
+	}
+	err := withActingUser(SL, &ids)
+	if err != nil {
+		return nil, err
+	}
+	return SL.LoadUsers(ids)
+}
+
+// The user endpoints act on the acting user, unless MattermostUserIDs are
+// specified.
+
+func restJoinRotation(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InJoinRotation{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Starting)
+	withNow(&in.Time)
+	return SL.JoinRotation(in)
+}
+
+func restLeaveRotation(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InJoinRotation{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	return SL.LeaveRotation(in)
+}
+
+func restQualify(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InQualify{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	withNow(&in.Time)
+	return SL.Qualify(in)
+}
+
+func restDisqualify(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InDisqualify{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	return SL.Disqualify(in)
+}
+
+func restSetWorkingHours(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InSetWorkingHours{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	return SL.SetWorkingHours(in)
+}
+
+func restSetUserHolidays(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InSetUserHolidays{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	return SL.SetUserHolidays(in)
+}
+
+func restAddToCalendar(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InAddToCalendar{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	if in.Unavailable == nil {
+		in.Unavailable = &sl.Unavailable{}
+	}
+	// Only personal events may be added, the others come with the tasks.
+	in.Unavailable.Reason = sl.ReasonPersonal
+	in.Unavailable.TaskID = ""
+	in.Unavailable.RotationID = ""
+	if in.Unavailable.IsEmpty() {
+		return nil, errors.New("unavailable interval is required")
+	}
+	return SL.AddToCalendar(in)
+}
+
+func restClearCalendar(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InClearCalendar{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	return SL.ClearCalendar(in)
+}
+
+func restServiceHistory(SL sl.SL, r *http.Request) (interface{}, error) {
+	in := sl.InServiceHistory{}
+	err := decodeUsers(SL, r, &in, &in.MattermostUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	return SL.LoadServiceHistory(in)
+}
+
+// decodeUsers reads the request body into in, and defaults its users to the
+// acting user. The users who are new to the plugin are added, as with
+// /lotto commands.
+func decodeUsers(SL sl.SL, r *http.Request, in interface{}, mattermostUserIDs **types.IDSet) error {
+	err := decode(r, in)
+	if err != nil {
+		return err
+	}
+	err = withActingUser(SL, mattermostUserIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range (*mattermostUserIDs).IDs() {
+		mattermostUser, err := SL.GetMattermostUser(string(id))
+		if err != nil {
+			return errors.WithMessagef(err, "failed to load user %s", id)
+		}
+		_, err = SL.LoadMattermostUserByUsername(mattermostUser.Username)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -10,12 +10,13 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/config"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/constants"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
 )
 
 const (
-	PathAPI        = "/api/v1"
-	PathPostAction = "/action"
+	PathAPI        = constants.PathAPI
+	PathPostAction = constants.PathPostAction
 	PathRespond    = "/respond"
 )
 
@@ -35,6 +36,14 @@ func NewService(config config.Service, router *mux.Router, sl sl.Service) *Servi
 	apiRouter := s.Router.PathPrefix(PathAPI).Subrouter()
 	apiRouter.HandleFunc("/authorized", s.apiGetAuthorized).Methods("GET")
 	apiRouter.HandleFunc("/execute_command", s.executeCommand).Methods("POST")
+	apiRouter.HandleFunc(constants.PathCalendar+"/{userID}/{token}/user.ics", s.calendarFeed).Methods("GET")
+	apiRouter.HandleFunc(constants.PathCalendar+"/{userID}/{token}/rotation/{rotationID}.ics", s.calendarFeed).Methods("GET")
+	apiRouter.HandleFunc("/unavailable/import", s.importUnavailable).Methods("POST")
+	s.initREST(apiRouter)
+
+	actionRouter := s.Router.PathPrefix(PathPostAction).Subrouter()
+	actionRouter.HandleFunc(constants.PathSwap, s.actionSwap).Methods("POST")
+	actionRouter.HandleFunc(constants.PathVolunteer, s.actionVolunteer).Methods("POST")
 
 	return s
 }
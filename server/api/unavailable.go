@@ -0,0 +1,65 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/ics"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// maxCalendarSize limits the size of the uploaded calendars.
+const maxCalendarSize = 10 * 1024 * 1024
+
+// importUnavailable imports the .ics calendar in the request body as the
+// user's personal unavailability. The all-day events are in the time zone
+// from the "timezone" query parameter, or the user's; the "calendar"
+// parameter names the calendar.
+func (s *Service) importUnavailable(w http.ResponseWriter, r *http.Request) {
+	mattermostUserID := r.Header.Get("Mattermost-User-ID")
+	if mattermostUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+	SL := s.sl.ActingAs(types.ID(mattermostUserID))
+
+	var loc *time.Location
+	var err error
+	if timeZone := r.URL.Query().Get("timezone"); timeZone != "" {
+		loc, err = time.LoadLocation(timeZone)
+	} else {
+		var user *sl.User
+		user, err = SL.ActingUser()
+		if err == nil {
+			loc = user.Time(types.NewTime(time.Now())).Location()
+		}
+	}
+	if err != nil {
+		s.handleErrorWithCode(w, http.StatusBadRequest, "Invalid time zone", err)
+		return
+	}
+
+	events, err := ics.Parse(http.MaxBytesReader(w, r.Body, maxCalendarSize), loc)
+	if err != nil {
+		s.handleErrorWithCode(w, http.StatusBadRequest, "Failed to parse calendar", err)
+		return
+	}
+
+	out, err := SL.ImportCalendar(sl.InImportCalendar{
+		MattermostUserIDs: types.NewIDSet(types.ID(mattermostUserID)),
+		Events:            events,
+		Time:              types.NewTime(time.Now()),
+		Source:            r.URL.Query().Get("calendar"),
+	})
+	if err != nil {
+		s.handleErrorWithCode(w, http.StatusInternalServerError, "Failed to import calendar", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(out.MD.String()))
+}
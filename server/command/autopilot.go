@@ -0,0 +1,21 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+)
+
+func (c *Command) autopilot(parameters []string) (md.MD, error) {
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+
+	return c.normalOut(
+		c.SL.RunAutopilotAll(&sl.InRunAutopilotAll{
+			Time: *c.now,
+		}))
+}
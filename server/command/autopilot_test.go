@@ -0,0 +1,48 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestAutopilot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	SL, store := getTestSL(t, ctrl)
+
+	mustRunMulti(t, SL, `
+		/lotto rotation new TEST1 --beginning=2020-01-05T09:30 --period=weekly
+		/lotto rotation set autopilot TEST1 --create --create-prior 300h
+		/lotto rotation new TEST2 --beginning=2020-01-05T09:30 --period=weekly
+		/lotto rotation set autopilot TEST2 --create --create-prior 100h
+		/lotto rotation new TEST3 --beginning=2020-01-05T09:30 --period=weekly
+	`)
+
+	out := &sl.OutRunAutopilotAll{}
+	_, err := runJSON(t, SL, `/lotto autopilot --now=2020-01-01T12:00`, out)
+	require.NoError(t, err)
+	require.Equal(t, []types.ID{"TEST1", "TEST2", "TEST3"}, out.Rotations.IDs())
+	require.Empty(t, out.Errors)
+
+	for _, id := range []types.ID{"TEST1#0", "TEST1#1", "TEST2#0"} {
+		task := sl.Task{}
+		err = store.Entity(sl.KeyTask).Load(id, &task)
+		require.NoError(t, err, id)
+		require.Equal(t, sl.TaskStatePending, task.State, id)
+	}
+	err = store.Entity(sl.KeyTask).Load("TEST2#1", &sl.Task{})
+	require.Error(t, err)
+	err = store.Entity(sl.KeyTask).Load("TEST3#0", &sl.Task{})
+	require.Error(t, err)
+
+	o := mustRun(t, SL, `/lotto autopilot --now=2020-01-01T12:00`)
+	require.Contains(t, o.String(), "Ran autopilot on 3 rotations for 2020-01-01T12:00, 0 failed.")
+}
@@ -11,11 +11,13 @@ import (
 
 func (c *Command) main(parameters []string) (md.MD, error) {
 	subcommands := map[string]func([]string) (md.MD, error){
-		"info":     c.info,
-		"rotation": c.rotation,
-		"skill":    c.skill,
-		"task":     c.task,
-		"user":     c.user,
+		"autopilot": c.autopilot,
+		"holiday":   c.holiday,
+		"info":      c.info,
+		"rotation":  c.rotation,
+		"skill":     c.skill,
+		"task":      c.task,
+		"user":      c.user,
 
 		"debug-log":   c.debugLog,
 		"debug-clean": c.debugClean,
@@ -29,6 +31,7 @@ func (c *Command) rotation(parameters []string) (md.MD, error) {
 		"archive":      c.rotationArchive,
 		"autopilot":    c.rotationAutopilot,
 		"debug-delete": c.rotationDebugDelete,
+		"forecast":     c.rotationForecast,
 		"list":         c.rotationList,
 		"new":          c.rotationNew,
 		"set":          c.rotationSet,
@@ -42,22 +45,30 @@ func (c *Command) rotationSet(parameters []string) (md.MD, error) {
 		"autopilot": c.rotationSetAutopilot,
 		"fill":      c.rotationSetFill,
 		"limit":     c.rotationSetLimit,
+		"pair":      c.rotationSetPair,
 		"require":   c.rotationSetRequire,
 		"task":      c.rotationSetTask,
+		"trainee":   c.rotationSetTrainee,
+		"webhook":   c.rotationSetWebhook,
+		"workload":  c.rotationSetWorkload,
 	}
 	return c.run(subcommands, parameters)
 }
 
 func (c *Command) task(parameters []string) (md.MD, error) {
 	subcommands := map[string]func([]string) (md.MD, error){
-		"assign":   c.taskAssign,
-		"unassign": c.taskUnassign,
-		"fill":     c.taskFill,
-		"schedule": c.taskTransition(sl.TaskStateScheduled),
-		"start":    c.taskTransition(sl.TaskStateStarted),
-		"finish":   c.taskTransition(sl.TaskStateFinished),
-		"new":      c.taskNew,
-		"show":     c.taskShow,
+		"assign":     c.taskAssign,
+		"unassign":   c.taskUnassign,
+		"fill":       c.taskFill,
+		"schedule":   c.taskTransition(sl.TaskStateScheduled),
+		"start":      c.taskTransition(sl.TaskStateStarted),
+		"finish":     c.taskTransition(sl.TaskStateFinished),
+		"cancel":     c.taskTransition(sl.TaskStateCancelled),
+		"unschedule": c.taskTransition(sl.TaskStatePending),
+		"new":        c.taskNew,
+		"show":       c.taskShow,
+		"swap":       c.taskSwap,
+		"volunteer":  c.taskVolunteer,
 	}
 	return c.run(subcommands, parameters)
 }
@@ -73,21 +84,38 @@ func (c *Command) taskNew(parameters []string) (md.MD, error) {
 
 func (c *Command) user(parameters []string) (md.MD, error) {
 	subcommands := map[string]func([]string) (md.MD, error){
+		"calendar":    c.userCalendar,
 		"disqualify":  c.userDisqualify,
+		"history":     c.userHistory,
 		"qualify":     c.userQualify,
 		"show":        c.userShow,
 		"unavailable": c.userUnavailable,
+		"holidays":    c.userHolidays,
+		"hours":       c.userHours,
 		"join":        c.userJoin,
 		"leave":       c.userLeave,
 	}
 	return c.run(subcommands, parameters)
 }
 
+func (c *Command) holiday(parameters []string) (md.MD, error) {
+	subcommands := map[string]func([]string) (md.MD, error){
+		"add":    c.holidayAdd,
+		"delete": c.holidayDelete,
+		"import": c.holidayImport,
+		"list":   c.holidayList,
+		"show":   c.holidayShow,
+	}
+	return c.run(subcommands, parameters)
+}
+
 func (c *Command) skill(parameters []string) (md.MD, error) {
 	subcommands := map[string]func([]string) (md.MD, error){
 		"new":    c.skillNew,
 		"delete": c.skillDelete,
 		"list":   c.skillList,
+		"set":    c.skillSet,
+		"audit":  c.skillAudit,
 	}
 	return c.run(subcommands, parameters)
 }
@@ -5,20 +5,24 @@ package command
 
 import (
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/config"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/queue"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/solarlottery"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/mock_sl"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/webhook"
 	"github.com/mattermost/mattermost-server/v5/model"
 )
 
@@ -58,6 +62,12 @@ func getTestSL(t testing.TB, ctrl *gomock.Controller) (sl.SL, kvstore.Store) {
 }
 
 func getTestSLWithPoster(t testing.TB, ctrl *gomock.Controller, poster bot.Poster) (sl.SL, kvstore.Store) {
+	serviceSL := getTestService(t, ctrl, poster)
+	return serviceSL.ActingAs("test-user"), serviceSL.Store
+}
+
+// getTestService returns the service, to act as users other than test-user.
+func getTestService(t testing.TB, ctrl *gomock.Controller, poster bot.Poster) *sl.Service {
 	pluginAPI := mock_sl.NewMockPluginAPI(ctrl)
 
 	pluginAPI.EXPECT().GetMattermostUser(gomock.Any()).AnyTimes().DoAndReturn(func(id string) (*model.User, error) {
@@ -85,7 +95,7 @@ func getTestSLWithPoster(t testing.TB, ctrl *gomock.Controller, poster bot.Poste
 		PluginAPI: pluginAPI,
 		Config:    config.NewTestService(&testConfig),
 		TaskFillers: map[types.ID]sl.TaskFiller{
-			// queue.Type:        queue.New(bot),
+			queue.Type:        queue.New(),
 			solarlottery.Type: solarlottery.New(),
 			"":                solarlottery.New(), // default
 		},
@@ -93,9 +103,15 @@ func getTestSLWithPoster(t testing.TB, ctrl *gomock.Controller, poster bot.Poste
 		Logger: &bot.NilLogger{},
 		Poster: poster,
 		Store:  kvstore.NewStore(kvstore.NewCacheKVStore(nil)),
+		Webhooks: &webhook.Sender{
+			Client:  &http.Client{Timeout: 5 * time.Second},
+			Logger:  &bot.NilLogger{},
+			Retries: 2,
+			Backoff: time.Millisecond,
+		},
 	}
 
-	return serviceSL.ActingAs("test-user"), serviceSL.Store
+	return serviceSL
 }
 
 func run(t testing.TB, sl sl.SL, cmd string) (md.MD, error) {
@@ -0,0 +1,202 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/httputils"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/ics"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// maxCalendarSize limits the size of the calendars fetched by URL.
+const maxCalendarSize = types.ByteSize(10 * 1024 * 1024)
+
+var calendarClient = &http.Client{Timeout: 30 * time.Second}
+
+func (c *Command) holidayImport(parameters []string) (md.MD, error) {
+	url := c.flags().String("url", "", "URL of the .ics calendar to import")
+	timeZone := c.flags().String("timezone", "", "time zone for the all-day holidays, as Europe/Berlin; defaults to yours")
+	replace := c.flags().Bool("replace", false, "remove the holidays that are not in the imported calendar")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if len(c.flags().Args()) != 1 {
+		return c.flagUsage(), errors.New("must specify the holiday calendar")
+	}
+	if *url == "" {
+		return c.flagUsage(), errors.New("--url is required")
+	}
+	loc, err := c.location(*timeZone)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+
+	events, err := fetchCalendar(*url, loc)
+	if err != nil {
+		return "", err
+	}
+	holidays := []*sl.Holiday{}
+	for _, e := range events {
+		holidays = append(holidays, &sl.Holiday{
+			UID:      e.UID,
+			Summary:  e.Summary,
+			Interval: types.NewInterval(types.NewTime(e.Start), types.NewTime(e.Finish)),
+		})
+	}
+
+	return c.normalOut(
+		c.SL.ImportHolidays(sl.InImportHolidays{
+			CalendarID: types.ID(c.flags().Arg(0)),
+			Holidays:   holidays,
+			Replace:    *replace,
+		}))
+}
+
+func (c *Command) holidayAdd(parameters []string) (md.MD, error) {
+	start, err := c.withTimeFlag("start", "start of the holiday, as 2020-12-25")
+	if err != nil {
+		return "", err
+	}
+	finish, err := c.withTimeFlag("finish", "end of the holiday (default: start + 24h)")
+	if err != nil {
+		return "", err
+	}
+	err = c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if len(c.flags().Args()) < 2 {
+		return c.flagUsage(), errors.New("must specify the holiday calendar, and the holiday's name")
+	}
+	if start.IsZero() {
+		return c.flagUsage(), errors.New("--start is required")
+	}
+	if finish.IsZero() {
+		*finish = types.NewTime(start.AddDate(0, 0, 1))
+	}
+
+	return c.normalOut(
+		c.SL.ImportHolidays(sl.InImportHolidays{
+			CalendarID: types.ID(c.flags().Arg(0)),
+			Holidays: []*sl.Holiday{
+				{
+					Summary:  strings.Join(c.flags().Args()[1:], " "),
+					Interval: types.NewInterval(*start, *finish),
+				},
+			},
+		}))
+}
+
+func (c *Command) holidayDelete(parameters []string) (md.MD, error) {
+	start, err := c.withTimeFlag("start", "remove the holidays from this time")
+	if err != nil {
+		return "", err
+	}
+	finish, err := c.withTimeFlag("finish", "remove the holidays up to this time")
+	if err != nil {
+		return "", err
+	}
+	err = c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if len(c.flags().Args()) != 1 {
+		return c.flagUsage(), errors.New("must specify the holiday calendar")
+	}
+	if start.IsZero() != finish.IsZero() {
+		return c.flagUsage(), errors.New("specify both --start and --finish, or neither to delete the calendar")
+	}
+
+	return c.normalOut(
+		c.SL.RemoveHolidays(sl.InRemoveHolidays{
+			CalendarID: types.ID(c.flags().Arg(0)),
+			Interval:   types.NewInterval(*start, *finish),
+		}))
+}
+
+func (c *Command) holidayList(parameters []string) (md.MD, error) {
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	calendars, err := c.SL.ListHolidayCalendars()
+	if err != nil {
+		return "", err
+	}
+	if c.outputJSON {
+		return md.JSONBlock(calendars), nil
+	}
+	if calendars.IsEmpty() {
+		return "No holiday calendars.", nil
+	}
+	out := md.MD("Holiday calendars:\n")
+	for _, id := range calendars.IDs() {
+		out += md.Markdownf("- %s\n", id)
+	}
+	return out, nil
+}
+
+func (c *Command) holidayShow(parameters []string) (md.MD, error) {
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if len(c.flags().Args()) != 1 {
+		return c.flagUsage(), errors.New("must specify the holiday calendar")
+	}
+	calendar, err := c.SL.LoadHolidayCalendar(types.ID(c.flags().Arg(0)))
+	if err != nil {
+		return "", err
+	}
+	if c.outputJSON {
+		return md.JSONBlock(calendar), nil
+	}
+	return calendar.MarkdownBullets(), nil
+}
+
+// location returns the time zone, or the acting user's if empty.
+func (c *Command) location(timeZone string) (*time.Location, error) {
+	if timeZone != "" {
+		return time.LoadLocation(timeZone)
+	}
+	actingUser, err := c.SL.ActingUser()
+	if err != nil {
+		return nil, err
+	}
+	return actingUser.Time(types.NewTime(time.Now())).Location(), nil
+}
+
+// fetchCalendar downloads, and parses the .ics calendar.
+func fetchCalendar(url string, loc *time.Location) ([]*ics.Event, error) {
+	resp, err := calendarClient.Get(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch calendar")
+	}
+	body := &httputils.LimitReadCloser{
+		ReadCloser: resp.Body,
+		Limit:      maxCalendarSize,
+	}
+	defer body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch calendar: %s", resp.Status)
+	}
+	events, err := ics.Parse(body, loc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse calendar")
+	}
+	// A truncated calendar would drop the events past the limit.
+	if body.TotalRead >= maxCalendarSize {
+		return nil, errors.Errorf("failed to fetch calendar: larger than %v", maxCalendarSize)
+	}
+	return events, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+const testHolidaysICS = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:christmas-2020@test
+DTSTART;VALUE=DATE:20201225
+DTEND;VALUE=DATE:20201226
+SUMMARY:Christmas Day
+END:VEVENT
+BEGIN:VEVENT
+UID:new-year-2021@test
+DTSTART;VALUE=DATE:20210101
+SUMMARY:New Year's Day
+END:VEVENT
+END:VCALENDAR
+`
+
+func serveICS(ics string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		_, _ = w.Write([]byte(ics))
+	}))
+}
+
+func TestHoliday(t *testing.T) {
+	t.Run("import, add, and delete", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		server := serveICS(testHolidaysICS)
+		defer server.Close()
+
+		out := mustRun(t, SL, `/lotto holiday import us --url `+server.URL+` --timezone America/Los_Angeles`)
+		require.Equal(t, "holiday calendar us: added 2, updated 0 holidays.", out.String())
+		out = mustRun(t, SL, `/lotto holiday import us --url `+server.URL+` --timezone America/Los_Angeles`)
+		require.Equal(t, "holiday calendar us: added 0, updated 2 holidays.", out.String())
+		mustRun(t, SL, `/lotto holiday add us Thanksgiving Day --start 2020-11-26`)
+
+		calendar := sl.HolidayCalendar{}
+		mustRunJSON(t, SL, `/lotto holiday show us`, &calendar)
+		require.Len(t, calendar.Holidays, 3)
+		require.Equal(t, "Thanksgiving Day", calendar.Holidays[0].Summary)
+		require.Equal(t, "2020-11-26T08:00", calendar.Holidays[0].Start.String())
+		require.Equal(t, "2020-11-27T08:00", calendar.Holidays[0].Finish.String())
+		require.Equal(t, "christmas-2020@test", calendar.Holidays[1].UID)
+		require.Equal(t, "2020-12-25T08:00", calendar.Holidays[1].Start.String())
+		require.Equal(t, "2021-01-02T08:00", calendar.Holidays[2].Finish.String())
+
+		out = mustRun(t, SL, `/lotto holiday delete us --start 2020-12-01 --finish 2020-12-31`)
+		require.Equal(t, "holiday calendar us: removed 1 holidays.", out.String())
+
+		out = mustRun(t, SL, `/lotto holiday list`)
+		require.Equal(t, "Holiday calendars:\n- us\n", out.String())
+		mustRun(t, SL, `/lotto holiday delete us`)
+		_, err := run(t, SL, `/lotto holiday show us`)
+		require.EqualError(t, err, "holiday calendar us is not found")
+	})
+
+	t.Run("autopilot skips holiday shifts", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		server := serveICS(testHolidaysICS)
+		defer server.Close()
+
+		mustRunMulti(t, SL, `
+			/lotto holiday import us --url `+server.URL+` --timezone America/Los_Angeles
+			/lotto rotation new test-rotation --beginning 2020-12-23T09:00 --period daily
+			/lotto rotation set task test-rotation --duration 8h --holidays us
+			/lotto rotation set autopilot test-rotation --create --create-prior 96h
+		`)
+
+		out := mustRun(t, SL, `/lotto rotation autopilot test-rotation --now 2020-12-23T08:00`)
+		require.Contains(t, out.String(), `  - create shift: created 3 shifts, skipped 1 on holidays:
+    - created shift test-rotation#0
+    - created shift test-rotation#1
+    - created shift test-rotation#3
+`)
+
+		_, err := run(t, SL, `/lotto task new shift test-rotation --number 2`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "it falls on holiday **Christmas Day**")
+	})
+
+	t.Run("forecast skips holiday shifts", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto holiday add us Christmas Day --start 2020-12-25
+			/lotto rotation new test-rotation --beginning 2020-12-23T09:00 --period daily
+			/lotto rotation set task test-rotation --duration 8h --holidays us
+			/lotto user join test-rotation @test-user1 --starting 2019-01-01
+		`)
+
+		out := sl.OutForecast{}
+		mustRunJSON(t, SL, `/lotto rotation forecast test-rotation --shifts 3 --now 2020-12-23T08:00 --commit`, &out)
+		require.Equal(t, 1, out.Skipped)
+		require.Len(t, out.Shifts, 3)
+		require.Equal(t, []int{0, 1, 3}, []int{out.Shifts[0].Number, out.Shifts[1].Number, out.Shifts[2].Number})
+		require.Equal(t, []types.ID{"test-rotation#0", "test-rotation#1", "test-rotation#3"}, out.Committed.IDs())
+	})
+
+	t.Run("mark holiday shifts", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto holiday add us Christmas Day --start 2020-12-25
+			/lotto rotation new test-rotation --beginning 2020-12-23T09:00 --period daily
+			/lotto rotation set task test-rotation --duration 8h --holidays us --holiday-policy mark
+		`)
+		task := mustRunTaskCreate(t, SL, `/lotto task new shift test-rotation --number 2`)
+		require.Equal(t, "Christmas Day", task.Holiday)
+		task = mustRunTaskCreate(t, SL, `/lotto task new shift test-rotation --number 1`)
+		require.Equal(t, "", task.Holiday)
+
+		_, err := run(t, SL, `/lotto rotation set task test-rotation --holidays nowhere`)
+		require.EqualError(t, err, "holiday calendar nowhere is not found")
+	})
+
+	t.Run("regional holidays", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto holiday add de Tag der Deutschen Einheit --start 2020-10-03
+			/lotto rotation new test-rotation --beginning 2020-10-01T09:00 --period daily
+			/lotto rotation set task test-rotation --duration 8h
+			/lotto user join test-rotation @test-user1 --starting 2019-01-01
+			/lotto user join test-rotation @test-user2 --starting 2020-09-01
+			/lotto user holidays @test-user1 --calendar de
+		`)
+		user := mustRunUser(t, SL, `/lotto user show @test-user1`)
+		require.Equal(t, "de", user.HolidayCalendarID.String())
+
+		// test-user1 has waited longer, but is on holiday on the 3rd.
+		mustRun(t, SL, `/lotto task new shift test-rotation --number 2`)
+		task := mustRunTaskAssign(t, SL, `/lotto task fill test-rotation#2`)
+		require.Equal(t, []types.ID{"test-user2"}, task.MattermostUserIDs.IDs())
+
+		mustRun(t, SL, `/lotto task new shift test-rotation --number 3`)
+		task = mustRunTaskAssign(t, SL, `/lotto task fill test-rotation#3`)
+		require.Equal(t, []types.ID{"test-user1"}, task.MattermostUserIDs.IDs())
+
+		mustRun(t, SL, `/lotto user holidays @test-user1 --clear`)
+		user = mustRunUser(t, SL, `/lotto user show @test-user1`)
+		require.Equal(t, "", user.HolidayCalendarID.String())
+	})
+}
@@ -9,6 +9,10 @@ import (
 )
 
 func (c *Command) rotationAutopilot(parameters []string) (md.MD, error) {
+	if len(parameters) > 0 && parameters[0] == "history" {
+		return c.rotationAutopilotHistory(parameters[1:])
+	}
+	dryRun := c.flags().Bool("dry-run", false, "show what autopilot would do, without saving the results or sending messages")
 	err := c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
@@ -22,5 +26,24 @@ func (c *Command) rotationAutopilot(parameters []string) (md.MD, error) {
 		c.SL.RunAutopilot(&sl.InRunAutopilot{
 			RotationID: rotationID,
 			Time:       *c.now,
+			DryRun:     *dryRun,
+		}))
+}
+
+func (c *Command) rotationAutopilotHistory(parameters []string) (md.MD, error) {
+	limit := c.flags().Int("limit", 10, "number of the most recent runs to show, 0 for all")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	rotationID, err := c.resolveRotation()
+	if err != nil {
+		return "", err
+	}
+
+	return c.normalOut(
+		c.SL.LoadAutopilotHistory(sl.InAutopilotHistory{
+			RotationID: rotationID,
+			Limit:      *limit,
 		}))
 }
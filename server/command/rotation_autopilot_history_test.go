@@ -0,0 +1,96 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestRotationAutopilotHistory(t *testing.T) {
+	ctrl, SL := defaultEnv(t)
+	defer ctrl.Finish()
+	mustRunMulti(t, SL, `
+		/lotto rotation new TEST --task-type=shift --beginning=2020-01-05T09:30 --period=biweekly --seed=873647632
+		/lotto rotation set require TEST --count 2
+		/lotto rotation set autopilot TEST --create --create-prior 800h --schedule --schedule-prior=100h --history-limit 2
+		/lotto user join TEST @test-user1 @test-user2 @test-user3 @test-user4 --starting 2020-01-01
+		/lotto rotation new OFF --task-type=shift --beginning=2020-01-05T09:30 --period=biweekly
+		`)
+
+	out, err := run(t, SL, `/lotto rotation autopilot history TEST`)
+	require.NoError(t, err)
+	require.Equal(t, "Autopilot has not run on TEST.", out.String())
+
+	mustRunMulti(t, SL, `
+		/lotto rotation autopilot TEST --now=2020-01-01T12:00
+		/lotto rotation autopilot TEST --now=2020-01-02T12:00
+		/lotto rotation autopilot TEST --dry-run --now=2020-01-02T13:00
+		`)
+	for _, now := range []string{"2020-01-03T12:00", "2020-01-14T12:00"} {
+		_, err = SL.RunAutopilotAll(&sl.InRunAutopilotAll{
+			Time:    types.MustParseTime(now),
+			Trigger: sl.AutopilotTriggerScheduled,
+		})
+		require.NoError(t, err)
+	}
+
+	// The dry run, and the scheduled runs with nothing to do are not
+	// recorded, and only the last 2 runs are kept.
+	h := &sl.OutAutopilotHistory{}
+	mustRunJSON(t, SL, `/lotto rotation autopilot history TEST`, h)
+	require.Len(t, h.History.Runs, 2)
+	// --now is in the acting user's time zone, PST.
+	require.Equal(t, types.MustParseTime("2020-01-02T20:00"), h.History.Runs[0].Time)
+	require.Equal(t, sl.AutopilotTriggerManual, h.History.Runs[0].Trigger)
+	require.Equal(t, sl.AutopilotTriggerScheduled, h.History.Runs[1].Trigger)
+	require.Len(t, h.History.Runs[0].Stages, 7)
+	require.Equal(t, &sl.AutopilotStage{
+		Name:   "fill and schedule",
+		Result: "fill and schedule: processed 1 tasks:\n    - Auto-assigned @test-user3 (none), @test-user1 (none) to ticket TEST#0, transitioned TEST#0 to scheduled",
+	}, h.History.Runs[0].Stages[3])
+
+	out, err = run(t, SL, `/lotto rotation autopilot history TEST --limit 1`)
+	require.NoError(t, err)
+	require.Equal(t, `Autopilot history of TEST, most recent first:
+- 2020-01-14T12:00 scheduled, by @test-user
+  - finish reminder: not configured
+  - finish: not configured
+  - create shift: created 1 shifts:
+      - created shift TEST#3
+  - fill and schedule: nothing to do
+  - start reminder: not configured
+  - start: not configured
+`, out.String())
+
+	out, err = run(t, SL, `/lotto rotation autopilot history OFF`)
+	require.NoError(t, err)
+	require.Equal(t, "Autopilot has not run on OFF.", out.String())
+}
+
+func TestRotationAutopilotHistoryError(t *testing.T) {
+	ctrl, SL := defaultEnv(t)
+	defer ctrl.Finish()
+	mustRunMulti(t, SL, `
+		/lotto rotation new TEST --task-type=shift --beginning=2020-01-05T09:30 --period=biweekly
+		/lotto rotation set autopilot TEST --create --create-prior 800h --schedule --schedule-prior=100h
+		`)
+
+	// Nobody to fill the shift with.
+	mustRun(t, SL, `/lotto rotation autopilot TEST --now=2020-01-01T12:00`)
+	_, err := run(t, SL, `/lotto rotation autopilot TEST --now=2020-01-02T12:00`)
+	require.Error(t, err)
+
+	h := &sl.OutAutopilotHistory{}
+	mustRunJSON(t, SL, `/lotto rotation autopilot history TEST`, h)
+	require.Len(t, h.History.Runs, 2)
+	failed := h.History.Runs[1]
+	require.Equal(t, err.Error(), failed.Error)
+	require.Len(t, failed.Stages, 4)
+	require.Equal(t, "fill and schedule", failed.Stages[3].Name)
+	require.NotEmpty(t, failed.Stages[3].Error)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
@@ -268,3 +269,57 @@ func TestRotationAutopilot(t *testing.T) {
 	checkNothing(`2020-01-31`)
 	checkNothing(`2020-02-01`)
 }
+
+func TestRotationAutopilotDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	poster := &bot.TestPoster{}
+	SL, store := getTestSLWithPoster(t, ctrl, poster)
+
+	mustRunMulti(t, SL, `
+		/lotto rotation new TEST --task-type=shift --beginning=2020-01-05T09:30 --period=biweekly --seed=873647632
+		/lotto rotation set task TEST --grace 400h
+		/lotto rotation set require TEST --count 2
+		/lotto rotation set autopilot TEST --create --create-prior 800h --schedule --schedule-prior=100h
+		/lotto user join TEST @test-user1 @test-user2 @test-user3 @test-user4 @test-user5 @test-user6 @test-user7 @test-user8 --starting 2020-01-01
+		`)
+	poster.Reset()
+
+	out := mustRun(t, SL, `/lotto rotation autopilot TEST --dry-run --now=2020-01-01T12:00`)
+	require.Equal(t, `Dry run: @test-user ran autopilot on TEST for 2020-01-01T12:00.
+  - finish reminder: not configured
+  - finish: not configured
+  - create shift: created 3 shifts:
+    - created shift TEST#0
+    - created shift TEST#1
+    - created shift TEST#2
+  - fill and schedule: nothing to do
+  - start reminder: not configured
+  - start: not configured`, out.String())
+	err := store.Entity(sl.KeyTask).Load("TEST#0", &sl.Task{})
+	require.Error(t, err)
+
+	mustRun(t, SL, `/lotto rotation autopilot TEST --now=2020-01-01T12:00`)
+	poster.Reset()
+
+	out = mustRun(t, SL, `/lotto rotation autopilot TEST --dry-run --now=2020-01-02T12:00`)
+	require.Equal(t, `Dry run: @test-user ran autopilot on TEST for 2020-01-02T12:00.
+  - finish reminder: not configured
+  - finish: not configured
+  - create shift: nothing to do
+  - fill and schedule: processed 1 tasks:
+    - Auto-assigned @test-user6 (none), @test-user2 (none) to ticket TEST#0, transitioned TEST#0 to scheduled
+  - start reminder: not configured
+  - start: not configured
+  - would send 2 messages:
+    - to @test-user6: You have been scheduled for TEST#0.
+    - to @test-user2: You have been scheduled for TEST#0.`, out.String())
+
+	// Nothing was saved, nor sent.
+	require.Equal(t, sl.TaskStatePending, mustRunTask(t, SL, `/lotto task show TEST#0`).State)
+	require.Empty(t, mustRunUser(t, SL, `/lotto user show @test-user6`).Calendar)
+	require.Empty(t, poster.DirectPosts)
+
+	// Running again gives the same result.
+	require.Equal(t, out, mustRun(t, SL, `/lotto rotation autopilot TEST --dry-run --now=2020-01-02T12:00`))
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+)
+
+func (c *Command) rotationForecast(parameters []string) (md.MD, error) {
+	c.withFlagRotation()
+	shifts := c.flags().IntP("shifts", "n", 4, "number of shifts to forecast")
+	commit := c.flags().Bool("commit", false, "create the forecast shifts as pending tasks, with the users assigned")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	rotationID, err := c.resolveRotation()
+	if err != nil {
+		return "", err
+	}
+
+	return c.normalOut(
+		c.SL.Forecast(sl.InForecast{
+			RotationID: rotationID,
+			Shifts:     *shifts,
+			Time:       *c.now,
+			Commit:     *commit,
+		}))
+}
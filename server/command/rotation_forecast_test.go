@@ -0,0 +1,123 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestRotationForecast(t *testing.T) {
+	forecastUsers := func(out *sl.OutForecast) [][]string {
+		users := [][]string{}
+		for _, shift := range out.Shifts {
+			users = append(users, shift.Task.MattermostUserIDs.TestIDs())
+		}
+		return users
+	}
+
+	t.Run("queue", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		SL, store := getTestSL(t, ctrl)
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning=2020-01-05 --period=weekly
+			/lotto rotation set fill test-rotation --filler queue
+			/lotto user join test-rotation @test-user1 @test-user2 @test-user3 --starting 2020-01-01
+			`)
+
+		out := &sl.OutForecast{}
+		mustRunJSON(t, SL, `/lotto rotation forecast test-rotation --shifts 4 --now 2020-01-01`, out)
+		require.Equal(t, [][]string{{"test-user1"}, {"test-user2"}, {"test-user3"}, {"test-user1"}}, forecastUsers(out))
+		require.Equal(t, "test-rotation#3", out.Shifts[3].Task.TaskID.String())
+
+		// Nothing is stored without --commit.
+		r := mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+		require.True(t, r.TaskIDs.IsEmpty())
+		require.Nil(t, r.FillSettings.Queue)
+		require.Empty(t, mustRunUser(t, SL, `/lotto user show @test-user1`).Calendar)
+
+		out = &sl.OutForecast{}
+		mustRunJSON(t, SL, `/lotto rotation forecast test-rotation --shifts 2 --commit --now 2020-01-01`, out)
+		require.Equal(t, []string{"test-rotation#0", "test-rotation#1"}, out.Committed.TestIDs())
+
+		task := sl.Task{}
+		err := store.Entity(sl.KeyTask).Load("test-rotation#1", &task)
+		require.NoError(t, err)
+		require.Equal(t, sl.TaskStatePending, task.State)
+		require.Equal(t, []string{"test-user2"}, task.MattermostUserIDs.TestIDs())
+
+		r = mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+		require.Equal(t, []string{"test-rotation#0", "test-rotation#1"}, r.TaskIDs.TestIDs())
+		require.Equal(t, []types.ID{"test-user3", "test-user1", "test-user2"}, r.FillSettings.Queue.IDs())
+
+		// The next forecast continues from the committed shifts.
+		out = &sl.OutForecast{}
+		mustRunJSON(t, SL, `/lotto rotation forecast test-rotation --shifts 3 --now 2020-01-01`, out)
+		require.Equal(t, [][]string{{"test-user1"}, {"test-user2"}, {"test-user3"}}, forecastUsers(out))
+		require.True(t, out.Shifts[0].Existing)
+		require.True(t, out.Shifts[1].Existing)
+		require.False(t, out.Shifts[2].Existing)
+	})
+
+	t.Run("carries grace forward", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning=2020-01-05 --period=weekly
+			/lotto rotation set task test-rotation --grace 400h
+			/lotto user join test-rotation @test-user1 @test-user2 @test-user3 --starting 2020-01-01
+			`)
+
+		out := &sl.OutForecast{}
+		mustRunJSON(t, SL, `/lotto rotation forecast test-rotation --shifts 4 --now 2020-01-01`, out)
+		users := forecastUsers(out)
+		require.ElementsMatch(t, []string{"test-user1", "test-user2", "test-user3"},
+			append(append(users[0], users[1]...), users[2]...))
+		require.Empty(t, users[3])
+		require.Contains(t, out.Shifts[3].Error, "insufficient")
+	})
+
+	t.Run("queue keeps only committed shifts", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning=2020-01-05 --period=weekly
+			/lotto rotation set fill test-rotation --filler queue
+			/lotto user join test-rotation @test-user1 @test-user2 @test-user3 --starting 2020-01-01
+			/lotto task new shift test-rotation --number 0
+			/lotto task assign test-rotation#0 @test-user1
+			`)
+
+		// test-rotation#0 is already filled, and is not committed, so it
+		// does not move test-user1 to the back of the queue.
+		out := &sl.OutForecast{}
+		mustRunJSON(t, SL, `/lotto rotation forecast test-rotation --shifts 2 --commit --now 2020-01-01`, out)
+		require.Equal(t, [][]string{{"test-user1"}, {"test-user1"}}, forecastUsers(out))
+		require.Equal(t, []string{"test-rotation#1"}, out.Committed.TestIDs())
+
+		r := mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+		require.Equal(t, []types.ID{"test-user2", "test-user3", "test-user1"}, r.FillSettings.Queue.IDs())
+	})
+
+	t.Run("error too many shifts", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRun(t, SL, `/lotto rotation new test-rotation --beginning=2020-01-05 --period=daily`)
+		_, err := run(t, SL, `/lotto rotation forecast test-rotation --shifts 1000000`)
+		require.EqualError(t, err, "number of shifts to forecast must be between 1 and 366, got 1000000")
+	})
+
+	t.Run("error tickets", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRun(t, SL, `/lotto rotation new test-rotation --task-type=ticket`)
+		_, err := run(t, SL, `/lotto rotation forecast test-rotation`)
+		require.Error(t, err)
+	})
+}
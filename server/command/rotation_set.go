@@ -4,8 +4,15 @@
 package command
 
 import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/queue"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/solarlottery"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
 func (c *Command) rotationSetAutopilot(parameters []string) (md.MD, error) {
@@ -19,6 +26,9 @@ func (c *Command) rotationSetAutopilot(parameters []string) (md.MD, error) {
 	remindStartPrior := c.flags().Duration("remind-start-prior", 0, "remind shift users this long before the shift's start")
 	remindFinish := c.flags().Bool("remind-finish", false, "remind shift users prior to finish")
 	remindFinishPrior := c.flags().Duration("remind-finish-prior", 0, "remind shift users this long before the shift's finish")
+	remindExpiry := c.flags().Bool("remind-expiry", false, "remind users, and the lead, prior to their qualifications expiring")
+	remindExpiryPrior := c.flags().Duration("remind-expiry-prior", 0, "remind users, and the lead, this long before their qualifications expire")
+	historyLimit := c.flags().Int("history-limit", 0, fmt.Sprintf("number of runs to keep in the autopilot history, default %v", sl.DefaultAutopilotHistoryLimit))
 	err := c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
@@ -47,6 +57,9 @@ func (c *Command) rotationSetAutopilot(parameters []string) (md.MD, error) {
 			r.AutopilotSettings.RemindStartPrior = *remindStartPrior
 			r.AutopilotSettings.RemindFinish = *remindFinish
 			r.AutopilotSettings.RemindFinishPrior = *remindFinishPrior
+			r.AutopilotSettings.RemindExpiry = *remindExpiry
+			r.AutopilotSettings.RemindExpiryPrior = *remindExpiryPrior
+			r.AutopilotSettings.HistoryLimit = *historyLimit
 			return nil
 		})
 	}
@@ -58,6 +71,11 @@ func (c *Command) rotationSetFill(parameters []string) (md.MD, error) {
 	c.withFlagRotation()
 	seed := c.flags().Int64("seed", intNoValue, "seed to use")
 	fuzz := c.flags().Int64("fuzz", intNoValue, `increase fill randomness`)
+	filler := c.flags().String("filler", "", fmt.Sprintf("filler type: %s or %s", solarlottery.Type, queue.Type))
+	workingHours := c.flags().String("working-hours", "", fmt.Sprintf("policy for users' working hours: %s, %s, or %s", sl.WorkingHoursIgnore, sl.WorkingHoursPrefer, sl.WorkingHoursRequire))
+	volunteers := c.flags().String("volunteers", "", fmt.Sprintf("policy for volunteers: %s, or %s", sl.VolunteerFirstCome, sl.VolunteerWeight))
+	period := types.Period{}
+	c.flags().Var(&period, "period", "recurrence period")
 	err := c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
@@ -66,15 +84,41 @@ func (c *Command) rotationSetFill(parameters []string) (md.MD, error) {
 	if err != nil {
 		return "", err
 	}
+	switch types.ID(*filler) {
+	case "", solarlottery.Type, queue.Type:
+		// passthrough
+	default:
+		return "", errors.Errorf(
+			"%s is not a valid filler type, please use %s or %s",
+			*filler, solarlottery.Type, queue.Type)
+	}
+	if *workingHours != "" && !sl.WorkingHoursPolicies.Contains(types.ID(*workingHours)) {
+		return c.flagUsage(), errors.Errorf("invalid working hours policy %s", *workingHours)
+	}
+	if *volunteers != "" && !sl.VolunteerPolicies.Contains(types.ID(*volunteers)) {
+		return c.flagUsage(), errors.Errorf("invalid volunteer policy %s", *volunteers)
+	}
 
 	return c.normalOut(
 		c.SL.UpdateRotation(rotationID, func(r *sl.Rotation) error {
+			if *filler != "" {
+				r.FillerType = types.ID(*filler)
+			}
 			if *seed != intNoValue {
 				r.FillSettings.Seed = *seed
 			}
 			if *fuzz != intNoValue {
 				r.FillSettings.Fuzz = *fuzz
 			}
+			if *workingHours != "" {
+				r.FillSettings.WorkingHours = types.ID(*workingHours)
+			}
+			if *volunteers != "" {
+				r.FillSettings.Volunteers = types.ID(*volunteers)
+			}
+			if period.Period != "" {
+				r.FillSettings.Period = period
+			}
 			return nil
 		}))
 }
@@ -117,14 +161,192 @@ func (c *Command) rotationSetNeed(require bool, parameters []string) (md.MD, err
 		}))
 }
 
+func (c *Command) rotationSetPair(parameters []string) (md.MD, error) {
+	c.withFlagRotation()
+	neverTogether := c.flags().Bool("never-together", false, "the users may not serve on the same task")
+	var mentee, mentor sl.SkillLevel
+	c.flags().Var(&mentee, "mentee", "skill, with the maximum level (1-4) of the users that must be accompanied, as in `--mentee=web-1`.")
+	c.flags().Var(&mentor, "mentor", "skill, with the minimum level (1-4) of the users that accompany the mentees, as in `--mentor=web-4`.")
+	clear := c.flags().Bool("clear", false, "remove the matching rules, or all rules if none specified")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	rotationID, mattermostUserIDs, err := c.resolveRotationUsernames()
+	if err != nil {
+		return "", err
+	}
+
+	var rule *sl.PairRule
+	switch {
+	case *neverTogether:
+		if mattermostUserIDs.Len() < 2 {
+			return "", errors.New("--never-together requires at least 2 users")
+		}
+		rule = sl.NewNeverTogether(mattermostUserIDs.IDs()...)
+	case mentee.Skill != "":
+		if mentor.Skill == "" && !*clear {
+			return "", errors.New("--mentee requires --mentor")
+		}
+		rule = sl.NewAccompaniedBy(mentee, mentor)
+	case !*clear:
+		return "", errors.New("specify --never-together, or --mentee and --mentor")
+	}
+
+	return c.normalOut(
+		c.SL.UpdateRotation(rotationID, func(r *sl.Rotation) error {
+			if !*clear {
+				r.TaskSettings.Pairs = append(r.TaskSettings.Pairs, rule)
+				return nil
+			}
+			pairs := sl.PairRules{}
+			for _, p := range r.TaskSettings.Pairs {
+				if rule != nil && !p.Matches(rule) {
+					pairs = append(pairs, p)
+				}
+			}
+			r.TaskSettings.Pairs = pairs
+			return nil
+		}))
+}
+
+func (c *Command) rotationSetTrainee(parameters []string) (md.MD, error) {
+	c.withFlagRotation()
+	shadows := c.flags().Int64("shadows", intNoValue, "number of trainees to shadow each task")
+	graduateAfter := c.flags().Int64("graduate-after", intNoValue, "prompt the lead to qualify trainees after this many shadowed tasks")
+	lead := c.flags().String("lead", "", "user to prompt when trainees graduate")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	rotationID, err := c.resolveRotation()
+	if err != nil {
+		return "", err
+	}
+	var leadID types.ID
+	if *lead != "" {
+		ids, err := c.resolveUsernames([]string{*lead})
+		if err != nil {
+			return "", err
+		}
+		leadID = ids.IDs()[0]
+	}
+
+	return c.normalOut(
+		c.SL.UpdateRotation(rotationID, func(r *sl.Rotation) error {
+			if *shadows != intNoValue {
+				r.TraineeSettings.Shadows = int(*shadows)
+			}
+			if *graduateAfter != intNoValue {
+				r.TraineeSettings.GraduateAfter = int(*graduateAfter)
+			}
+			if leadID != "" {
+				r.TraineeSettings.LeadMattermostUserID = leadID
+			}
+			return nil
+		}))
+}
+
+func (c *Command) rotationSetWebhook(parameters []string) (md.MD, error) {
+	c.withFlagRotation()
+	url := c.flags().String("url", "", "URL to POST the events to")
+	secret := c.flags().String("secret", "", "secret to sign the payloads with (default: random, for a new webhook)")
+	events := c.flags().StringSlice("events", nil, "events to send, e.g. `--events=task.started,task.finished` (default: all)")
+	remove := c.flags().Bool("remove", false, "remove the webhook")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	rotationID, err := c.resolveRotation()
+	if err != nil {
+		return "", err
+	}
+	if *url == "" {
+		if *secret != "" || len(*events) > 0 || *remove {
+			return c.flagUsage(), errors.New("--url is required")
+		}
+		return c.normalOut(c.SL.ListWebhooks(rotationID))
+	}
+
+	var eventIDs *types.IDSet
+	if len(*events) > 0 {
+		eventIDs = types.NewIDSet()
+		for _, event := range *events {
+			eventIDs.Set(types.ID(event))
+		}
+	}
+	return c.normalOut(
+		c.SL.SetWebhook(sl.InSetWebhook{
+			RotationID: rotationID,
+			URL:        *url,
+			Secret:     *secret,
+			Events:     eventIDs,
+			Remove:     *remove,
+		}))
+}
+
+func (c *Command) rotationSetWorkload(parameters []string) (md.MD, error) {
+	c.withFlagRotation()
+	maxTasks := c.flags().Int64("max-tasks", intNoValue, "maximum number of tasks per window, across all rotations")
+	maxHours := c.flags().Float64("max-hours", float64(intNoValue), "maximum number of hours per window, across all rotations")
+	window := c.flags().Duration("window", 0, "the rolling window for the cap, e.g. 168h")
+	clear := c.flags().Bool("clear", false, "clear the rotation's cap, and use the plugin-wide one")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	rotationID, err := c.resolveRotation()
+	if err != nil {
+		return "", err
+	}
+
+	return c.normalOut(
+		c.SL.UpdateRotation(rotationID, func(r *sl.Rotation) error {
+			if *clear {
+				r.TaskSettings.Workload = sl.Workload{}
+				return nil
+			}
+			if *maxTasks != intNoValue {
+				r.TaskSettings.Workload.MaxTasks = int(*maxTasks)
+			}
+			if *maxHours != float64(intNoValue) {
+				r.TaskSettings.Workload.MaxHours = *maxHours
+			}
+			if *window != 0 {
+				r.TaskSettings.Workload.Window = *window
+			}
+			w := r.TaskSettings.Workload
+			if (w.MaxTasks > 0 || w.MaxHours > 0) && w.Window <= 0 {
+				return errors.New("--window is required to set the workload cap")
+			}
+			return nil
+		}))
+}
+
 func (c *Command) rotationSetTask(parameters []string) (md.MD, error) {
 	c.withFlagRotation()
 	dur := c.flags().Duration("duration", 0, "duration")
 	grace := c.flags().Duration("grace", 0, "grace period after finishing a task")
+	conflicts := c.flags().String("conflicts", "", fmt.Sprintf("policy for overlaps with tasks in other rotations: %s, %s, or %s", sl.ConflictIgnore, sl.ConflictWarn, sl.ConflictBlock))
+	holidays := c.flags().String("holidays", "", "holiday calendar for the shifts")
+	holidayPolicy := c.flags().String("holiday-policy", "", fmt.Sprintf("policy for the shifts on holidays: %s, or %s", sl.HolidaySkip, sl.HolidayMark))
+	noHolidays := c.flags().Bool("no-holidays", false, "clear the holiday calendar")
 	err := c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
 	}
+	if *conflicts != "" && !sl.ConflictPolicies.Contains(types.ID(*conflicts)) {
+		return c.flagUsage(), errors.Errorf("invalid conflict policy %s", *conflicts)
+	}
+	if *holidayPolicy != "" && !sl.HolidayPolicies.Contains(types.ID(*holidayPolicy)) {
+		return c.flagUsage(), errors.Errorf("invalid holiday policy %s", *holidayPolicy)
+	}
+	if *holidays != "" {
+		_, err = c.SL.LoadHolidayCalendar(types.ID(*holidays))
+		if err != nil {
+			return "", err
+		}
+	}
 
 	rotationID, err := c.resolveRotation()
 	if err != nil {
@@ -139,6 +361,18 @@ func (c *Command) rotationSetTask(parameters []string) (md.MD, error) {
 			if *grace != 0 {
 				r.TaskSettings.Grace = *grace
 			}
+			if *conflicts != "" {
+				r.TaskSettings.Conflicts = types.ID(*conflicts)
+			}
+			if *holidays != "" {
+				r.TaskSettings.Holidays = types.ID(*holidays)
+			}
+			if *noHolidays {
+				r.TaskSettings.Holidays = ""
+			}
+			if *holidayPolicy != "" {
+				r.TaskSettings.HolidayPolicy = types.ID(*holidayPolicy)
+			}
 			return nil
 		}))
 }
@@ -54,6 +54,58 @@ func TestRotationSetFill(t *testing.T) {
 	})
 }
 
+func TestRotationSetPair(t *testing.T) {
+	ctrl, SL := defaultEnv(t)
+	defer ctrl.Finish()
+
+	mustRunMulti(t, SL, `
+		/lotto rotation new test-rotation
+		/lotto rotation set pair test-rotation @test-user1 @test-user2 --never-together
+		/lotto rotation set pair test-rotation --mentee webapp-1 --mentor webapp-3
+		`)
+
+	r := mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+	require.Equal(t, sl.PairRules{
+		sl.NewNeverTogether("test-user1", "test-user2"),
+		sl.NewAccompaniedBy(sl.NewSkillLevel("webapp", sl.BeginnerLevel), sl.NewSkillLevel("webapp", sl.AdvancedLevel)),
+	}, r.TaskSettings.Pairs)
+
+	r = mustRunRotation(t, SL, `/lotto rotation set pair test-rotation @test-user2 @test-user1 --never-together --clear`)
+	require.Equal(t, sl.PairRules{
+		sl.NewAccompaniedBy(sl.NewSkillLevel("webapp", sl.BeginnerLevel), sl.NewSkillLevel("webapp", sl.AdvancedLevel)),
+	}, r.TaskSettings.Pairs)
+
+	r = mustRunRotation(t, SL, `/lotto rotation set pair test-rotation --clear`)
+	require.Empty(t, r.TaskSettings.Pairs)
+
+	_, err := run(t, SL, `/lotto rotation set pair test-rotation @test-user1 --never-together`)
+	require.Error(t, err)
+}
+
+func TestRotationSetWorkload(t *testing.T) {
+	ctrl, SL := defaultEnv(t)
+	defer ctrl.Finish()
+
+	mustRun(t, SL, `/lotto rotation new test-rotation`)
+	r := mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+	require.True(t, r.Workload().IsEmpty())
+
+	r = mustRunRotation(t, SL, `/lotto rotation set workload test-rotation --max-tasks 2 --window 168h`)
+	require.Equal(t, sl.Workload{MaxTasks: 2, Window: 168 * time.Hour}, r.TaskSettings.Workload)
+
+	r = mustRunRotation(t, SL, `/lotto rotation set workload test-rotation --max-hours 40.5`)
+	require.Equal(t, sl.Workload{MaxTasks: 2, MaxHours: 40.5, Window: 168 * time.Hour}, r.TaskSettings.Workload)
+	require.Equal(t, "2 tasks, 40.5 hours per 7 days", r.Workload().String())
+
+	r = mustRunRotation(t, SL, `/lotto rotation set workload test-rotation --clear`)
+	require.True(t, r.Workload().IsEmpty())
+
+	_, err := run(t, SL, `/lotto rotation set workload test-rotation --max-tasks 2`)
+	require.EqualError(t, err, "--window is required to set the workload cap")
+	r = mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+	require.Equal(t, sl.Workload{}, r.TaskSettings.Workload)
+}
+
 func TestTaskSet(t *testing.T) {
 	t.Run("limit", func(t *testing.T) {
 		ctrl, SL := defaultEnv(t)
@@ -0,0 +1,134 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/webhook"
+)
+
+// serveWebhook receives the webhooks, and verifies their signatures.
+func serveWebhook(t *testing.T, secret string) (*httptest.Server, chan *sl.WebhookPayload) {
+	ch := make(chan *sl.WebhookPayload, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil || !webhook.Verify(secret, data, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		payload := &sl.WebhookPayload{}
+		err = json.Unmarshal(data, payload)
+		if err != nil || string(payload.Event) != r.Header.Get(webhook.HeaderEvent) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ch <- payload
+	}))
+	return server, ch
+}
+
+func receiveWebhook(t *testing.T, ch chan *sl.WebhookPayload) *sl.WebhookPayload {
+	select {
+	case payload := <-ch:
+		return payload
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "webhook not received")
+	}
+	return nil
+}
+
+func usernames(payload *sl.WebhookPayload) []string {
+	out := []string{}
+	for _, user := range payload.Users {
+		out = append(out, user.Username)
+	}
+	return out
+}
+
+func TestRotationSetWebhook(t *testing.T) {
+	t.Run("add, list, and remove", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRun(t, SL, `/lotto rotation new test-rotation`)
+		out := mustRun(t, SL, `/lotto rotation set webhook test-rotation --url https://example.com/hook`)
+		require.Contains(t, out.String(), "added webhook https://example.com/hook: all events to test-rotation.")
+		require.Contains(t, out.String(), "Verify the payloads' signatures with the secret")
+
+		out = mustRun(t, SL, `/lotto rotation set webhook test-rotation --url https://example.com/hook --events task.started,task.finished`)
+		require.Equal(t, "updated webhook https://example.com/hook: task.started, task.finished in test-rotation.", out.String())
+
+		hooks := sl.OutWebhooks{}
+		mustRunJSON(t, SL, `/lotto rotation set webhook test-rotation`, &hooks)
+		require.Len(t, hooks.Webhooks, 1)
+		require.Equal(t, "", hooks.Webhooks[0].Secret)
+		out = mustRun(t, SL, `/lotto rotation set webhook test-rotation`)
+		require.Equal(t, "test-rotation webhooks:\n- https://example.com/hook: task.started, task.finished\n", out.String())
+
+		_, err := run(t, SL, `/lotto rotation set webhook test-rotation --url https://example.com/hook --events task.exploded`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown webhook event task.exploded")
+		_, err = run(t, SL, `/lotto rotation set webhook test-rotation --url example.com/hook`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid webhook URL")
+
+		out = mustRun(t, SL, `/lotto rotation set webhook test-rotation --url https://example.com/hook --remove`)
+		require.Equal(t, "removed webhook https://example.com/hook from test-rotation.", out.String())
+		out = mustRun(t, SL, `/lotto rotation set webhook test-rotation`)
+		require.Equal(t, "test-rotation has no webhooks.", out.String())
+	})
+
+	t.Run("events", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		server, ch := serveWebhook(t, "test-secret")
+		defer server.Close()
+
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning 2020-03-01T09:00 --period weekly
+			/lotto rotation set task test-rotation --duration 8h
+			/lotto rotation set webhook test-rotation --url `+server.URL+` --secret test-secret
+			/lotto user join test-rotation @test-user1 --now 2020-02-01T10:00
+		`)
+		payload := receiveWebhook(t, ch)
+		require.Equal(t, sl.WebhookEventJoined, payload.Event)
+		require.Equal(t, "2020-02-01T18:00", payload.Time.String())
+		require.Equal(t, types.ID("test-rotation"), payload.RotationID)
+		require.Equal(t, []string{"test-user1"}, usernames(payload))
+
+		// The simulations do not send webhooks.
+		mustRunMulti(t, SL, `
+			/lotto rotation forecast test-rotation --shifts 2
+			/lotto task new shift test-rotation --number 1
+			/lotto task fill test-rotation#1 --dry-run
+			/lotto task fill test-rotation#1
+			/lotto task schedule test-rotation#1
+			/lotto task start test-rotation#1
+			/lotto task finish test-rotation#1
+		`)
+		for _, expected := range []types.ID{"task.created", "task.filled", "task.scheduled", "task.started", "task.finished"} {
+			payload = receiveWebhook(t, ch)
+			require.Equal(t, expected, payload.Event)
+			require.Equal(t, types.ID("test-rotation#1"), payload.Task.TaskID)
+			if expected != sl.WebhookEventTaskCreated {
+				require.Equal(t, []string{"test-user1"}, usernames(payload))
+			}
+		}
+		require.Equal(t, sl.TaskStateFinished, payload.Task.State)
+
+		mustRun(t, SL, `/lotto user leave test-rotation @test-user1 --now 2020-03-20T10:00`)
+		payload = receiveWebhook(t, ch)
+		require.Equal(t, sl.WebhookEventLeft, payload.Event)
+		require.Equal(t, "2020-03-20T17:00", payload.Time.String())
+	})
+}
@@ -4,13 +4,17 @@
 package command
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
 func (c *Command) skillNew(parameters []string) (md.MD, error) {
+	parent, levelOffset := c.withSkillParentFlags()
 	err := c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
@@ -24,13 +28,59 @@ func (c *Command) skillNew(parameters []string) (md.MD, error) {
 	if err != nil {
 		return "", err
 	}
+	if *parent != "" {
+		err = c.SL.SetSkillParent(skill, types.ID(*parent), *levelOffset)
+		if err != nil {
+			return "", err
+		}
+	}
 
 	if c.outputJSON {
 		return md.JSONBlock(skill), nil
 	}
+	if *parent != "" {
+		return md.Markdownf("Added **%s** to known skills, implying **%s** %v level(s) lower.", skill, *parent, *levelOffset), nil
+	}
 	return md.Markdownf("Added **%s** to known skills.", skill), nil
 }
 
+func (c *Command) skillSet(parameters []string) (md.MD, error) {
+	parent, levelOffset := c.withSkillParentFlags()
+	clear := c.flags().Bool("clear", false, "remove the skill's parent")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if len(c.flags().Args()) != 1 {
+		return c.flagUsage(), errors.New("must specify skill")
+	}
+	skill := types.ID(c.flags().Arg(0))
+	if *parent == "" && !*clear {
+		return c.flagUsage(), errors.New("must specify --parent, or --clear")
+	}
+	if *clear {
+		*parent = ""
+	}
+
+	err = c.SL.SetSkillParent(skill, types.ID(*parent), *levelOffset)
+	if err != nil {
+		return "", err
+	}
+	if c.outputJSON {
+		return md.JSONBlock(skill), nil
+	}
+	if *parent == "" {
+		return md.Markdownf("**%s** no longer implies other skills.", skill), nil
+	}
+	return md.Markdownf("**%s** now implies **%s** %v level(s) lower.", skill, *parent, *levelOffset), nil
+}
+
+func (c *Command) withSkillParentFlags() (parent *string, levelOffset *int64) {
+	parent = c.flags().String("parent", "", "the parent skill, implied by this one")
+	levelOffset = c.flags().Int64("level-offset", 0, "the parent skill is implied this many levels lower, 0-3")
+	return parent, levelOffset
+}
+
 func (c *Command) skillDelete(parameters []string) (md.MD, error) {
 	err := c.parse(parameters)
 	if err != nil {
@@ -63,5 +113,33 @@ func (c *Command) skillList(parameters []string) (md.MD, error) {
 	if c.outputJSON {
 		return md.JSONBlock(skills), nil
 	}
-	return "Known skills: " + md.JSONBlock(skills), nil
+	tree, err := c.SL.LoadSkillTree()
+	if err != nil {
+		return "", err
+	}
+	return "Known skills:\n" + tree.MarkdownTree(skills), nil
+}
+
+func (c *Command) skillAudit(parameters []string) (md.MD, error) {
+	c.withFlagRotation()
+	within := c.flags().Duration("within", 30*24*time.Hour, "list the qualifications that expire within this period")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	rotationID := types.ID("")
+	ref, _ := c.flags().GetString("rotation")
+	if ref != "" || len(c.flags().Args()) > 0 {
+		rotationID, err = c.resolveRotation()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return c.normalOut(
+		c.SL.AuditSkills(sl.InAuditSkills{
+			RotationID: rotationID,
+			Time:       *c.now,
+			Within:     *within,
+		}))
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,3 +32,140 @@ func TestSkillList(t *testing.T) {
 		require.Equal(t, []string{"test", "test-123", "test-345"}, out)
 	})
 }
+
+func TestSkillImplied(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	SL, _ := getTestSL(t, ctrl)
+	mustRunMulti(t, SL, `
+		/lotto skill new ops
+		/lotto skill new sre --parent ops --level-offset 1
+		/lotto skill new kubernetes
+		/lotto skill set kubernetes --parent sre
+		/lotto rotation new test-rotation --task-type=ticket --beginning=2020-03-01
+		/lotto rotation set require -s ops-2 --count 1 test-rotation
+		/lotto rotation set limit -s ops-3 --count 0 test-rotation
+		/lotto user join test-rotation @test-user1 @test-user2 @test-user3 --starting 2020-01-01
+		/lotto user qualify -s ops-1 @test-user1
+		/lotto user qualify -s kubernetes-4 @test-user2
+		/lotto user qualify -s sre-2 @test-user3
+		/lotto task new ticket test-rotation --summary test-summary1
+		`)
+
+	out := mustRun(t, SL, `/lotto skill list`)
+	require.Equal(t, "Known skills:\n"+
+		"- ops\n"+
+		"  - sre (implies ops, 1 level(s) lower)\n"+
+		"    - kubernetes\n",
+		out.String())
+
+	// test-user1 is not qualified, test-user2 is implied ops-3 by
+	// kubernetes-4 and would exceed the limit, test-user3 is implied ops-1.
+	_, err := run(t, SL, `/lotto task fill test-rotation#1 --now 2020-02-20`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unfilled needs 1 any, 1 ops-▣: insufficient")
+
+	mustRun(t, SL, `/lotto user qualify -s sre-3 @test-user3`)
+	task := mustRunTaskAssign(t, SL, `/lotto task fill test-rotation#1 --now 2020-02-20`)
+	require.Equal(t, []string{"test-user3"}, task.MattermostUserIDs.TestIDs())
+
+	_, err = run(t, SL, `/lotto skill set ops --parent kubernetes`)
+	require.EqualError(t, err, "kubernetes already implies ops")
+}
+
+func TestSkillAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	poster := &bot.TestPoster{}
+	SL, _ := getTestSLWithPoster(t, ctrl, poster)
+	mustRunMulti(t, SL, `
+		/lotto rotation new test-rotation --beginning 2020-03-01 --period weekly
+		/lotto rotation new other-rotation --beginning 2020-03-01 --period weekly
+		/lotto rotation set trainee test-rotation --lead @test-lead
+		/lotto user join test-rotation @test-user1 @test-user2 @test-user3
+		/lotto user join other-rotation @test-user4
+		/lotto user qualify -s web-2 @test-user1 --expires 2020-03-10 --now 2020-01-01
+		/lotto user qualify -s server-1 @test-user2 --expires 2020-04-15 --now 2020-01-01
+		/lotto user qualify -s server-1 @test-user3 @test-user4
+		`)
+
+	out := mustRun(t, SL, `/lotto skill audit --rotation test-rotation --within 720h --now 2020-03-01`)
+	require.Equal(t, "Qualifications expiring before 2020-03-31T01:00:\n"+
+		"- test-rotation:\n"+
+		"  - @test-user1 web-▣ expires 2020-03-10, granted by @test-user on 2020-01-01\n",
+		out.String())
+
+	out = mustRun(t, SL, `/lotto skill audit --within 1200h --now 2020-03-11`)
+	require.Contains(t, out.String(), "  - @test-user1 web-▣ expired 2020-03-10")
+	require.Contains(t, out.String(), "  - @test-user2 server-◉ expires 2020-04-15")
+	require.Contains(t, out.String(), "- other-rotation: none\n")
+
+	// The reminders are off by default.
+	poster.Reset()
+	mustRun(t, SL, `/lotto rotation autopilot test-rotation --now 2020-03-01`)
+	require.Empty(t, poster.DirectPosts)
+
+	mustRunMulti(t, SL, `
+		/lotto rotation set autopilot test-rotation --remind-expiry
+		/lotto rotation set autopilot other-rotation --remind-expiry
+		/lotto rotation set trainee other-rotation --lead @test-lead2
+		/lotto user join other-rotation @test-user1
+		`)
+	poster.Reset()
+	mustRun(t, SL, `/lotto rotation autopilot test-rotation --now 2020-03-01`)
+	require.Equal(t, []bot.TestPost{
+		{
+			UserID: "test-user1",
+			Message: "###### Your qualifications are expiring\n" +
+				"- @test-user1 web-▣ expires 2020-03-10, granted by @test-user on 2020-01-01\n" +
+				"Please ask to be recertified, with `/lotto user qualify @test-user1 --skills <skill-level> --expires <time>`.",
+		},
+		{
+			UserID: "test-lead",
+			Message: "###### Qualifications expiring in test-rotation\n" +
+				"- @test-user1 web-▣ expires 2020-03-10, granted by @test-user on 2020-01-01\n" +
+				"Use `/lotto skill audit --rotation test-rotation` for the full list.",
+		},
+	}, poster.DirectPosts)
+
+	// Each qualification is reminded of once to the user, and once to the
+	// lead of each rotation.
+	poster.Reset()
+	mustRun(t, SL, `/lotto rotation autopilot test-rotation --now 2020-03-02`)
+	require.Empty(t, poster.DirectPosts)
+	mustRun(t, SL, `/lotto rotation autopilot other-rotation --now 2020-03-02`)
+	require.Equal(t, []bot.TestPost{
+		{
+			UserID: "test-lead2",
+			Message: "###### Qualifications expiring in other-rotation\n" +
+				"- @test-user1 web-▣ expires 2020-03-10, granted by @test-user on 2020-01-01\n" +
+				"Use `/lotto skill audit --rotation other-rotation` for the full list.",
+		},
+	}, poster.DirectPosts)
+	poster.Reset()
+	mustRun(t, SL, `/lotto rotation autopilot other-rotation --now 2020-03-03`)
+	require.Empty(t, poster.DirectPosts)
+}
+
+func TestRemindExpiryKeepsSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	poster := &bot.TestPoster{}
+	SL, _ := getTestSLWithPoster(t, ctrl, poster)
+	mustRunMulti(t, SL, `
+		/lotto rotation new test-rotation --beginning 2020-01-05 --period weekly
+		/lotto rotation set autopilot test-rotation --create --create-prior 200h --schedule --schedule-prior 100h --remind-expiry
+		/lotto user join test-rotation @test-user1 --starting 2019-12-01
+		/lotto user qualify -s web-2 @test-user1 --expires 2020-01-16 --now 2019-12-01
+		/lotto rotation autopilot test-rotation --now 2020-01-01T12:00
+		`)
+	poster.Reset()
+
+	// The user is scheduled, and reminded, in the same run.
+	mustRun(t, SL, `/lotto rotation autopilot test-rotation --now 2020-01-02T12:00`)
+	require.Equal(t, sl.TaskStateScheduled, mustRunTask(t, SL, `/lotto task show test-rotation#0`).State)
+	user := mustRunUser(t, SL, `/lotto user show @test-user1`)
+	require.Len(t, user.Calendar, 1)
+	require.Equal(t, types.ID("test-rotation#0"), user.Calendar[0].TaskID)
+	require.True(t, user.SkillGrants["web"].Reminded)
+}
@@ -10,6 +10,7 @@ import (
 
 func (c *Command) taskAssign(parameters []string) (md.MD, error) {
 	force := c.flags().BoolP("force", "f", false, "ignore constraints")
+	shadow := c.flags().Bool("shadow", false, "assign as shadows (trainees)")
 	err := c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
@@ -23,5 +24,6 @@ func (c *Command) taskAssign(parameters []string) (md.MD, error) {
 		TaskID:            taskID,
 		MattermostUserIDs: mattermostUserIDs,
 		Force:             *force,
+		Shadow:            *shadow,
 	}))
 }
@@ -60,6 +60,114 @@ func TestTaskAssign(t *testing.T) {
 		require.Equal(t, "failed to assign task test-rotation#1: user @test-user2 failed max constraints server-◉", err.Error())
 	})
 
+	t.Run("never together", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=ticket
+			/lotto rotation set pair test-rotation @test-user1 @test-user2 --never-together
+			/lotto task new ticket test-rotation --summary test-summary1
+			/lotto task assign test-rotation#1 @test-user1 @test-user3
+			`)
+
+		_, err := run(t, SL, `/lotto task assign test-rotation#1 @test-user2`)
+		require.Error(t, err)
+		require.Equal(t, "failed to assign task test-rotation#1: user @test-user2 violates pair rules never together: @test-user1, @test-user2", err.Error())
+	})
+
+	t.Run("accompanied by", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=ticket
+			/lotto rotation set pair test-rotation --mentee server-1 --mentor server-4
+			/lotto user qualify @test-user1 -s server-1
+			/lotto user qualify @test-user2 -s server-4
+			/lotto task new ticket test-rotation --summary test-summary1
+			/lotto task new ticket test-rotation --summary test-summary2
+			`)
+
+		_, err := run(t, SL, `/lotto task assign test-rotation#1 @test-user1`)
+		require.Error(t, err)
+		require.Equal(t, "failed to assign task test-rotation#1: user @test-user1 violates pair rules server-◉ accompanied by server-◈◈", err.Error())
+
+		task := mustRunTaskAssign(t, SL, `/lotto task assign test-rotation#1 @test-user1 @test-user2`)
+		require.Equal(t, []string{"test-user1", "test-user2"}, task.MattermostUserIDs.TestIDs())
+
+		task = mustRunTaskAssign(t, SL, `/lotto task assign test-rotation#2 @test-user1 @test-user2 --force`)
+		require.Equal(t, []string{"test-user1", "test-user2"}, task.MattermostUserIDs.TestIDs())
+	})
+
+	t.Run("workload cap across rotations", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation1 --beginning 2020-03-01 --period weekly
+			/lotto rotation new test-rotation2 --beginning 2020-03-04 --period weekly
+			/lotto rotation set task test-rotation1 --duration 48h
+			/lotto rotation set task test-rotation2 --duration 48h
+			/lotto rotation set workload test-rotation2 --max-tasks 1 --window 168h
+			/lotto task new shift test-rotation1 --number 1
+			/lotto task new shift test-rotation2 --number 1
+			/lotto task new shift test-rotation2 --number 3
+			/lotto task assign test-rotation1#1 @test-user1
+			/lotto task schedule test-rotation1#1
+			`)
+
+		_, err := run(t, SL, `/lotto task assign test-rotation2#1 @test-user1`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to assign task test-rotation2#1: user @test-user1 would exceed the workload cap: 2 tasks")
+
+		task := mustRunTaskAssign(t, SL, `/lotto task assign test-rotation2#3 @test-user1`)
+		require.Equal(t, []string{"test-user1"}, task.MattermostUserIDs.TestIDs())
+
+		task = mustRunTaskAssign(t, SL, `/lotto task assign test-rotation2#1 @test-user1 --force`)
+		require.Equal(t, []string{"test-user1"}, task.MattermostUserIDs.TestIDs())
+	})
+
+	t.Run("conflicts with other rotations", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation1 --beginning 2020-03-01 --period weekly
+			/lotto rotation new test-rotation2 --beginning 2020-03-02 --period weekly
+			/lotto rotation new test-rotation3 --beginning 2020-03-01 --period weekly
+			/lotto rotation set task test-rotation1 --duration 48h
+			/lotto rotation set task test-rotation2 --duration 24h
+			/lotto rotation set task test-rotation3 --duration 48h
+			/lotto task new shift test-rotation1 --number 1
+			/lotto task new shift test-rotation2 --number 1
+			/lotto task new shift test-rotation3 --number 1
+			/lotto task assign test-rotation1#1 @test-user1
+			/lotto task assign test-rotation3#1 @test-user1
+			/lotto task schedule test-rotation1#1
+			/lotto task schedule test-rotation3#1
+			`)
+
+		_, err := run(t, SL, `/lotto task assign test-rotation2#1 @test-user1`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to assign task test-rotation2#1: user @test-user1 has conflicting tasks: test-rotation1#1 (")
+		require.Contains(t, err.Error(), "), test-rotation3#1 (")
+
+		mustRun(t, SL, `/lotto rotation set task test-rotation2 --conflicts warn`)
+		out := mustRun(t, SL, `/lotto task assign test-rotation2#1 @test-user1`)
+		require.Contains(t, out.String(), "- warning: @test-user1 has conflicting tasks: test-rotation1#1 (")
+
+		mustRunMulti(t, SL, `
+			/lotto task unassign test-rotation2#1 @test-user1
+			/lotto rotation set task test-rotation2 --conflicts ignore
+			`)
+		out = mustRun(t, SL, `/lotto task assign test-rotation2#1 @test-user1`)
+		require.NotContains(t, out.String(), "warning")
+
+		_, err = run(t, SL, `/lotto rotation set task test-rotation2 --conflicts sometimes`)
+		require.Error(t, err)
+	})
+
 	t.Run("max constraint--force", func(t *testing.T) {
 		ctrl, SL := defaultEnv(t)
 		defer ctrl.Finish()
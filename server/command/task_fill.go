@@ -9,6 +9,8 @@ import (
 )
 
 func (c *Command) taskFill(parameters []string) (md.MD, error) {
+	dryRun := c.flags().Bool("dry-run", false, "fill the task, but do not save the results")
+	explain := c.flags().Bool("explain", false, "explain how the users were picked")
 	err := c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
@@ -18,8 +20,10 @@ func (c *Command) taskFill(parameters []string) (md.MD, error) {
 		return "", err
 	}
 
-	return c.normalOut(c.SL.FillTask(sl.InAssignTask{
-		TaskID: taskID,
-		Time:   *c.now,
+	return c.normalOut(c.SL.FillTask(sl.InFillTask{
+		TaskID:  taskID,
+		Time:    *c.now,
+		DryRun:  *dryRun,
+		Explain: *explain,
 	}))
 }
@@ -3,6 +3,7 @@
 package command
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -69,4 +70,133 @@ func TestTaskFill(t *testing.T) {
 			mustRunUser(t, SL, `/lotto user show @test-user3`).Calendar,
 		)
 	})
+	t.Run("fill queue", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=ticket --beginning=2020-03-01
+			/lotto rotation set fill test-rotation --filler queue
+			/lotto user join test-rotation @test-user1 @test-user2 @test-user3 --starting 2020-01-01
+			/lotto task new ticket test-rotation --now 2020-03-01
+			/lotto task new ticket test-rotation --now 2020-03-02
+			/lotto task new ticket test-rotation --now 2020-03-03
+			/lotto task new ticket test-rotation --now 2020-03-04
+			`)
+
+		r := mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+		require.Equal(t, types.ID("queue"), r.FillerType)
+
+		for i, expected := range []string{"test-user1", "test-user2", "test-user3", "test-user1"} {
+			task := mustRunTaskAssign(t, SL, fmt.Sprintf(`/lotto task fill test-rotation#%v`, i+1))
+			require.Equal(t, []string{expected}, task.MattermostUserIDs.TestIDs())
+		}
+
+		r = mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+		require.Equal(t, []types.ID{"test-user2", "test-user3", "test-user1"}, r.FillSettings.Queue.IDs())
+	})
+
+	t.Run("other rotation's grace period", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation1 --beginning 2020-03-01 --period weekly
+			/lotto rotation new test-rotation2 --beginning 2020-03-03 --period weekly
+			/lotto rotation set task test-rotation1 --duration 24h --grace 48h
+			/lotto rotation set task test-rotation2 --duration 24h
+			/lotto user join test-rotation2 @test-user1 @test-user2 --starting 2020-01-01
+			/lotto task new shift test-rotation1 --number 1
+			/lotto task new shift test-rotation2 --number 1
+			/lotto task assign test-rotation1#1 @test-user1
+			/lotto task schedule test-rotation1#1
+			`)
+
+		out := &sl.OutFillTask{Changed: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task fill test-rotation2#1 --dry-run --explain`, out)
+		require.Equal(t, []string{"test-user2"}, out.Task.MattermostUserIDs.TestIDs())
+		require.Len(t, out.Explanation.Disqualified, 1)
+		require.Equal(t, types.ID("test-user1"), out.Explanation.Disqualified[0].MattermostUserID)
+		require.Equal(t, sl.DisqualifiedUnavailable, out.Explanation.Disqualified[0].Reason)
+		require.Contains(t, out.Explanation.Disqualified[0].Details, "grace: ")
+
+		mustRun(t, SL, `/lotto rotation set task test-rotation2 --conflicts warn`)
+		out = &sl.OutFillTask{Changed: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task fill test-rotation2#1 --dry-run --explain`, out)
+		require.Empty(t, out.Explanation.Disqualified)
+	})
+
+	t.Run("dry run explain", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=ticket --beginning=2020-03-01
+			/lotto rotation set require -s web-1 --count 1 test-rotation
+			/lotto rotation set limit -s server-1 --count 1 test-rotation
+			/lotto user join test-rotation @test-user1 @test-user2 @test-user3 @test-user4 @test-user5 --starting 2020-01-01
+			/lotto user qualify -s web-1 @test-user1 @test-user3 @test-user4
+			/lotto user qualify -s server-1 @test-user4 @test-user5
+			/lotto user unavailable @test-user1 --start 2020-03-01 --finish 2020-03-03
+			/lotto task new ticket test-rotation --now 2020-03-02
+			/lotto task assign test-rotation#1 @test-user5
+			`)
+
+		out := &sl.OutFillTask{Changed: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task fill test-rotation#1 --dry-run --explain --now 2020-02-20`, out)
+		require.Equal(t, []string{"test-user3", "test-user5"}, out.Task.MattermostUserIDs.TestIDs())
+		require.Empty(t, out.Error)
+
+		e := out.Explanation
+		require.NotNil(t, e)
+		require.Equal(t, types.ID("solar-lottery"), e.Filler)
+		require.Equal(t, []types.ID{"test-user5"}, e.Preassigned.IDs())
+		require.Len(t, e.Pool, 2)
+		require.InDelta(t, 1.0, e.Pool[0].Probability+e.Pool[1].Probability, 1e-9)
+		require.Equal(t, []*sl.FillDisqualified{
+			{
+				MattermostUserID: "test-user1",
+				Reason:           sl.DisqualifiedUnavailable,
+				Details:          "personal: 2020-03-01 to 2020-03-03",
+			},
+			{
+				MattermostUserID: "test-user2",
+				Reason:           sl.DisqualifiedMissingSkill,
+				Details:          "does not qualify for 1 web-◉",
+			},
+		}, e.Disqualified[:2])
+		for _, d := range e.Disqualified[2:] {
+			require.Equal(t, types.ID("test-user4"), d.MattermostUserID)
+			require.Equal(t, sl.DisqualifiedLimit, d.Reason)
+		}
+		require.Equal(t, []*sl.FillPick{{Need: "web-◉", MattermostUserID: "test-user3"}}, e.Picks)
+
+		// Nothing is saved.
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		require.Equal(t, []string{"test-user5"}, task.MattermostUserIDs.TestIDs())
+		user := mustRunUser(t, SL, `/lotto user show @test-user3`)
+		require.Empty(t, user.Calendar)
+
+		md := mustRun(t, SL, `/lotto task fill test-rotation#1 --dry-run --explain --now 2020-02-20`)
+		require.Contains(t, md.String(), "Dry run: would auto-assign @test-user3")
+		require.Contains(t, md.String(), "- Disqualified")
+		require.Contains(t, md.String(), "1. @test-user3 for web-◉")
+	})
+
+	t.Run("dry run error", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=ticket --beginning=2020-03-01
+			/lotto rotation set require -s web-1 --count 2 test-rotation
+			/lotto user join test-rotation @test-user1 @test-user2 --starting 2020-01-01
+			/lotto user qualify -s web-1 @test-user1
+			/lotto task new ticket test-rotation --now 2020-03-02
+			`)
+
+		out := &sl.OutFillTask{Changed: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task fill test-rotation#1 --dry-run --explain`, out)
+		require.Contains(t, out.Error, "insufficient")
+		require.Equal(t, []*sl.FillPick{{Need: "web-◉", MattermostUserID: "test-user1"}}, out.Explanation.Picks)
+
+		_, err := run(t, SL, `/lotto task fill test-rotation#1`)
+		require.Error(t, err)
+	})
 }
@@ -0,0 +1,122 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestTaskShadow(t *testing.T) {
+	t.Run("fill and graduate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		poster := &bot.TestPoster{}
+		SL, _ := getTestSLWithPoster(t, ctrl, poster)
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning 2020-03-01 --period weekly
+			/lotto rotation set require test-rotation -s server-1 --count 1
+			/lotto rotation set limit test-rotation -s any --count 1
+			/lotto rotation set trainee test-rotation --shadows 1 --graduate-after 1 --lead @test-lead
+			/lotto user qualify @test-user1 -s server-3
+			/lotto user join test-rotation @test-user1
+			/lotto user join test-rotation @test-trainee --trainee
+			/lotto task new shift test-rotation --number 1
+			/lotto task fill test-rotation#1
+			`)
+
+		r := mustRunRotation(t, SL, `/lotto rotation show test-rotation`)
+		require.Equal(t, []string{"test-user1"}, r.MattermostUserIDs.TestIDs())
+		require.Equal(t, []string{"test-trainee"}, r.TraineeMattermostUserIDs.TestIDs())
+
+		// The shadow does not count towards the limit.
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		require.Equal(t, []string{"test-user1"}, task.MattermostUserIDs.TestIDs())
+		require.Equal(t, []string{"test-trainee"}, task.ShadowMattermostUserIDs.TestIDs())
+
+		out := mustRun(t, SL, `/lotto task show test-rotation#1`)
+		require.Contains(t, out.String(), "  - Shadows: **1**\n")
+
+		trainee := mustRunUser(t, SL, `/lotto user show @test-trainee`)
+		require.Len(t, trainee.Calendar, 1)
+		require.Equal(t, types.ID("test-rotation#1"), trainee.Calendar[0].TaskID)
+		require.Nil(t, trainee.Shadowed)
+
+		poster.Reset()
+		mustRunMulti(t, SL, `
+			/lotto task schedule test-rotation#1
+			/lotto task start test-rotation#1
+			/lotto task finish test-rotation#1
+			`)
+		dms := map[string]int{}
+		for _, post := range poster.DirectPosts {
+			dms[post.UserID]++
+		}
+		require.Equal(t, map[string]int{"test-user1": 3, "test-trainee": 3, "test-lead": 1}, dms)
+		require.Equal(t, bot.TestPost{
+			UserID: "test-lead",
+			Message: "###### @test-trainee is ready to graduate\n" +
+				"@test-trainee has shadowed 1 tasks in test-rotation. Please consider " +
+				"`/lotto user qualify @test-trainee --skills server-1`, and `/lotto user join @test-trainee --rotation test-rotation`.",
+		}, poster.DirectPosts[len(poster.DirectPosts)-1])
+
+		trainee = mustRunUser(t, SL, `/lotto user show @test-trainee`)
+		require.Equal(t, int64(1), trainee.ShadowedCount("test-rotation"))
+	})
+
+	t.Run("graduate without a lead", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		poster := &bot.TestPoster{}
+		SL, _ := getTestSLWithPoster(t, ctrl, poster)
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning 2020-03-01 --period weekly
+			/lotto rotation set trainee test-rotation --shadows 1 --graduate-after 1
+			/lotto user join test-rotation @test-user1
+			/lotto user join test-rotation @test-trainee --trainee
+			/lotto task new shift test-rotation --number 1
+			/lotto task fill test-rotation#1
+			/lotto task schedule test-rotation#1
+			/lotto task start test-rotation#1
+			`)
+
+		// The graduation is counted, but there is no one to prompt.
+		poster.Reset()
+		mustRun(t, SL, `/lotto task finish test-rotation#1`)
+		for _, post := range poster.DirectPosts {
+			require.NotContains(t, post.Message, "ready to graduate")
+		}
+		trainee := mustRunUser(t, SL, `/lotto user show @test-trainee`)
+		require.Equal(t, int64(1), trainee.ShadowedCount("test-rotation"))
+	})
+
+	t.Run("assign and unassign", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=ticket
+			/lotto task new ticket test-rotation --summary test-summary1
+			/lotto task assign test-rotation#1 @test-user1
+			/lotto task assign test-rotation#1 @test-user2 --shadow
+			`)
+
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		require.Equal(t, sl.TaskStatePending, task.State)
+		require.Equal(t, []string{"test-user1"}, task.MattermostUserIDs.TestIDs())
+		require.Equal(t, []string{"test-user2"}, task.ShadowMattermostUserIDs.TestIDs())
+
+		_, err := run(t, SL, `/lotto task assign test-rotation#1 @test-user1 --shadow`)
+		require.Error(t, err)
+
+		mustRun(t, SL, `/lotto task unassign test-rotation#1 @test-user2`)
+		task = mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		require.Equal(t, []string{"test-user1"}, task.MattermostUserIDs.TestIDs())
+		require.Empty(t, task.ShadowMattermostUserIDs.TestIDs())
+	})
+}
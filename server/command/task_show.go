@@ -19,5 +19,12 @@ func (c *Command) taskShow(parameters []string) (md.MD, error) {
 	}
 
 	task, err := c.SL.LoadTask(taskID)
-	return c.normalOut(task, err)
+	if err != nil {
+		return "", err
+	}
+
+	if c.outputJSON {
+		return md.JSONBlock(task), nil
+	}
+	return task.MarkdownBullets(), nil
 }
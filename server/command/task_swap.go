@@ -0,0 +1,34 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func (c *Command) taskSwap(parameters []string) (md.MD, error) {
+	trade := c.flags().String("trade", "", "the other user's task to take in exchange")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if len(c.flags().Args()) != 2 {
+		return c.flagUsage(), errors.New("must specify the task, and one @username")
+	}
+	taskID, mattermostUserIDs, err := c.resolveTaskIDUsernames()
+	if err != nil {
+		return "", err
+	}
+
+	return c.normalOut(c.SL.RequestSwap(sl.InRequestSwap{
+		TaskID:           taskID,
+		MattermostUserID: mattermostUserIDs.IDs()[0],
+		TradeTaskID:      types.ID(*trade),
+		Time:             *c.now,
+	}))
+}
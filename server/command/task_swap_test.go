@@ -0,0 +1,197 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestTaskSwap(t *testing.T) {
+	setup := func(t *testing.T) (*gomock.Controller, *sl.Service, sl.SL, *bot.TestPoster) {
+		ctrl := gomock.NewController(t)
+		poster := &bot.TestPoster{}
+		service := getTestService(t, ctrl, poster)
+		SL := service.ActingAs("test-user")
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning 2020-03-01 --period weekly
+			/lotto rotation set require test-rotation -s web-1 --count 1
+			/lotto user join test-rotation @test-user @test-user2 @test-user3 --starting 2020-01-01
+			/lotto user qualify -s web-1 @test-user @test-user2
+			/lotto task new shift test-rotation --number 1
+			/lotto task new shift test-rotation --number 2
+			/lotto task assign test-rotation#1 @test-user
+			/lotto task assign test-rotation#2 @test-user2
+			/lotto task schedule test-rotation#1
+			/lotto task schedule test-rotation#2
+			`)
+		poster.Reset()
+		return ctrl, service, SL, poster
+	}
+
+	calendarTaskIDs := func(t *testing.T, SL sl.SL, username string) []types.ID {
+		ids := []types.ID{}
+		for _, u := range mustRunUser(t, SL, `/lotto user show @`+username).Calendar {
+			ids = append(ids, u.TaskID)
+		}
+		return ids
+	}
+
+	swapAction := func(t *testing.T, poster *bot.TestPoster, userID string) (types.ID, string) {
+		require.Len(t, poster.DirectPosts, 1)
+		post := poster.DirectPosts[0]
+		require.Equal(t, userID, post.UserID)
+		require.Len(t, post.Attachments, 1)
+		require.Len(t, post.Attachments[0].Actions, 2)
+		accept := post.Attachments[0].Actions[0]
+		require.Equal(t, "Accept", accept.Name)
+		require.Equal(t, true, accept.Integration.Context["accept"])
+		require.Equal(t, "Decline", post.Attachments[0].Actions[1].Name)
+		require.Equal(t, false, post.Attachments[0].Actions[1].Integration.Context["accept"])
+		return types.ID(accept.Integration.Context["swap_id"].(string)), post.Attachments[0].Text
+	}
+
+	t.Run("give away", func(t *testing.T) {
+		ctrl, service, SL, poster := setup(t)
+		defer ctrl.Finish()
+
+		_, err := run(t, SL, `/lotto task swap test-rotation#1 @test-user3`)
+		require.EqualError(t, err, "@test-user3 would leave the requirement for web-◉ unmet in test-rotation#1")
+
+		mustRun(t, SL, `/lotto task swap test-rotation#1 @test-user2`)
+		swapID, text := swapAction(t, poster, "test-user2")
+		require.Equal(t, "@test-user would like to give you test-rotation#1.", text)
+
+		// Only the user the swap is proposed to can respond.
+		_, err = SL.RespondSwap(sl.InRespondSwap{SwapID: swapID, Accept: true})
+		require.Error(t, err)
+
+		poster.Reset()
+		out, err := service.ActingAs("test-user2").RespondSwap(sl.InRespondSwap{SwapID: swapID, Accept: true})
+		require.NoError(t, err)
+		require.Equal(t, sl.SwapStateAccepted, out.Swap.State)
+		require.Equal(t, []bot.TestPost{{
+			UserID:  "test-user",
+			Message: "@test-user2 accepted your swap swap-test-rotation#1: test-rotation#1 from @test-user to @test-user2.",
+		}}, poster.DirectPosts)
+
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		require.Equal(t, []string{"test-user2"}, task.MattermostUserIDs.TestIDs())
+		require.Empty(t, calendarTaskIDs(t, SL, "test-user"))
+		require.Equal(t, []types.ID{"test-rotation#1", "test-rotation#2"}, calendarTaskIDs(t, SL, "test-user2"))
+
+		_, err = service.ActingAs("test-user2").RespondSwap(sl.InRespondSwap{SwapID: swapID, Accept: true})
+		require.EqualError(t, err, "swap swap-test-rotation#1: test-rotation#1 from @test-user to @test-user2 is already accepted")
+	})
+
+	t.Run("trade", func(t *testing.T) {
+		ctrl, service, SL, poster := setup(t)
+		defer ctrl.Finish()
+
+		mustRun(t, SL, `/lotto task swap test-rotation#1 @test-user2 --trade test-rotation#2`)
+		swapID, text := swapAction(t, poster, "test-user2")
+		require.Equal(t, "@test-user would like to trade test-rotation#1 for your test-rotation#2.", text)
+
+		_, err := service.ActingAs("test-user2").RespondSwap(sl.InRespondSwap{SwapID: swapID, Accept: true})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"test-user2"}, mustRunTask(t, SL, `/lotto task show test-rotation#1`).MattermostUserIDs.TestIDs())
+		require.Equal(t, []string{"test-user"}, mustRunTask(t, SL, `/lotto task show test-rotation#2`).MattermostUserIDs.TestIDs())
+		require.Equal(t, []types.ID{"test-rotation#2"}, calendarTaskIDs(t, SL, "test-user"))
+		require.Equal(t, []types.ID{"test-rotation#1"}, calendarTaskIDs(t, SL, "test-user2"))
+	})
+
+	t.Run("decline", func(t *testing.T) {
+		ctrl, service, SL, poster := setup(t)
+		defer ctrl.Finish()
+
+		mustRun(t, SL, `/lotto task swap test-rotation#1 @test-user2`)
+		swapID, _ := swapAction(t, poster, "test-user2")
+
+		out, err := service.ActingAs("test-user2").RespondSwap(sl.InRespondSwap{SwapID: swapID})
+		require.NoError(t, err)
+		require.Equal(t, sl.SwapStateDeclined, out.Swap.State)
+		require.Equal(t, []string{"test-user"}, mustRunTask(t, SL, `/lotto task show test-rotation#1`).MattermostUserIDs.TestIDs())
+		require.Equal(t, []types.ID{"test-rotation#1"}, calendarTaskIDs(t, SL, "test-user"))
+	})
+
+	t.Run("rotation rules", func(t *testing.T) {
+		ctrl, _, SL, _ := setup(t)
+		defer ctrl.Finish()
+
+		mustRun(t, SL, `/lotto user qualify -s web-1 @test-user4`)
+		_, err := run(t, SL, `/lotto task swap test-rotation#1 @test-user4`)
+		require.EqualError(t, err, "@test-user4 is not a member of test-rotation")
+
+		mustRun(t, SL, `/lotto rotation set workload test-rotation --max-tasks 1 --window 336h`)
+		_, err = run(t, SL, `/lotto task swap test-rotation#1 @test-user2`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "user @test-user2 would exceed the workload cap")
+		// The task traded away does not count.
+		mustRun(t, SL, `/lotto task swap test-rotation#1 @test-user2 --trade test-rotation#2`)
+		mustRun(t, SL, `/lotto rotation set workload test-rotation --clear`)
+
+		mustRun(t, SL, `/lotto rotation set pair test-rotation --mentee web-1 --mentor web-3`)
+		_, err = run(t, SL, `/lotto task swap test-rotation#1 @test-user2`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "@test-user2 would violate pair rules")
+	})
+
+	t.Run("rolled back", func(t *testing.T) {
+		ctrl, service, SL, poster := setup(t)
+		defer ctrl.Finish()
+
+		mustRun(t, SL, `/lotto task swap test-rotation#1 @test-user2 --trade test-rotation#2`)
+		swapID, _ := swapAction(t, poster, "test-user2")
+
+		// Storing the accepted swap fails after the users and the tasks are
+		// stored, they are rolled back.
+		store := service.Store
+		service.Store = kvstore.NewStore(&failingKVStore{KVStore: store, prefix: sl.KeySwap + "_"})
+		in := sl.InRespondSwap{SwapID: swapID, Accept: true, Time: types.MustParseTime("2020-03-02")}
+		_, err := service.ActingAs("test-user2").RespondSwap(in)
+		require.Error(t, err)
+		service.Store = store
+
+		require.Equal(t, []string{"test-user"}, mustRunTask(t, SL, `/lotto task show test-rotation#1`).MattermostUserIDs.TestIDs())
+		require.Equal(t, []string{"test-user2"}, mustRunTask(t, SL, `/lotto task show test-rotation#2`).MattermostUserIDs.TestIDs())
+		require.Equal(t, []types.ID{"test-rotation#1"}, calendarTaskIDs(t, SL, "test-user"))
+		require.Equal(t, []types.ID{"test-rotation#2"}, calendarTaskIDs(t, SL, "test-user2"))
+
+		// The swap is still pending, and can be accepted again.
+		out, err := service.ActingAs("test-user2").RespondSwap(in)
+		require.NoError(t, err)
+		require.Equal(t, sl.SwapStateAccepted, out.Swap.State)
+		require.Equal(t, []types.ID{"test-rotation#2"}, calendarTaskIDs(t, SL, "test-user"))
+	})
+
+	t.Run("not assigned", func(t *testing.T) {
+		ctrl, _, SL, _ := setup(t)
+		defer ctrl.Finish()
+
+		_, err := run(t, SL, `/lotto task swap test-rotation#2 @test-user3`)
+		require.EqualError(t, err, "@test-user is not assigned to test-rotation#2")
+	})
+}
+
+// failingKVStore fails to store the keys with the prefix.
+type failingKVStore struct {
+	kvstore.KVStore
+	prefix string
+}
+
+func (s *failingKVStore) Store(key string, data []byte) error {
+	if strings.HasPrefix(key, s.prefix) {
+		return errors.New("failed to store " + key)
+	}
+	return s.KVStore.Store(key, data)
+}
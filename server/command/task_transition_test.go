@@ -120,4 +120,90 @@ func TestTaskTransition(t *testing.T) {
 		mustRun(t, SL, `/lotto task finish test-rotation#0 --now=2020-04-19`)
 		checkCal("@u", 0, "2020-04-01T07:00", "2020-04-19T07:00", "2020-04-27T16:00")
 	})
+
+	t.Run("unschedule and cancel", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		poster := &bot.TestPoster{}
+		SL, _ := getTestSLWithPoster(t, ctrl, poster)
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=shift --beginning=2020-03-01
+			/lotto user join test-rotation @test-user1
+			`)
+		lastServed := mustRunUser(t, SL, `/lotto user show @test-user1`).LastServed.TestAsMap()
+		mustRunMulti(t, SL, `
+			/lotto task new shift test-rotation -n 0
+			/lotto task fill test-rotation#0
+			/lotto task schedule test-rotation#0
+			`)
+		require.Len(t, mustRunUser(t, SL, `/lotto user show @test-user1`).Calendar, 1)
+		poster.Reset()
+
+		mustRun(t, SL, `/lotto task unschedule test-rotation#0`)
+		require.Equal(t, []bot.TestPost{{
+			UserID:  "test-user1",
+			Message: "###### test-rotation#0 is no longer scheduled.\n@test-user moved test-rotation#0 back to pending, it has been removed from your calendar.",
+		}}, poster.DirectPosts)
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#0`)
+		require.Equal(t, sl.TaskStatePending, task.State)
+		require.Equal(t, []string{"test-user1"}, task.MattermostUserIDs.TestIDs())
+		user := mustRunUser(t, SL, `/lotto user show @test-user1`)
+		require.Empty(t, user.Calendar)
+		require.Equal(t, lastServed, user.LastServed.TestAsMap())
+
+		mustRun(t, SL, `/lotto task schedule test-rotation#0`)
+		require.Len(t, mustRunUser(t, SL, `/lotto user show @test-user1`).Calendar, 1)
+		poster.Reset()
+
+		mustRun(t, SL, `/lotto task cancel test-rotation#0`)
+		require.Equal(t, []bot.TestPost{{
+			UserID:  "test-user1",
+			Message: "###### test-rotation#0 is cancelled.\n@test-user cancelled test-rotation#0, it has been removed from your calendar.",
+		}}, poster.DirectPosts)
+		require.Equal(t, sl.TaskStateCancelled, mustRunTask(t, SL, `/lotto task show test-rotation#0`).State)
+		require.Empty(t, mustRunUser(t, SL, `/lotto user show @test-user1`).Calendar)
+
+		for _, transition := range []string{"schedule", "start", "finish", "unschedule"} {
+			_, err := run(t, SL, `/lotto task `+transition+` test-rotation#0`)
+			require.Error(t, err, transition)
+		}
+
+		// The autopilot does not re-create the cancelled shift.
+		mustRun(t, SL, `/lotto rotation set autopilot test-rotation --create --create-prior=24h`)
+		out := mustRun(t, SL, `/lotto rotation autopilot test-rotation --now=2020-03-01T10:00`)
+		require.Contains(t, out.String(), "create shift: nothing to do")
+		require.Equal(t, sl.TaskStateCancelled, mustRunTask(t, SL, `/lotto task show test-rotation#0`).State)
+	})
+
+	t.Run("autopilot does not reschedule", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=shift --beginning=2020-03-01T09:00 --period=weekly
+			/lotto user join test-rotation @test-user1
+			/lotto rotation set autopilot test-rotation --create --create-prior=48h --schedule --schedule-prior=48h --remind-start --remind-start-prior=24h
+			/lotto rotation autopilot test-rotation --now=2020-02-28T10:00
+			/lotto rotation autopilot test-rotation --now=2020-02-29T10:00
+			/lotto rotation autopilot test-rotation --now=2020-02-29T10:30
+			`)
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#0`)
+		require.Equal(t, sl.TaskStateScheduled, task.State)
+		require.True(t, task.AutopilotRemindedStart)
+
+		mustRun(t, SL, `/lotto task unschedule test-rotation#0`)
+		out := mustRun(t, SL, `/lotto rotation autopilot test-rotation --now=2020-02-29T11:00`)
+		require.Contains(t, out.String(), "fill and schedule: nothing to do")
+		task = mustRunTask(t, SL, `/lotto task show test-rotation#0`)
+		require.Equal(t, sl.TaskStatePending, task.State)
+		require.True(t, task.Unscheduled)
+		require.False(t, task.AutopilotRemindedStart)
+
+		// Once scheduled by hand, the autopilot reminds the users again.
+		mustRun(t, SL, `/lotto task schedule test-rotation#0`)
+		out = mustRun(t, SL, `/lotto rotation autopilot test-rotation --now=2020-02-29T12:00`)
+		require.Contains(t, out.String(), "start reminder: messaged 1 users")
+		task = mustRunTask(t, SL, `/lotto task show test-rotation#0`)
+		require.False(t, task.Unscheduled)
+		require.True(t, task.AutopilotRemindedStart)
+	})
 }
@@ -23,5 +23,6 @@ func (c *Command) taskUnassign(parameters []string) (md.MD, error) {
 		TaskID:            taskID,
 		MattermostUserIDs: mattermostUserIDs,
 		Force:             *force,
+		Time:              *c.now,
 	}))
 }
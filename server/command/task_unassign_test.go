@@ -5,9 +5,11 @@ package command
 import (
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
@@ -98,3 +100,78 @@ func TestTaskUnassign(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskUnassignCleanup(t *testing.T) {
+	setup := func(t *testing.T) (*gomock.Controller, sl.SL, *bot.TestPoster) {
+		ctrl := gomock.NewController(t)
+		poster := &bot.TestPoster{}
+		SL, _ := getTestSLWithPoster(t, ctrl, poster)
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning 2020-03-01 --period weekly
+			/lotto rotation set require test-rotation -s web-1 --count 1
+			/lotto user join test-rotation @test-user1 @test-user2 @test-user3 @test-user4 --starting 2020-01-01
+			/lotto user qualify -s web-1 @test-user1 @test-user2 @test-user3
+			/lotto task new shift test-rotation --number 1
+			/lotto task fill test-rotation#1 --now 2020-02-20
+			`)
+		poster.Reset()
+		return ctrl, SL, poster
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		ctrl, SL, poster := setup(t)
+		defer ctrl.Finish()
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		removedID := task.MattermostUserIDs.IDs()[0]
+		user := mustRunUser(t, SL, `/lotto user show @`+string(removedID))
+		require.Len(t, user.Calendar, 1)
+		require.Equal(t, "test-rotation#1", string(user.Calendar[0].TaskID))
+
+		out := &sl.OutAssignTask{Changed: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task unassign test-rotation#1 @`+string(removedID), out)
+		require.Nil(t, out.Replacement)
+
+		user = mustRunUser(t, SL, `/lotto user show @`+string(removedID))
+		require.Empty(t, user.Calendar)
+		require.Equal(t, map[types.ID]int64{
+			"test-rotation": 1577865600, // --starting 2020-01-01, PST
+		}, user.LastServed.TestAsMap())
+		require.Equal(t, []bot.TestPost{{
+			UserID:  string(removedID),
+			Message: "@test-user unassigned you from test-rotation#1, which is pending",
+		}}, poster.DirectPosts)
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		ctrl, SL, poster := setup(t)
+		defer ctrl.Finish()
+		mustRun(t, SL, `/lotto task schedule test-rotation#1`)
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		var removedID types.ID
+		for _, id := range task.MattermostUserIDs.IDs() {
+			if mustRunUser(t, SL, `/lotto user show @`+string(id)).SkillLevels.Get("web") > 0 {
+				removedID = id
+			}
+		}
+		require.NotEmpty(t, removedID)
+		poster.Reset()
+
+		out := &sl.OutAssignTask{Changed: sl.NewUsers(), Replacement: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task unassign test-rotation#1 @`+string(removedID)+` --force --now 2020-02-21`, out)
+		require.Equal(t, 1, out.Replacement.Len())
+		replacement := out.Replacement.AsArray()[0]
+		require.NotEqual(t, removedID, replacement.MattermostUserID)
+		require.Equal(t, int64(1), replacement.SkillLevels.Get("web"))
+		// The replacement is only proposed.
+		require.False(t, out.Task.MattermostUserIDs.Contains(replacement.MattermostUserID))
+
+		require.Empty(t, mustRunUser(t, SL, `/lotto user show @`+string(removedID)).Calendar)
+		require.Len(t, poster.DirectPosts, 2)
+		require.Equal(t, string(removedID), poster.DirectPosts[0].UserID)
+		require.Equal(t, "test-user", poster.DirectPosts[1].UserID)
+		require.Equal(t, "@test-user unassigned @"+string(removedID)+" from test-rotation#1, which is scheduled.\n"+
+			"Proposed replacement: @"+string(replacement.MattermostUserID)+" "+replacement.MarkdownSkills().String()+
+			". To assign, use `/lotto task assign test-rotation#1 @"+string(replacement.MattermostUserID)+" --force`.\n",
+			poster.DirectPosts[1].Message)
+	})
+}
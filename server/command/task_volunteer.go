@@ -0,0 +1,28 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func (c *Command) taskVolunteer(parameters []string) (md.MD, error) {
+	withdraw := c.flags().Bool("withdraw", false, "withdraw from the task's volunteers")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if len(c.flags().Args()) != 1 {
+		return c.flagUsage(), errors.New("must specify the task")
+	}
+
+	return c.normalOut(c.SL.Volunteer(sl.InVolunteer{
+		TaskID:   types.ID(c.flags().Arg(0)),
+		Withdraw: *withdraw,
+	}))
+}
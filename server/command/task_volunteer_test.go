@@ -0,0 +1,107 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestTaskVolunteer(t *testing.T) {
+	setup := `
+		/lotto rotation new test-rotation --task-type=ticket --beginning=2020-03-01
+		/lotto rotation set require -s web-1 --count 1 test-rotation
+		/lotto user join test-rotation @test-user @test-user1 @test-user2 @test-user3 @test-user4 --starting 2020-01-01
+		/lotto user qualify -s web-1 @test-user1 @test-user2 @test-user3 @test-user4
+		/lotto task new ticket test-rotation --summary test-summary1 --now 2020-03-01
+		`
+
+	t.Run("volunteer and withdraw", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		mustRunMulti(t, SL, setup)
+
+		out := mustRun(t, SL, `/lotto task volunteer test-rotation#1`)
+		require.Equal(t, "@test-user volunteered for test-rotation#1.", out.String())
+		task := mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		require.Equal(t, []string{"test-user"}, task.VolunteerMattermostUserIDs.TestIDs())
+
+		_, err := run(t, SL, `/lotto task volunteer test-rotation#1`)
+		require.EqualError(t, err, "@test-user has already volunteered for test-rotation#1")
+
+		mustRun(t, SL, `/lotto task volunteer test-rotation#1 --withdraw`)
+		task = mustRunTask(t, SL, `/lotto task show test-rotation#1`)
+		require.Empty(t, task.VolunteerMattermostUserIDs.TestIDs())
+
+		mustRun(t, SL, `/lotto user leave test-rotation`)
+		_, err = run(t, SL, `/lotto task volunteer test-rotation#1`)
+		require.EqualError(t, err, "@test-user is not a member of test-rotation")
+	})
+
+	t.Run("first come", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		service := getTestService(t, ctrl, &bot.NilPoster{})
+		SL := service.ActingAs("test-user")
+		mustRunMulti(t, SL, setup+`
+			/lotto rotation set fill test-rotation --volunteers first-come
+			`)
+
+		// test-user fills the "any" need, and test-user3 is the first to
+		// volunteer who qualifies for web-1.
+		mustRun(t, SL, `/lotto task volunteer test-rotation#1`)
+		_, err := service.ActingAs("test-user3").Volunteer(sl.InVolunteer{TaskID: "test-rotation#1"})
+		require.NoError(t, err)
+		_, err = service.ActingAs("test-user2").Volunteer(sl.InVolunteer{TaskID: "test-rotation#1"})
+		require.NoError(t, err)
+
+		out := &sl.OutFillTask{Changed: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task fill test-rotation#1 --explain --now 2020-02-20`, out)
+		require.Equal(t, []string{"test-user", "test-user3"}, out.Task.MattermostUserIDs.TestIDs())
+		require.ElementsMatch(t, []*sl.FillPick{
+			{Need: "any", MattermostUserID: "test-user"},
+			{Need: "web-◉", MattermostUserID: "test-user3"},
+		}, out.Explanation.Picks)
+	})
+
+	t.Run("weight", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		service := getTestService(t, ctrl, &bot.NilPoster{})
+		SL := service.ActingAs("test-user")
+		mustRunMulti(t, SL, setup)
+		_, err := service.ActingAs("test-user3").Volunteer(sl.InVolunteer{TaskID: "test-rotation#1"})
+		require.NoError(t, err)
+
+		out := &sl.OutFillTask{Changed: sl.NewUsers()}
+		mustRunJSON(t, SL, `/lotto task fill test-rotation#1 --dry-run --explain --now 2020-02-20`, out)
+		weights := map[types.ID]float64{}
+		for _, c := range out.Explanation.Pool {
+			weights[c.MattermostUserID] = c.Weight
+		}
+		require.Len(t, weights, 5)
+		require.InDelta(t, sl.VolunteerWeightFactor*weights["test-user1"], weights["test-user3"], 1e-9)
+	})
+
+	t.Run("queue weight", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		service := getTestService(t, ctrl, &bot.NilPoster{})
+		SL := service.ActingAs("test-user")
+		mustRunMulti(t, SL, setup+`
+			/lotto rotation set fill test-rotation --filler queue
+			`)
+		_, err := service.ActingAs("test-user4").Volunteer(sl.InVolunteer{TaskID: "test-rotation#1"})
+		require.NoError(t, err)
+
+		task := mustRunTaskAssign(t, SL, `/lotto task fill test-rotation#1 --now 2020-02-20`)
+		require.Equal(t, []string{"test-user4"}, task.MattermostUserIDs.TestIDs())
+	})
+
+}
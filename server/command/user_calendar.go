@@ -0,0 +1,22 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+)
+
+func (c *Command) userCalendar(parameters []string) (md.MD, error) {
+	reset := c.flags().Bool("reset", false, "replace the secret in the URLs, the existing subscriptions stop working")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+
+	return c.normalOut(
+		c.SL.CalendarToken(sl.InCalendarToken{
+			Reset: *reset,
+		}))
+}
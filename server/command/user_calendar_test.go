@@ -0,0 +1,101 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+)
+
+// feedToken returns the token from the calendar feed URL.
+func feedToken(t testing.TB, url string) string {
+	parts := strings.Split(strings.TrimPrefix(url, "https://pluginurl/api/v1/calendar/test-user/"), "/")
+	require.Len(t, parts, 2)
+	return parts[0]
+}
+
+func TestUserCalendar(t *testing.T) {
+	t.Run("feeds", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --beginning 2020-03-01T09:00 --period weekly
+			/lotto rotation set task test-rotation --duration 8h --grace 24h
+			/lotto user join test-rotation @test-user @test-user2 --starting 2019-01-01
+			/lotto task new shift test-rotation --number 1
+			/lotto task assign test-rotation#1 @test-user @test-user2
+			/lotto task schedule test-rotation#1
+			/lotto task new shift test-rotation --number 2
+			/lotto user unavailable --start 2020-03-20 --finish 2020-03-22
+		`)
+
+		out := &sl.OutCalendarToken{}
+		mustRunJSON(t, SL, `/lotto user calendar`, out)
+		require.True(t, strings.HasPrefix(out.UserFeedURL, "https://pluginurl/api/v1/calendar/test-user/"))
+		require.True(t, strings.HasSuffix(out.UserFeedURL, "/user.ics"))
+		require.Equal(t, strings.TrimSuffix(out.UserFeedURL, "user.ics")+"rotation/test-rotation.ics",
+			out.RotationFeedURLs["test-rotation"])
+		token := feedToken(t, out.UserFeedURL)
+
+		again := &sl.OutCalendarToken{}
+		mustRunJSON(t, SL, `/lotto user calendar`, again)
+		require.Equal(t, out.UserFeedURL, again.UserFeedURL)
+
+		exported, err := SL.ExportCalendar(sl.InExportCalendar{Token: token})
+		require.NoError(t, err)
+		require.Equal(t, "On call: @test-user", exported.Name)
+		require.Len(t, exported.Events, 3)
+		require.Equal(t, "On call: test-rotation#1", exported.Events[0].Summary)
+		require.Equal(t, "Rotation test-rotation", exported.Events[0].Description)
+		require.Equal(t, "2020-03-08T17:00", exported.Events[0].Start.UTC().Format("2006-01-02T15:04"))
+		require.Equal(t, "Grace period after test-rotation#1", exported.Events[1].Summary)
+		require.Equal(t, "Unavailable", exported.Events[2].Summary)
+
+		exported, err = SL.ExportCalendar(sl.InExportCalendar{Token: token, RotationID: "test-rotation"})
+		require.NoError(t, err)
+		require.Len(t, exported.Events, 2)
+		require.Equal(t, "task-test-rotation#1@solar-lottery", exported.Events[0].UID)
+		require.Equal(t, "test-rotation#1: @test-user, @test-user2", exported.Events[0].Summary)
+		require.Equal(t, "Status: scheduled", exported.Events[0].Description)
+		require.Equal(t, "test-rotation#2: unassigned", exported.Events[1].Summary)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		_, err := SL.ExportCalendar(sl.InExportCalendar{Token: "nothing-yet"})
+		require.Equal(t, sl.ErrInvalidCalendarToken, err)
+
+		out := &sl.OutCalendarToken{}
+		mustRunJSON(t, SL, `/lotto user calendar`, out)
+		token := feedToken(t, out.UserFeedURL)
+		_, err = SL.ExportCalendar(sl.InExportCalendar{})
+		require.Equal(t, sl.ErrInvalidCalendarToken, err)
+
+		mustRunJSON(t, SL, `/lotto user calendar --reset`, out)
+		require.NotEqual(t, token, feedToken(t, out.UserFeedURL))
+		_, err = SL.ExportCalendar(sl.InExportCalendar{Token: token})
+		require.Equal(t, sl.ErrInvalidCalendarToken, err)
+		_, err = SL.ExportCalendar(sl.InExportCalendar{Token: feedToken(t, out.UserFeedURL)})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid token stores nothing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		service := getTestService(t, ctrl, nil)
+
+		_, err := service.ActingAs("test-stranger").ExportCalendar(sl.InExportCalendar{Token: "garbage"})
+		require.Equal(t, sl.ErrInvalidCalendarToken, err)
+		err = service.Store.Entity(sl.KeyUser).Load("test-stranger", &sl.User{})
+		require.Equal(t, kvstore.ErrNotFound, err)
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func (c *Command) userHistory(parameters []string) (md.MD, error) {
+	rotation := c.flags().StringP("rotation", "r", "", "only show the tasks served in the rotation")
+	start, err := c.withTimeFlag("start", "only show the tasks served after this time")
+	if err != nil {
+		return "", err
+	}
+	finish, err := c.withTimeFlag("finish", "only show the tasks served before this time")
+	if err != nil {
+		return "", err
+	}
+	err = c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+
+	mattermostUserIDs, err := c.resolveUsernames(c.flags().Args())
+	if err != nil {
+		return "", err
+	}
+	rotationID := types.ID("")
+	if *rotation != "" {
+		rotationID, err = c.SL.ResolveRotationName(*rotation)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return c.normalOut(
+		c.SL.LoadServiceHistory(sl.InServiceHistory{
+			MattermostUserIDs: mattermostUserIDs,
+			RotationID:        rotationID,
+			Interval:          types.NewInterval(*start, *finish),
+		}))
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestUserHistory(t *testing.T) {
+	ctrl, SL := defaultEnv(t)
+	defer ctrl.Finish()
+	mustRunMulti(t, SL, `
+		/lotto rotation new test-rotation --task-type=ticket
+		/lotto rotation new other-rotation --task-type=ticket
+		/lotto task new ticket test-rotation --now 2020-01-01T10:00
+		/lotto task assign test-rotation#1 @test-user1 @test-user2
+		/lotto task schedule test-rotation#1 --now 2020-01-01T09:00
+		/lotto task start test-rotation#1 --now 2020-01-01T10:00
+		/lotto task finish test-rotation#1 --now 2020-01-01T13:30
+		/lotto task new ticket other-rotation --now 2020-02-01T10:00
+		/lotto task assign other-rotation#1 @test-user1
+		/lotto task schedule other-rotation#1 --now 2020-02-01T09:00
+		/lotto task start other-rotation#1 --now 2020-02-01T10:00
+		/lotto task finish other-rotation#1 --now 2020-02-01T11:00
+		`)
+
+	history := func(cmd string) []*sl.ServiceHistory {
+		out := &sl.OutServiceHistory{}
+		mustRunJSON(t, SL, cmd, out)
+		return out.History
+	}
+
+	h := history(`/lotto user history @test-user1 @test-user2`)
+	require.Len(t, h, 2)
+	require.Equal(t, types.ID("test-user1"), h[0].MattermostUserID)
+	require.Equal(t, []*sl.ServiceRecord{
+		{
+			RotationID: "test-rotation",
+			TaskID:     "test-rotation#1",
+			Start:      types.MustParseTime("2020-01-01T18:00"),
+			Finish:     types.MustParseTime("2020-01-01T21:30"),
+			Hours:      3.5,
+		},
+		{
+			RotationID: "other-rotation",
+			TaskID:     "other-rotation#1",
+			Start:      types.MustParseTime("2020-02-01T18:00"),
+			Finish:     types.MustParseTime("2020-02-01T19:00"),
+			Hours:      1,
+		},
+	}, h[0].Records)
+	require.Len(t, h[1].Records, 1)
+
+	h = history(`/lotto user history @test-user1 --rotation other-rotation`)
+	require.Len(t, h[0].Records, 1)
+	require.Equal(t, types.ID("other-rotation#1"), h[0].Records[0].TaskID)
+
+	h = history(`/lotto user history @test-user1 --start 2020-01-15 --finish 2020-03-01`)
+	require.Len(t, h[0].Records, 1)
+	require.Equal(t, types.ID("other-rotation#1"), h[0].Records[0].TaskID)
+
+	h = history(`/lotto user history @test-user2 --start 2020-01-15`)
+	require.Empty(t, h[0].Records)
+
+	out := mustRun(t, SL, `/lotto user history @test-user1`)
+	require.Equal(t, `- @test-user1: served **2** tasks, **4.5** hours
+  - test-rotation test-rotation#1: 2020-01-01T10:00 to 2020-01-01T13:30, 3.5 hours
+  - other-rotation other-rotation#1: 2020-02-01T10:00 to 2020-02-01T11:00, 1.0 hours
+`, out.String())
+
+	// The tasks finished without starting, before or after they were
+	// expected to start, are recorded with zero hours.
+	mustRunMulti(t, SL, `
+		/lotto rotation new shift-rotation --beginning 2020-04-01 --period weekly
+		/lotto task new shift shift-rotation --number 0
+		/lotto task assign shift-rotation#0 @test-user3
+		/lotto task finish shift-rotation#0 --now 2020-03-15
+		/lotto task new shift shift-rotation --number 1
+		/lotto task assign shift-rotation#1 @test-user3
+		/lotto task finish shift-rotation#1 --now 2020-04-10
+		/lotto task new ticket test-rotation --now 2020-05-01T10:00
+		/lotto task assign test-rotation#2 @test-user3
+		/lotto task finish test-rotation#2 --now 2020-05-01T11:00
+		`)
+	h = history(`/lotto user history @test-user3`)
+	require.Len(t, h[0].Records, 3)
+	for _, rec := range h[0].Records {
+		require.Equal(t, rec.Finish, rec.Start)
+		require.Equal(t, 0.0, rec.Hours)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func (c *Command) userHolidays(parameters []string) (md.MD, error) {
+	calendar := c.flags().String("calendar", "", "regional holiday calendar")
+	clear := c.flags().Bool("clear", false, "clear the holiday calendar")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if *calendar == "" && !*clear {
+		return c.flagUsage(), errors.New("must specify --calendar, or --clear")
+	}
+	if *clear {
+		*calendar = ""
+	}
+
+	mattermostUserIDs, err := c.resolveUsernames(c.flags().Args())
+	if err != nil {
+		return "", err
+	}
+
+	return c.normalOut(
+		c.SL.SetUserHolidays(sl.InSetUserHolidays{
+			MattermostUserIDs: mattermostUserIDs,
+			CalendarID:        types.ID(*calendar),
+		}))
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package command
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+)
+
+func (c *Command) userHours(parameters []string) (md.MD, error) {
+	timeZone := c.flags().String("timezone", "", "time zone, as Europe/Berlin; defaults to the user's Mattermost time zone")
+	start := c.flags().String("start", "", "start of the working day, as 09:00")
+	finish := c.flags().String("finish", "", "end of the working day, as 17:00")
+	days := c.flags().StringSlice("days", nil, "working days, as Mon,Tue,Wed; defaults to Mon-Fri")
+	clear := c.flags().Bool("clear", false, "clear the working hours")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+
+	var workingHours *sl.WorkingHours
+	if !*clear {
+		if *start == "" || *finish == "" {
+			return c.flagUsage(), errors.New("--start and --finish are required")
+		}
+		workingHours, err = sl.NewWorkingHours(*timeZone, *start, *finish, *days)
+		if err != nil {
+			return c.flagUsage(), err
+		}
+	}
+
+	mattermostUserIDs, err := c.resolveUsernames(c.flags().Args())
+	if err != nil {
+		return "", err
+	}
+
+	return c.normalOut(
+		c.SL.SetWorkingHours(sl.InSetWorkingHours{
+			MattermostUserIDs: mattermostUserIDs,
+			WorkingHours:      workingHours,
+		}))
+}
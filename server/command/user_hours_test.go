@@ -0,0 +1,76 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestUserHours(t *testing.T) {
+	t.Run("set and clear", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+
+		mustRun(t, SL, `/lotto user hours @test-user1 --start 09:00 --finish 17:00 --timezone Europe/Berlin --days mon,tue`)
+		user := mustRunUser(t, SL, `/lotto user show @test-user1`)
+		require.Equal(t, &sl.WorkingHours{
+			TimeZone: "Europe/Berlin",
+			Start:    "09:00",
+			Finish:   "17:00",
+			Weekdays: []string{"Mon", "Tue"},
+		}, user.WorkingHours)
+
+		mustRun(t, SL, `/lotto user hours @test-user1 --clear`)
+		user = mustRunUser(t, SL, `/lotto user show @test-user1`)
+		require.Nil(t, user.WorkingHours)
+
+		_, err := run(t, SL, `/lotto user hours @test-user1 --start 9am --finish 17:00`)
+		require.Error(t, err)
+	})
+
+	t.Run("follow the sun", func(t *testing.T) {
+		// The shift is 00:00-08:00 PST, during the working hours in Berlin.
+		for _, tc := range []struct {
+			policy                string
+			expected              string
+			expectNotDisqualified bool
+		}{
+			{policy: "require", expected: "test-user2"},
+			{policy: "prefer", expected: "test-user2", expectNotDisqualified: true},
+			{policy: "ignore", expected: "test-user1", expectNotDisqualified: true},
+		} {
+			t.Run(tc.policy, func(t *testing.T) {
+				ctrl, SL := defaultEnv(t)
+				defer ctrl.Finish()
+				mustRunMulti(t, SL, `
+					/lotto rotation new test-rotation --beginning 2020-03-02 --period weekly
+					/lotto rotation set task test-rotation --duration 8h
+					/lotto rotation set fill test-rotation --working-hours `+tc.policy+`
+					/lotto user join test-rotation @test-user1 --starting 2019-01-01
+					/lotto user join test-rotation @test-user2 --starting 2020-02-20
+					/lotto user hours @test-user1 --start 09:00 --finish 17:00
+					/lotto user hours @test-user2 --start 09:00 --finish 17:00 --timezone Europe/Berlin
+					/lotto task new shift test-rotation --number 1
+					`)
+
+				out := &sl.OutFillTask{Changed: sl.NewUsers()}
+				mustRunJSON(t, SL, `/lotto task fill test-rotation#1 --dry-run --explain`, out)
+				require.Equal(t, []string{tc.expected}, out.Task.MattermostUserIDs.TestIDs())
+				if tc.expectNotDisqualified {
+					require.Empty(t, out.Explanation.Disqualified)
+					return
+				}
+				require.Equal(t, []*sl.FillDisqualified{{
+					MattermostUserID: types.ID("test-user1"),
+					Reason:           sl.DisqualifiedWorkingHours,
+					Details:          "09:00-17:00 Mon,Tue,Wed,Thu,Fri",
+				}}, out.Explanation.Disqualified)
+			})
+		}
+	})
+}
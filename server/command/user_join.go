@@ -15,6 +15,7 @@ func (c *Command) userJoin(parameters []string) (md.MD, error) {
 	if err != nil {
 		return c.flagUsage(), err
 	}
+	trainee := c.flags().Bool("trainee", false, "join as a trainee, to shadow tasks")
 	err = c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
@@ -30,5 +31,7 @@ func (c *Command) userJoin(parameters []string) (md.MD, error) {
 			MattermostUserIDs: mattermostUserIDs,
 			RotationID:        rotationID,
 			Starting:          *starting,
+			Time:              *c.now,
+			Trainee:           *trainee,
 		}))
 }
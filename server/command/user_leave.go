@@ -24,5 +24,6 @@ func (c *Command) userLeave(parameters []string) (md.MD, error) {
 		c.SL.LeaveRotation(sl.InJoinRotation{
 			MattermostUserIDs: mattermostUserIDs,
 			RotationID:        rotationID,
+			Time:              *c.now,
 		}))
 }
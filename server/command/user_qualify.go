@@ -10,7 +10,11 @@ import (
 
 func (c *Command) userQualify(parameters []string) (md.MD, error) {
 	skills := c.flags().StringSliceP("skills", "s", nil, "skills, with optional levels (1-4) as in `--skills=web-3,server-2`.")
-	err := c.parse(parameters)
+	expires, err := c.withTimeFlag("expires", "the qualification expires at this time, and needs recertification")
+	if err != nil {
+		return "", err
+	}
+	err = c.parse(parameters)
 	if err != nil {
 		return c.flagUsage(), err
 	}
@@ -34,5 +38,7 @@ func (c *Command) userQualify(parameters []string) (md.MD, error) {
 		c.SL.Qualify(sl.InQualify{
 			MattermostUserIDs: mattermostUserIDs,
 			SkillLevels:       skillLevels,
+			Time:              *c.now,
+			Expires:           *expires,
 		}))
 }
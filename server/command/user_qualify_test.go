@@ -39,4 +39,42 @@ func TestUserQualify(t *testing.T) {
 		require.Equal(t, []string{"test-user"}, users.TestIDs())
 		require.Equal(t, int64(3), users.Get("test-user").SkillLevels.Get("somethingelse"))
 	})
+
+	t.Run("expiry", func(t *testing.T) {
+		ctrl, SL := defaultEnv(t)
+		defer ctrl.Finish()
+		// The queue filler would pick test-user1 first, if qualified.
+		mustRunMulti(t, SL, `
+			/lotto rotation new test-rotation --task-type=ticket --beginning=2020-03-01
+			/lotto rotation set fill test-rotation --filler queue
+			/lotto rotation set require -s web-1 --count 1 test-rotation
+			/lotto user join test-rotation @test-user1 @test-user2 --starting 2020-01-01
+			/lotto user qualify -s web-2 @test-user1 --expires 2090-01-01 --now 2019-06-01
+			/lotto user qualify -s web-1 @test-user2 --expires 2099-01-01 --now 2019-06-01
+			/lotto task new ticket test-rotation --summary test-summary1 --now 2089-12-01
+			/lotto task new ticket test-rotation --summary test-summary2 --now 2090-02-20
+			`)
+
+		user := mustRunUser(t, SL, `/lotto user show @test-user1`)
+		require.Equal(t, int64(2), user.SkillLevels.Get("web"))
+		require.Equal(t, "2019-06-01T07:00", user.SkillGrants["web"].GrantedAt.String())
+		require.Equal(t, "2090-01-01T08:00", user.SkillGrants["web"].Expires.String())
+		require.Equal(t, types.ID("test-user"), user.SkillGrants["web"].GrantedBy)
+		require.Equal(t, int64(2), user.SkillLevel("web", types.MustParseTime("2089-12-01").Time))
+		require.Equal(t, int64(0), user.SkillLevel("web", types.MustParseTime("2090-02-20").Time))
+
+		// The qualification counts for the task that starts before it
+		// expires, and not for the one that starts after, even if filled
+		// long before.
+		task := mustRunTaskAssign(t, SL, `/lotto task fill test-rotation#2 --now 2020-02-20`)
+		require.Equal(t, []string{"test-user2"}, task.MattermostUserIDs.TestIDs())
+		task = mustRunTaskAssign(t, SL, `/lotto task fill test-rotation#1 --now 2020-02-20`)
+		require.Equal(t, []string{"test-user1"}, task.MattermostUserIDs.TestIDs())
+
+		// Recertification renews the grant.
+		mustRun(t, SL, `/lotto user qualify -s web-2 @test-user1 --now 2020-02-21`)
+		user = mustRunUser(t, SL, `/lotto user show @test-user1`)
+		require.True(t, user.SkillGrants["web"].Expires.IsZero())
+		require.Equal(t, int64(2), user.SkillLevel("web", types.MustParseTime("2090-02-20").Time))
+	})
 }
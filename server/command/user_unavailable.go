@@ -4,12 +4,19 @@
 package command
 
 import (
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
 func (c *Command) userUnavailable(parameters []string) (md.MD, error) {
+	if len(parameters) > 0 && parameters[0] == "import" {
+		c.actualTrigger += " import"
+		return c.userUnavailableImport(parameters[1:])
+	}
+
 	clear := c.flags().Bool("clear", false, "mark as available by clearing all overlapping unavailability events")
 	start, err := c.withTimeFlag("start", "start time")
 	if err != nil {
@@ -44,3 +51,37 @@ func (c *Command) userUnavailable(parameters []string) (md.MD, error) {
 			Unavailable:       sl.NewUnavailable(sl.ReasonPersonal, interval),
 		}))
 }
+
+func (c *Command) userUnavailableImport(parameters []string) (md.MD, error) {
+	url := c.flags().String("url", "", "URL of the .ics calendar to import")
+	timeZone := c.flags().String("timezone", "", "time zone for the all-day events, as Europe/Berlin; defaults to yours")
+	calendar := c.flags().String("calendar", sl.DefaultCalendarSource, "name of the calendar, to import several")
+	err := c.parse(parameters)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+	if *url == "" {
+		return c.flagUsage(), errors.New("--url is required")
+	}
+	loc, err := c.location(*timeZone)
+	if err != nil {
+		return c.flagUsage(), err
+	}
+
+	mattermostUserIDs, err := c.resolveUsernames(c.flags().Args())
+	if err != nil {
+		return "", err
+	}
+	events, err := fetchCalendar(*url, loc)
+	if err != nil {
+		return "", err
+	}
+
+	return c.normalOut(
+		c.SL.ImportCalendar(sl.InImportCalendar{
+			MattermostUserIDs: mattermostUserIDs,
+			Events:            events,
+			Time:              *c.now,
+			Source:            *calendar,
+		}))
+}
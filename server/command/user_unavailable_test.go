@@ -55,4 +55,106 @@ func TestUserUnavailable(t *testing.T) {
 			},
 			users.Get("test-user").Calendar[1])
 	})
+	t.Run("import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		SL, store := getTestSL(t, ctrl)
+
+		server := serveICS(`BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:vacation@test
+DTSTART;VALUE=DATE:20250210
+DTEND;VALUE=DATE:20250215
+SUMMARY:Vacation
+END:VEVENT
+BEGIN:VEVENT
+UID:dentist@test
+DTSTART:20250120T170000Z
+DTEND:20250120T180000Z
+SUMMARY:Dentist
+END:VEVENT
+BEGIN:VEVENT
+UID:lunch@test
+DTSTART:20250121T200000Z
+DTEND:20250121T210000Z
+TRANSP:TRANSPARENT
+SUMMARY:Lunch
+END:VEVENT
+BEGIN:VEVENT
+UID:past@test
+DTSTART;VALUE=DATE:20241201
+SUMMARY:Past
+END:VEVENT
+END:VCALENDAR
+`)
+		defer server.Close()
+
+		mustRun(t, SL, `/lotto user unavailable --start 2025-03-01 --finish 2025-03-02`)
+		out := mustRun(t, SL, `/lotto user unavailable import --url `+server.URL+` --now 2025-01-01`)
+		require.Equal(t, "imported calendar to @test-user: added 2, updated 0, removed 0 unavailable events.", out.String())
+		out = mustRun(t, SL, `/lotto user unavailable import --url `+server.URL+` --now 2025-01-01`)
+		require.Equal(t, "imported calendar to @test-user: added 0, updated 0, removed 0 unavailable events.", out.String())
+
+		user := sl.NewUser("")
+		err := store.Entity(sl.KeyUser).Load("test-user", user)
+		require.NoError(t, err)
+		require.Len(t, user.Calendar, 3)
+		require.Equal(t, "dentist@test", user.Calendar[0].UID)
+		require.Equal(t, sl.ReasonPersonal, user.Calendar[0].Reason)
+		require.Equal(t, types.MustParseInterval("2025-01-20T17:00", "2025-01-20T18:00"), user.Calendar[0].Interval)
+		require.Equal(t, "vacation@test", user.Calendar[1].UID)
+		require.Equal(t, types.MustParseInterval("2025-02-10T08:00", "2025-02-15T08:00"), user.Calendar[1].Interval)
+		require.Equal(t, "", user.Calendar[2].UID)
+
+		// The vacation is extended, and the dentist appointment is cancelled.
+		updated := serveICS(`BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:vacation@test
+DTSTART;VALUE=DATE:20250210
+DTEND;VALUE=DATE:20250217
+SUMMARY:Vacation
+END:VEVENT
+END:VCALENDAR
+`)
+		defer updated.Close()
+		out = mustRun(t, SL, `/lotto user unavailable import --url `+updated.URL+` --now 2025-01-01`)
+		require.Equal(t, "imported calendar to @test-user: added 0, updated 1, removed 1 unavailable events.", out.String())
+
+		user = sl.NewUser("")
+		err = store.Entity(sl.KeyUser).Load("test-user", user)
+		require.NoError(t, err)
+		require.Len(t, user.Calendar, 2)
+		require.Equal(t, "vacation@test", user.Calendar[0].UID)
+		require.Equal(t, sl.DefaultCalendarSource, user.Calendar[0].Source)
+		require.Equal(t, types.MustParseInterval("2025-02-10T08:00", "2025-02-17T08:00"), user.Calendar[0].Interval)
+		require.Equal(t, "", user.Calendar[1].UID)
+
+		// Another calendar does not replace the first.
+		work := serveICS(`BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:offsite@test
+DTSTART;VALUE=DATE:20250120
+DTEND;VALUE=DATE:20250122
+SUMMARY:Offsite
+END:VEVENT
+END:VCALENDAR
+`)
+		defer work.Close()
+		out = mustRun(t, SL, `/lotto user unavailable import --url `+work.URL+` --calendar work --now 2025-01-01`)
+		require.Equal(t, "imported calendar to @test-user: added 1, updated 0, removed 0 unavailable events.", out.String())
+
+		// The finished events are kept on re-import.
+		out = mustRun(t, SL, `/lotto user unavailable import --url `+work.URL+` --calendar work --now 2025-02-01`)
+		require.Equal(t, "imported calendar to @test-user: added 0, updated 0, removed 0 unavailable events.", out.String())
+		out = mustRun(t, SL, `/lotto user unavailable import --url `+updated.URL+` --now 2025-02-01`)
+		require.Equal(t, "imported calendar to @test-user: added 0, updated 0, removed 0 unavailable events.", out.String())
+
+		user = sl.NewUser("")
+		err = store.Entity(sl.KeyUser).Load("test-user", user)
+		require.NoError(t, err)
+		require.Len(t, user.Calendar, 3)
+		require.Equal(t, "offsite@test", user.Calendar[0].UID)
+		require.Equal(t, "work", user.Calendar[0].Source)
+		require.Equal(t, "vacation@test", user.Calendar[1].UID)
+	})
 }
@@ -8,10 +8,19 @@ import (
 // config.
 type StoredConfig struct {
 	bot.BotConfig
+
+	// The plugin-wide workload cap, for the rotations that do not set their
+	// own. Stored as text, empty means no cap.
+	WorkloadMaxTasks   string
+	WorkloadMaxHours   string
+	WorkloadWindowDays string
 }
 
 func (c StoredConfig) Map(onto map[string]interface{}) map[string]interface{} {
 	out := c.BotConfig.ToStorable(nil)
+	out["WorkloadMaxTasks"] = c.WorkloadMaxTasks
+	out["WorkloadMaxHours"] = c.WorkloadMaxHours
+	out["WorkloadWindowDays"] = c.WorkloadWindowDays
 	return out
 }
 
@@ -8,6 +8,15 @@ const (
 	CommandTrigger = "lotto"
 )
 
+// The plugin's HTTP paths, relative to the plugin's URL.
+const (
+	PathAPI        = "/api/v1"
+	PathCalendar   = "/calendar"
+	PathPostAction = "/action"
+	PathSwap       = "/swap"
+	PathVolunteer  = "/volunteer"
+)
+
 const (
 	BotUserName    = "solar-lottery"
 	BotDisplayName = "Solar Lottery"
@@ -0,0 +1,100 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package plugin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+const (
+	autopilotInterval = 5 * time.Minute
+	keyAutopilotTick  = "autopilot_tick_"
+)
+
+// autopilot periodically runs autopilot on all active rotations. The ticks are
+// aligned on autopilotInterval boundaries so that all nodes in a cluster agree
+// on the tick's time, and only the node that claims the tick runs it.
+type autopilot struct {
+	p    *Plugin
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (p *Plugin) startAutopilot() {
+	a := &autopilot{
+		p:    p,
+		done: make(chan struct{}),
+	}
+	a.wg.Add(1)
+	go a.loop()
+	p.autopilot = a
+}
+
+func (p *Plugin) stopAutopilot() {
+	if p.autopilot == nil {
+		return
+	}
+	close(p.autopilot.done)
+	p.autopilot.wg.Wait()
+	p.autopilot = nil
+}
+
+func (a *autopilot) loop() {
+	defer a.wg.Done()
+	for {
+		now := time.Now()
+		next := now.Truncate(autopilotInterval).Add(autopilotInterval)
+		select {
+		case <-a.done:
+			return
+		case <-time.After(next.Sub(now)):
+			a.tick(next)
+		}
+	}
+}
+
+func (a *autopilot) tick(tick time.Time) {
+	logger := a.p.bot.Timed()
+	claimed, err := a.claim(tick)
+	if err != nil {
+		logger.Errorf("autopilot: failed to claim tick %v: %v", tick, err)
+		return
+	}
+	if !claimed {
+		logger.Debugf("autopilot: tick %v is handled by another server", tick)
+		return
+	}
+
+	out, err := a.p.sl.ActingAs(types.ID(a.p.botUserID)).RunAutopilotAll(&sl.InRunAutopilotAll{
+		Time:    types.NewTime(tick),
+		Trigger: sl.AutopilotTriggerScheduled,
+	})
+	if err != nil {
+		logger.Errorf("autopilot: failed: %v", err)
+		return
+	}
+	logger.Infof("autopilot: %s", out.Markdown())
+}
+
+// claim atomically marks the tick as taken. It succeeds on exactly one node in
+// the cluster; the key expires after a few intervals.
+func (a *autopilot) claim(tick time.Time) (bool, error) {
+	key := fmt.Sprintf("%s%v", keyAutopilotTick, tick.Unix())
+	claimed, appErr := a.p.API.KVSetWithOptions(key, []byte(model.NewId()), model.PluginKVSetOptions{
+		Atomic:          true,
+		OldValue:        nil,
+		ExpireInSeconds: int64(3 * autopilotInterval / time.Second),
+	})
+	if appErr != nil {
+		return false, appErr
+	}
+	return claimed, nil
+}
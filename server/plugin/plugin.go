@@ -17,10 +17,12 @@ import (
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/config"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/constants"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/queue"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/filler/solarlottery"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/webhook"
 )
 
 type Plugin struct {
@@ -31,6 +33,7 @@ type Plugin struct {
 	api    *api.Service
 	config config.Service
 
+	autopilot *autopilot
 	botUserID string
 }
 
@@ -56,13 +59,14 @@ func (p *Plugin) OnActivate() error {
 		PluginAPI: p,
 		Config:    p.config,
 		TaskFillers: map[types.ID]sl.TaskFiller{
-			// queue.Type:        queue.New(bot),
+			queue.Type:        queue.New(),
 			solarlottery.Type: solarlottery.New(),
 			"":                solarlottery.New(), // default
 		},
-		Logger: p.bot,
-		Poster: p.bot,
-		Store:  kvstore.NewStore(kvstore.NewPluginStore(p.API)),
+		Logger:   p.bot,
+		Poster:   p.bot,
+		Store:    kvstore.NewStore(kvstore.NewPluginStore(p.API)),
+		Webhooks: webhook.NewSender(p.bot),
 	}
 
 	router := &mux.Router{}
@@ -70,6 +74,15 @@ func (p *Plugin) OnActivate() error {
 	router.Handle("{anything:.*}", http.NotFoundHandler())
 
 	command.Register(p.API.RegisterCommand)
+	p.startAutopilot()
+	return nil
+}
+
+func (p *Plugin) OnDeactivate() error {
+	p.stopAutopilot()
+	if p.sl.Webhooks != nil {
+		p.sl.Webhooks.Close()
+	}
 	return nil
 }
 
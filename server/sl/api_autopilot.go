@@ -4,6 +4,12 @@
 package sl
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
@@ -11,26 +17,56 @@ import (
 type InRunAutopilot struct {
 	RotationID types.ID
 	Time       types.Time
+
+	// Trigger is recorded in the autopilot history, manual by default.
+	Trigger types.ID `json:",omitempty"`
+
+	// DryRun runs the autopilot against an in-memory copy of the store, and
+	// collects the direct messages instead of sending them.
+	DryRun bool
 }
 
 type OutRunAutopilot struct {
 	md.MD
 	Rotation *Rotation
+	Run      *AutopilotRun
+
+	// DryRunMessages are the direct messages the dry run would have sent.
+	DryRunMessages []*AutopilotMessage `json:",omitempty"`
+}
 
-	messages []md.MD
+type AutopilotMessage struct {
+	MattermostUserID types.ID
+	Message          string
 }
 
 func (s *sl) RunAutopilot(in *InRunAutopilot) (*OutRunAutopilot, error) {
+	if in.DryRun {
+		return s.dryRunAutopilot(in)
+	}
 	r := NewRotation()
-	out := &OutRunAutopilot{}
+	run := &AutopilotRun{
+		Time:                   in.Time,
+		Trigger:                in.Trigger,
+		ActingMattermostUserID: s.actingMattermostUserID,
+	}
+	if run.Trigger == "" {
+		run.Trigger = AutopilotTriggerManual
+	}
 
-	autopilotOp := func(op func(*Rotation, types.Time) (md.Markdowner, error)) func(*sl) error {
+	autopilotOp := func(name string, op func(*Rotation, types.Time) (md.Markdowner, error)) func(*sl) error {
 		return func(*sl) error {
+			stage := &AutopilotStage{
+				Name: name,
+			}
+			run.Stages = append(run.Stages, stage)
 			msg, err := op(r, in.Time)
 			if err != nil {
+				stage.Error = err.Error()
 				return err
 			}
-			out.messages = append(out.messages, msg.Markdown())
+			stage.Result = msg.Markdown().String()
+			_, stage.idle = msg.(autopilotIdle)
 			return nil
 		}
 	}
@@ -38,26 +74,206 @@ func (s *sl) RunAutopilot(in *InRunAutopilot) (*OutRunAutopilot, error) {
 	err := s.Setup(
 		pushAPILogger("RunAutopilot", in),
 		withExpandedRotation(&in.RotationID, r),
-		autopilotOp(s.autopilotRemindFinish),
-		autopilotOp(s.autopilotFinish),
-		autopilotOp(s.autopilotCreate),
-		autopilotOp(s.autopilotFillSchedule),
-		autopilotOp(s.autopilotRemindStart),
-		autopilotOp(s.autopilotStart),
+		autopilotOp("finish reminder", s.autopilotRemindFinish),
+		autopilotOp("finish", s.autopilotFinish),
+		autopilotOp("create shift", s.autopilotCreate),
+		autopilotOp("fill and schedule", s.autopilotFillSchedule),
+		autopilotOp("start reminder", s.autopilotRemindStart),
+		autopilotOp("start", s.autopilotStart),
+		autopilotOp("expiry reminder", s.autopilotRemindExpiry),
 	)
 	if err != nil {
+		// Record the failed runs, unless the rotation itself failed to load.
+		if r.RotationID != "" {
+			run.Error = err.Error()
+			if recordErr := s.recordAutopilotRun(r, run); recordErr != nil {
+				s.Errorf("failed to record the autopilot run on %s: %v", r.RotationID, recordErr)
+			}
+		}
 		return nil, err
 	}
 	defer s.popLogger()
 
-	out.Rotation = r
+	err = s.recordAutopilotRun(r, run)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutRunAutopilot{
+		Rotation: r,
+		Run:      run,
+	}
 	out.MD = md.Markdownf("%s ran autopilot on %s for %v.", s.actingUser.Markdown(), r.Markdown(), in.Time)
-	for _, msg := range out.messages {
-		if msg != "" {
-			out.MD += "\n  - " + msg
+	for _, stage := range run.Stages {
+		if stage.Result != "" {
+			out.MD += md.MD("\n  - " + stage.Result)
 		}
 	}
 
 	s.logAPI(out)
 	return out, nil
 }
+
+type InAutopilotHistory struct {
+	RotationID types.ID
+
+	// Limit is the number of the most recent runs to display, all if 0.
+	Limit int
+}
+
+type OutAutopilotHistory struct {
+	md.MD
+	History *AutopilotHistory
+}
+
+func (s *sl) LoadAutopilotHistory(in InAutopilotHistory) (*OutAutopilotHistory, error) {
+	r := NewRotation()
+	err := s.Setup(
+		pushAPILogger("LoadAutopilotHistory", in),
+		withExpandedRotation(&in.RotationID, r),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer s.popLogger()
+
+	h, err := s.loadAutopilotHistory(r.RotationID)
+	if err != nil {
+		return nil, err
+	}
+	ids := types.NewIDSet()
+	for _, run := range h.Runs {
+		ids.Set(run.ActingMattermostUserID)
+	}
+	users, err := s.LoadUsers(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutAutopilotHistory{
+		History: h,
+	}
+	if len(h.Runs) == 0 {
+		out.MD = md.Markdownf("Autopilot has not run on %s.", r.Markdown())
+	} else {
+		out.MD = md.Markdownf("Autopilot history of %s, most recent first:\n", r.Markdown()) + h.MarkdownBullets(users, in.Limit)
+	}
+	return out, nil
+}
+
+// dryRunAutopilot runs the autopilot with a copy of the service that keeps the
+// changes in memory, and discards them when done.
+func (s *sl) dryRunAutopilot(in *InRunAutopilot) (*OutRunAutopilot, error) {
+	poster := &dryRunPoster{}
+	dry := *s.Service
+	dry.Poster = poster
+	dry.Webhooks = nil
+	dry.Store = kvstore.NewStore(kvstore.NewCacheKVStore(s.Store))
+	dsl := dry.ActingAs(s.actingMattermostUserID)
+
+	out, err := dsl.RunAutopilot(&InRunAutopilot{
+		RotationID: in.RotationID,
+		Time:       in.Time,
+		Trigger:    in.Trigger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.MD = "Dry run: " + out.MD
+	if len(poster.messages) == 0 {
+		return out, nil
+	}
+
+	ids := types.NewIDSet()
+	for _, m := range poster.messages {
+		ids.Set(m.MattermostUserID)
+	}
+	users, err := dsl.LoadUsers(ids)
+	if err != nil {
+		return nil, err
+	}
+	out.DryRunMessages = poster.messages
+	out.MD += md.Markdownf("\n  - would send %v messages:", len(poster.messages))
+	for _, m := range poster.messages {
+		// The first line is enough to tell the messages apart.
+		summary := strings.TrimLeft(strings.SplitN(m.Message, "\n", 2)[0], "# ")
+		out.MD += md.Markdownf("\n    - to %s: %s", users.Get(m.MattermostUserID).Markdown(), summary)
+	}
+	return out, nil
+}
+
+// dryRunPoster collects the direct messages instead of posting them.
+type dryRunPoster struct {
+	messages []*AutopilotMessage
+}
+
+func (p *dryRunPoster) DM(userID, format string, args ...interface{}) error {
+	p.messages = append(p.messages, &AutopilotMessage{
+		MattermostUserID: types.ID(userID),
+		Message:          fmt.Sprintf(format, args...),
+	})
+	return nil
+}
+
+func (p *dryRunPoster) DMWithAttachments(userID string, attachments ...*model.SlackAttachment) error {
+	texts := []string{}
+	for _, a := range attachments {
+		texts = append(texts, a.Text)
+	}
+	p.messages = append(p.messages, &AutopilotMessage{
+		MattermostUserID: types.ID(userID),
+		Message:          strings.Join(texts, "\n"),
+	})
+	return nil
+}
+
+func (p *dryRunPoster) Ephemeral(userID, channelID, format string, args ...interface{}) {}
+
+type InRunAutopilotAll struct {
+	Time types.Time
+
+	// Trigger is recorded in the autopilot history, manual by default.
+	Trigger types.ID `json:",omitempty"`
+}
+
+type OutRunAutopilotAll struct {
+	md.MD
+	Rotations *types.IDSet
+	Errors    map[types.ID]string
+}
+
+// RunAutopilotAll runs autopilot on all active rotations. A failure on one
+// rotation is logged and reported in the output, and does not prevent the
+// others from running.
+func (s *sl) RunAutopilotAll(in *InRunAutopilotAll) (*OutRunAutopilotAll, error) {
+	active, err := s.LoadActiveRotations()
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutRunAutopilotAll{
+		Rotations: types.NewIDSet(),
+		Errors:    map[types.ID]string{},
+	}
+	for _, rotationID := range active.IDs() {
+		// Use a fresh sl for each rotation, so that the loggers and the acting
+		// user state do not leak between the runs.
+		rsl := s.Service.ActingAs(s.actingMattermostUserID)
+		routput, err := rsl.RunAutopilot(&InRunAutopilot{
+			RotationID: rotationID,
+			Time:       in.Time,
+			Trigger:    in.Trigger,
+		})
+		if err != nil {
+			s.Errorf("failed to run autopilot on %s: %v", rotationID, err)
+			out.Errors[rotationID] = err.Error()
+			out.MD += md.Markdownf("- %s: **error**: %v\n", rotationID, err)
+			continue
+		}
+		out.Rotations.Set(rotationID)
+		out.MD += "- " + routput.MD + "\n"
+	}
+
+	out.MD = md.Markdownf("Ran autopilot on %v rotations for %v, %v failed.\n", active.Len(), in.Time, len(out.Errors)) + out.MD
+	return out, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"net/url"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/constants"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/ics"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InCalendarToken struct {
+	// Reset replaces the token, the subscriptions with the old one stop
+	// working.
+	Reset bool `json:",omitempty"`
+}
+
+type OutCalendarToken struct {
+	md.MD
+	UserFeedURL      string
+	RotationFeedURLs map[types.ID]string
+}
+
+// CalendarToken returns the URLs of the acting user's calendar feeds, for
+// their own calendar and for the active rotations. The secret token in the
+// URLs is created on first use.
+func (sl *sl) CalendarToken(params InCalendarToken) (*OutCalendarToken, error) {
+	active := types.NewIDSet()
+	err := sl.Setup(
+		pushAPILogger("CalendarToken", params),
+		withLoadIDIndex(KeyActiveRotations, active),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	token, err := sl.loadCalendarToken(sl.actingUser.MattermostUserID)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || params.Reset {
+		token = newCalendarToken(sl.actingUser.MattermostUserID)
+		err = sl.storeCalendarToken(token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := &OutCalendarToken{
+		UserFeedURL:      sl.calendarFeedURL(token, "user.ics"),
+		RotationFeedURLs: map[types.ID]string{},
+	}
+	out.MD = md.Markdownf("Subscribe to the calendar feeds in your calendar app, keep the URLs private:\n")
+	out.MD += md.Markdownf("- your tasks, and unavailability: %s\n", out.UserFeedURL)
+	for _, rotationID := range active.IDs() {
+		u := sl.calendarFeedURL(token, "rotation", url.PathEscape(string(rotationID))+".ics")
+		out.RotationFeedURLs[rotationID] = u
+		out.MD += md.Markdownf("- rotation %s: %s\n", rotationID, u)
+	}
+
+	// The output has the secret token, do not log it.
+	sl.logAPI(md.Markdownf("calendar feeds, reset: %v", params.Reset))
+	return out, nil
+}
+
+type InExportCalendar struct {
+	Token string `json:"-"`
+
+	// RotationID is the rotation to export; if empty the acting user's
+	// calendar is exported.
+	RotationID types.ID `json:",omitempty"`
+}
+
+type OutExportCalendar struct {
+	md.MD
+	Name   string
+	Events []*ics.Event
+}
+
+// ExportCalendar returns the acting user's calendar, or a rotation's tasks,
+// as calendar events, if the token is the acting user's.
+func (sl *sl) ExportCalendar(params InExportCalendar) (*OutExportCalendar, error) {
+	err := sl.verifyCalendarToken(sl.actingMattermostUserID, params.Token)
+	if err != nil {
+		return nil, err
+	}
+	err = sl.Setup(pushAPILogger("ExportCalendar", params))
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	out := &OutExportCalendar{}
+	if params.RotationID == "" {
+		out.Name = "On call: " + sl.actingUser.String()
+		out.Events = userCalendarEvents(sl.actingUser)
+	} else {
+		r := &Rotation{}
+		err = sl.Setup(withExpandedRotation(&params.RotationID, r))
+		if err != nil {
+			return nil, err
+		}
+		out.Name = "Rotation " + r.String()
+		out.Events = rotationCalendarEvents(r)
+	}
+	out.MD = md.Markdownf("exported %v events from %s.", len(out.Events), out.Name)
+	sl.logAPI(out)
+	return out, nil
+}
+
+func (sl *sl) calendarFeedURL(token *CalendarToken, elem ...string) string {
+	u := sl.conf.PluginURL + constants.PathAPI + constants.PathCalendar +
+		"/" + url.PathEscape(string(token.MattermostUserID)) +
+		"/" + token.Token
+	for _, e := range elem {
+		u += "/" + e
+	}
+	return u
+}
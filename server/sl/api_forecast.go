@@ -0,0 +1,296 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// MaxForecastShifts limits the forecast to about a year of daily shifts.
+const MaxForecastShifts = 366
+
+type InForecast struct {
+	RotationID types.ID
+	Shifts     int
+	Time       types.Time
+
+	// Commit creates the forecast shifts as pending tasks, with the forecast
+	// users assigned.
+	Commit bool
+}
+
+type ForecastShift struct {
+	Number int
+	Task   *Task
+
+	// Existing is set if the shift had been created before the forecast. Only
+	// the pending existing shifts are filled.
+	Existing bool
+
+	// Added are the users that the forecast assigned to the shift.
+	Added *types.IDSet
+
+	Error string `json:",omitempty"`
+}
+
+type OutForecast struct {
+	md.MD
+	Shifts    []*ForecastShift
+	Committed *types.IDSet `json:",omitempty"`
+
+	// Skipped is the number of shifts skipped on holidays, as the autopilot
+	// would not create them.
+	Skipped int `json:",omitempty"`
+}
+
+// Forecast fills the next in.Shifts shifts of a rotation as a sequence, each
+// fill taking into account the simulated results of the previous ones. Unless
+// in.Commit is set, nothing is stored.
+func (sl *sl) Forecast(in InForecast) (*OutForecast, error) {
+	r := NewRotation()
+	err := sl.Setup(
+		pushAPILogger("Forecast", in),
+		withExpandedRotation(&in.RotationID, r),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	if r.TaskType != TaskTypeShift {
+		return nil, errors.Errorf("can not forecast %s, only shift rotations are supported", r.Markdown())
+	}
+	if in.Shifts <= 0 || in.Shifts > MaxForecastShifts {
+		return nil, errors.Errorf("number of shifts to forecast must be between 1 and %v, got %v", MaxForecastShifts, in.Shifts)
+	}
+	filler, err := sl.taskFiller(r)
+	if err != nil {
+		return nil, err
+	}
+
+	simr := newForecastRotation(r)
+	period := r.FillSettings.Period
+	num, _ := period.ForTime(r.FillSettings.Beginning, in.Time)
+	if num < 0 {
+		num = 0
+	}
+	out := &OutForecast{}
+	for ; len(out.Shifts) < in.Shifts; num++ {
+		start := period.ForNumber(r.FillSettings.Beginning, num)
+		existing := simr.queryTasks(simr.allTasksForTime, start)
+		if existing.IsEmpty() {
+			if h := simr.skippedHoliday(num); h != nil {
+				sl.Debugf("skipped shift #%v on holiday %s", num, h.Markdown())
+				out.Skipped++
+				continue
+			}
+		}
+
+		shift := &ForecastShift{
+			Number: num,
+			Added:  types.NewIDSet(),
+		}
+		out.Shifts = append(out.Shifts, shift)
+
+		if !existing.IsEmpty() {
+			shift.Existing = true
+			shift.Task = existing.AsArray()[0]
+			if shift.Task.State != TaskStatePending {
+				continue
+			}
+		} else {
+			shift.Task, err = simr.makeShift(num, in.Time)
+			if err != nil {
+				return nil, err
+			}
+			simr.Tasks.Set(shift.Task)
+		}
+
+		// The fill state, e.g. the queue order, is only kept for the shifts
+		// that can be committed, the ones that failed or added no one leave
+		// it as it was.
+		var queue *types.IDSet
+		if simr.FillSettings.Queue != nil {
+			queue = types.NewIDSet(simr.FillSettings.Queue.IDs()...)
+		}
+
+		// Filler logs are too verbose for a sequence of fills, suppress.
+		added, _, err := filler.FillTask(simr, shift.Task, in.Time, &bot.NilLogger{})
+		if err == nil {
+			added, err = sl.assignTask(simr, shift.Task, added, true, in.Time)
+		}
+		if err != nil {
+			shift.Error = err.Error()
+		}
+		if err != nil || added.IsEmpty() {
+			simr.FillSettings.Queue = queue
+			continue
+		}
+		shift.Added = types.NewIDSet(added.IDs()...)
+	}
+
+	if in.Commit {
+		out.Committed, err = sl.commitForecast(r, simr, out.Shifts, in.Time)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out.MD = md.Markdownf("Forecast for %s, %v shifts", r.Markdown(), len(out.Shifts))
+	if out.Skipped > 0 {
+		out.MD += md.Markdownf(", skipped %v on holidays", out.Skipped)
+	}
+	out.MD += ":\n"
+	for _, shift := range out.Shifts {
+		out.MD += shift.Markdown()
+	}
+	if in.Commit {
+		out.MD += md.Markdownf("Committed %v shifts as pending tasks.\n", out.Committed.Len())
+	}
+
+	sl.logAPI(out)
+	return out, nil
+}
+
+// commitForecast creates the forecast shifts, and assigns the forecast users.
+// All the shifts are checked first, on a copy of the rotation, so that a
+// shift that can not be committed fails the commit before anything is
+// stored.
+func (sl *sl) commitForecast(r, simr *Rotation, shifts []*ForecastShift, now types.Time) (*types.IDSet, error) {
+	toCommit := []*ForecastShift{}
+	for _, shift := range shifts {
+		if shift.Error == "" && !shift.Added.IsEmpty() {
+			toCommit = append(toCommit, shift)
+		}
+	}
+
+	check := newForecastRotation(r)
+	for _, shift := range toCommit {
+		var task *Task
+		var err error
+		if shift.Existing {
+			task = check.Tasks.Get(shift.Task.TaskID)
+		} else {
+			task, err = check.makeShift(shift.Number, now)
+			if err != nil {
+				return nil, err
+			}
+			check.Tasks.Set(task)
+		}
+		users := NewUsers()
+		for _, id := range shift.Added.IDs() {
+			users.Set(check.Users.Get(id))
+		}
+		_, err = sl.assignTask(check, task, users, false, now)
+		if err != nil {
+			return nil, errors.WithMessage(err, "nothing committed")
+		}
+	}
+
+	committed := types.NewIDSet()
+	failed := func(err error) error {
+		if committed.IsEmpty() {
+			return err
+		}
+		return errors.WithMessagef(err, "committed %v before the failure", committed.IDs())
+	}
+	for _, shift := range toCommit {
+		var task *Task
+		var err error
+		if shift.Existing {
+			task = r.Tasks.Get(shift.Task.TaskID)
+		} else {
+			task, err = sl.createShift(r, shift.Number, now)
+			if err != nil {
+				return nil, failed(err)
+			}
+		}
+
+		users := NewUsers()
+		for _, id := range shift.Added.IDs() {
+			users.Set(r.Users.Get(id))
+		}
+		assigned, err := sl.assignTask(r, task, users, false, now)
+		if err != nil {
+			return nil, failed(err)
+		}
+		err = sl.storeTask(task)
+		if err != nil {
+			return nil, failed(err)
+		}
+		err = sl.storeUsers(assigned)
+		if err != nil {
+			return nil, failed(err)
+		}
+		sl.fireWebhooks(r.RotationID, WebhookEventTaskAssigned, now, task, assigned)
+		committed.Set(task.TaskID)
+	}
+
+	// Fillers may have updated the rotation's fill state in the simulation,
+	// e.g. the queue order. The simulation kept it only for the shifts that
+	// are committed.
+	if simr.FillSettings.Queue != nil {
+		r.FillSettings.Queue = simr.FillSettings.Queue
+	}
+	err := sl.Store.Entity(KeyRotation).Store(r.RotationID, r)
+	if err != nil {
+		return nil, err
+	}
+	return committed, nil
+}
+
+// newForecastRotation makes a copy of an expanded rotation for simulating
+// fills. Users and pending tasks are cloned, so that assigning users in the
+// simulation does not modify the originals.
+func newForecastRotation(r *Rotation) *Rotation {
+	simr := *r
+	simr.Users = NewUsers()
+	for _, user := range r.Users.AsArray() {
+		simr.Users.Set(user.Clone())
+	}
+	if r.FillSettings.Queue != nil {
+		simr.FillSettings.Queue = types.NewIDSet(r.FillSettings.Queue.IDs()...)
+	}
+
+	simr.Tasks = NewTasks()
+	for _, t := range r.Tasks.AsArray() {
+		if t.State != TaskStatePending {
+			simr.Tasks.Set(t)
+			continue
+		}
+		simt := *t
+		simt.Require = t.Require.Clone()
+		simt.Limit = t.Limit.Clone()
+		simt.MattermostUserIDs = types.NewIDSet(t.MattermostUserIDs.IDs()...)
+		simt.Users = NewUsers()
+		for _, id := range t.MattermostUserIDs.IDs() {
+			if simr.Users.Contains(id) {
+				simt.Users.Set(simr.Users.Get(id))
+			} else if t.Users != nil && t.Users.Contains(id) {
+				simt.Users.Set(t.Users.Get(id).Clone())
+			}
+		}
+		simr.Tasks.Set(&simt)
+	}
+	return &simr
+}
+
+func (shift *ForecastShift) Markdown() md.MD {
+	t := shift.Task
+	out := md.Markdownf("- %s (%v to %v)", t.Markdown(), t.ExpectedStart, types.NewTime(t.ExpectedStart.Add(t.ExpectedDuration)))
+	switch {
+	case shift.Error != "":
+		return out + md.Markdownf(": **failed**: %s\n", shift.Error)
+	case shift.Existing && t.State != TaskStatePending:
+		out += md.Markdownf(" **%s**", t.State)
+	}
+	if t.Users.IsEmpty() {
+		return out + ": no users\n"
+	}
+	return out + md.Markdownf(": %s\n", t.Users.Markdown())
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InImportHolidays struct {
+	CalendarID types.ID
+	Holidays   []*Holiday
+
+	// Replace removes the calendar's holidays that are not in Holidays.
+	Replace bool `json:",omitempty"`
+}
+
+type OutHolidays struct {
+	md.MD
+	Calendar *HolidayCalendar
+}
+
+// ImportHolidays adds the holidays to the calendar, creating it if needed.
+// The holidays that are already in the calendar are updated.
+func (sl *sl) ImportHolidays(params InImportHolidays) (*OutHolidays, error) {
+	err := sl.Setup(pushAPILogger("ImportHolidays", params))
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	if params.CalendarID == "" {
+		return nil, errors.New("holiday calendar name is required")
+	}
+	for _, h := range params.Holidays {
+		if h.IsEmpty() {
+			return nil, errors.Errorf("holiday %s has no duration", h.Markdown())
+		}
+	}
+
+	c, err := sl.loadHolidayCalendar(params.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+	if params.Replace {
+		c.Holidays = []*Holiday{}
+	}
+	added, updated := c.Merge(params.Holidays)
+	err = sl.storeHolidayCalendar(c)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutHolidays{
+		MD:       md.Markdownf("holiday calendar %s: added %v, updated %v holidays.", c.Markdown(), added, updated),
+		Calendar: c,
+	}
+	sl.logAPI(out)
+	return out, nil
+}
+
+type InRemoveHolidays struct {
+	CalendarID types.ID
+
+	// Interval to remove the holidays in; if empty, the calendar is deleted.
+	Interval types.Interval
+}
+
+// RemoveHolidays removes the calendar's holidays in the interval, or the
+// whole calendar. The rotations and users that use a deleted calendar no
+// longer have holidays.
+func (sl *sl) RemoveHolidays(params InRemoveHolidays) (*OutHolidays, error) {
+	err := sl.Setup(
+		pushAPILogger("RemoveHolidays", params),
+		withValidHolidayCalendar(&params.CalendarID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	c, err := sl.loadHolidayCalendar(params.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutHolidays{
+		Calendar: c,
+	}
+	if params.Interval.IsEmpty() {
+		err = sl.Store.Entity(KeyHolidayCalendar).Delete(c.CalendarID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to delete holiday calendar %s", c.CalendarID)
+		}
+		err = sl.Store.IDIndex(KeyHolidayCalendars).Delete(c.CalendarID)
+		if err != nil {
+			return nil, err
+		}
+		delete(sl.holidayCalendars, c.CalendarID)
+		out.MD = md.Markdownf("deleted holiday calendar %s.", c.Markdown())
+	} else {
+		removed := c.Remove(params.Interval)
+		err = sl.storeHolidayCalendar(c)
+		if err != nil {
+			return nil, err
+		}
+		out.MD = md.Markdownf("holiday calendar %s: removed %v holidays.", c.Markdown(), len(removed))
+	}
+	sl.logAPI(out)
+	return out, nil
+}
+
+func (sl *sl) ListHolidayCalendars() (*types.IDSet, error) {
+	calendars := types.NewIDSet()
+	err := sl.Setup(withLoadIDIndex(KeyHolidayCalendars, calendars))
+	if err != nil {
+		return nil, err
+	}
+	return calendars, nil
+}
+
+func (sl *sl) LoadHolidayCalendar(calendarID types.ID) (*HolidayCalendar, error) {
+	err := sl.Setup(withValidHolidayCalendar(&calendarID))
+	if err != nil {
+		return nil, err
+	}
+	return sl.loadHolidayCalendar(calendarID)
+}
+
+type InSetUserHolidays struct {
+	MattermostUserIDs *types.IDSet
+
+	// CalendarID is the users' regional holiday calendar, empty to clear.
+	CalendarID types.ID
+}
+
+type OutSetUserHolidays struct {
+	md.MD
+	Users *Users
+}
+
+// SetUserHolidays sets the users' regional holiday calendar. The users are
+// not picked to fill the tasks that overlap their holidays.
+func (sl *sl) SetUserHolidays(params InSetUserHolidays) (*OutSetUserHolidays, error) {
+	users := NewUsers()
+	err := sl.Setup(
+		pushAPILogger("SetUserHolidays", params),
+		withExpandedUsers(&params.MattermostUserIDs, users),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+	if params.CalendarID != "" {
+		err = sl.Setup(withValidHolidayCalendar(&params.CalendarID))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, user := range users.AsArray() {
+		user.HolidayCalendarID = params.CalendarID
+	}
+	err = sl.storeUsers(users)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutSetUserHolidays{
+		Users: users,
+	}
+	if params.CalendarID == "" {
+		out.MD = md.Markdownf("cleared holiday calendar for %s.", users.Markdown())
+	} else {
+		out.MD = md.Markdownf("set holiday calendar %s for %s.", params.CalendarID, users.Markdown())
+	}
+	sl.logAPI(out)
+	return out, nil
+}
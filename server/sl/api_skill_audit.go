@@ -0,0 +1,92 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InAuditSkills struct {
+	// RotationID to audit, all active rotations if empty.
+	RotationID types.ID
+	Time       types.Time
+
+	// Within is how far ahead of Time to look for expiring qualifications.
+	Within time.Duration
+}
+
+type OutAuditSkills struct {
+	md.MD
+	Expiring map[types.ID][]*ExpiringSkill
+}
+
+// AuditSkills lists the qualifications of the rotations' users that expire
+// within the period, or have already expired.
+func (sl *sl) AuditSkills(in InAuditSkills) (*OutAuditSkills, error) {
+	err := sl.Setup(pushAPILogger("AuditSkills", in))
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	rotationIDs := types.NewIDSet(in.RotationID)
+	if in.RotationID == "" {
+		rotationIDs, err = sl.LoadActiveRotations()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	before := in.Time.Add(in.Within)
+	out := &OutAuditSkills{
+		MD:       md.Markdownf("Qualifications expiring before %s:\n", sl.actingUser.Time(types.NewTime(before))),
+		Expiring: map[types.ID][]*ExpiringSkill{},
+	}
+	for _, rotationID := range rotationIDs.IDs() {
+		r := NewRotation()
+		err = sl.Setup(withExpandedRotation(&rotationID, r))
+		if err != nil {
+			return nil, err
+		}
+
+		expiring := FindExpiringSkills(r.Users, before)
+		out.Expiring[rotationID] = expiring
+		out.MD += md.Markdownf("- %s:", r.Markdown())
+		if len(expiring) == 0 {
+			out.MD += " none\n"
+			continue
+		}
+		out.MD += "\n"
+
+		users, err := sl.loadGrantors(expiring, r.Users)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range expiring {
+			out.MD += md.Markdownf("  - %s\n", e.Markdown(users, in.Time.Time))
+		}
+	}
+
+	sl.logAPI(out)
+	return out, nil
+}
+
+// loadGrantors adds the users who granted the expiring qualifications to
+// users, for display.
+func (sl *sl) loadGrantors(expiring []*ExpiringSkill, users *Users) (*Users, error) {
+	grantors := types.NewIDSet()
+	for _, e := range expiring {
+		if e.Grant.GrantedBy != "" && !users.Contains(e.Grant.GrantedBy) {
+			grantors.Set(e.Grant.GrantedBy)
+		}
+	}
+	loaded, err := sl.LoadUsers(grantors)
+	if err != nil {
+		return nil, err
+	}
+	return loaded.Join(users), nil
+}
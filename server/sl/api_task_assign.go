@@ -13,12 +13,20 @@ type InAssignTask struct {
 	MattermostUserIDs *types.IDSet
 	Force             bool
 	Time              types.Time
+
+	// Shadow assigns the users as the task's shadows (trainees), that do not
+	// count towards its requirements and limits.
+	Shadow bool
 }
 
 type OutAssignTask struct {
 	md.MD
 	Task    *Task
 	Changed *Users
+
+	// Replacement are the users proposed to replace the ones unassigned from
+	// a scheduled or started task.
+	Replacement *Users `json:",omitempty"`
 }
 
 func (sl *sl) AssignTask(params InAssignTask) (*OutAssignTask, error) {
@@ -36,7 +44,17 @@ func (sl *sl) AssignTask(params InAssignTask) (*OutAssignTask, error) {
 	}
 	defer sl.popLogger()
 
-	assigned, err := sl.assignTask(r, task, users, params.Force)
+	var assigned *Users
+	verb := "assigned"
+	if params.Shadow {
+		verb = "assigned shadows"
+		assigned, err = sl.assignShadows(r, task, users)
+		if err == nil {
+			err = sl.storeUsers(assigned)
+		}
+	} else {
+		assigned, err = sl.assignTask(r, task, users, params.Force, params.Time)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -45,9 +63,10 @@ func (sl *sl) AssignTask(params InAssignTask) (*OutAssignTask, error) {
 	if err != nil {
 		return nil, err
 	}
+	sl.fireWebhooks(r.RotationID, WebhookEventTaskAssigned, params.Time, task, assigned)
 
 	out := &OutAssignTask{
-		MD:      md.Markdownf("assigned %s to ticket %s", assigned.Markdown(), task.Markdown()),
+		MD:      md.Markdownf("%s %s to ticket %s", verb, assigned.Markdown(), task.Markdown()) + markdownConflictWarnings(r, task, assigned),
 		Task:    task,
 		Changed: assigned,
 	}
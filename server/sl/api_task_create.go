@@ -58,6 +58,7 @@ func (sl *sl) CreateTicket(params InCreateTicket) (*OutCreateTask, error) {
 	if err != nil {
 		return nil, err
 	}
+	sl.fireWebhooks(task.RotationID, WebhookEventTaskCreated, params.Time, task, nil)
 
 	out := &OutCreateTask{
 		MD:   md.Markdownf("created ticket %s.", task.Markdown()),
@@ -79,6 +80,7 @@ func (sl *sl) CreateShift(in InCreateShift) (*OutCreateTask, error) {
 		pushAPILogger("MakeShift", in),
 		withLoadRotation(&in.RotationID, r),
 		withExpandRotationTasks(r),
+		withExpandRotationHolidays(r),
 	)
 	if err != nil {
 		return nil, err
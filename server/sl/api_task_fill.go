@@ -5,9 +5,30 @@ package sl
 
 import (
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
-func (sl *sl) FillTask(params InAssignTask) (*OutAssignTask, error) {
+type InFillTask struct {
+	TaskID types.ID
+	Time   types.Time
+
+	// DryRun fills the task without saving the results. A failed dry run is
+	// reported in the output rather than as an error.
+	DryRun bool
+
+	// Explain adds the filler's explanation of its choices to the output.
+	Explain bool
+}
+
+type OutFillTask struct {
+	md.MD
+	Task        *Task
+	Changed     *Users
+	Explanation *FillExplanation `json:",omitempty"`
+	Error       string           `json:",omitempty"`
+}
+
+func (sl *sl) FillTask(params InFillTask) (*OutFillTask, error) {
 	task := NewTask("")
 	r := NewRotation()
 	err := sl.Setup(
@@ -20,7 +41,32 @@ func (sl *sl) FillTask(params InAssignTask) (*OutAssignTask, error) {
 	}
 	defer sl.popLogger()
 
-	filled, err := sl.fillTask(r, task, params.Time)
+	filled, explanation, err := sl.fillTask(r, task, params.Time)
+	shadows := NewUsers()
+	if err == nil {
+		shadows, err = sl.fillShadows(r, task)
+	}
+	if params.DryRun {
+		out := &OutFillTask{
+			Task:    task,
+			Changed: filled,
+		}
+		if err != nil {
+			out.Error = err.Error()
+			out.MD = md.Markdownf("Dry run: %s", out.Error)
+		} else {
+			out.MD = md.Markdownf("Dry run: would auto-assign %s to ticket %s", filled.MarkdownWithSkills(), task.Markdown())
+			if !shadows.IsEmpty() {
+				out.MD += md.Markdownf(", shadowed by %s", shadows.Markdown())
+			}
+			out.MD += markdownConflictWarnings(r, task, filled)
+		}
+		if params.Explain && explanation != nil {
+			out.Explanation = explanation
+			out.MD += "\n" + explanation.Markdown(r.Users)
+		}
+		return out, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -33,12 +79,30 @@ func (sl *sl) FillTask(params InAssignTask) (*OutAssignTask, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = sl.storeUsers(shadows)
+	if err != nil {
+		return nil, err
+	}
+	// Fillers may update the rotation's fill state, e.g. the queue order.
+	err = sl.Store.Entity(KeyRotation).Store(r.RotationID, r)
+	if err != nil {
+		return nil, err
+	}
+	sl.fireWebhooks(r.RotationID, WebhookEventTaskFilled, params.Time, task, filled)
 
-	out := &OutAssignTask{
+	out := &OutFillTask{
 		MD:      md.Markdownf("Auto-assigned %s to ticket %s", filled.MarkdownWithSkills(), task.Markdown()),
 		Task:    task,
 		Changed: filled,
 	}
+	if !shadows.IsEmpty() {
+		out.MD += md.Markdownf(", shadowed by %s", shadows.Markdown())
+	}
+	out.MD += markdownConflictWarnings(r, task, filled)
+	if params.Explain {
+		out.Explanation = explanation
+		out.MD += "\n" + explanation.Markdown(r.Users)
+	}
 	sl.logAPI(out)
 	return out, nil
 }
@@ -0,0 +1,233 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InRequestSwap struct {
+	TaskID types.ID
+
+	// MattermostUserID is the user to give the task to.
+	MattermostUserID types.ID
+
+	// TradeTaskID is the user's task to take in exchange, if any.
+	TradeTaskID types.ID `json:",omitempty"`
+	Time        types.Time
+}
+
+type OutSwap struct {
+	md.MD
+	Swap *Swap
+}
+
+// RequestSwap proposes to give the acting user's task to another user, or to
+// trade it for one of theirs. The other user is asked to accept, or decline.
+func (sl *sl) RequestSwap(params InRequestSwap) (*OutSwap, error) {
+	swap := &Swap{
+		TaskID:             params.TaskID,
+		ToMattermostUserID: params.MattermostUserID,
+		TradeTaskID:        params.TradeTaskID,
+		State:              SwapStatePending,
+		Created:            params.Time,
+	}
+	err := sl.Setup(pushAPILogger("RequestSwap", params))
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+	swap.FromMattermostUserID = sl.actingUser.MattermostUserID
+
+	// Fail early if the swap could not be applied as it is now.
+	_, _, users, err := sl.prepareSwap(swap, params.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	swap.SwapID, err = sl.Store.Entity(KeySwap).NewID("swap-" + string(swap.TaskID))
+	if err != nil {
+		return nil, err
+	}
+	err = sl.storeSwap(swap)
+	if err != nil {
+		return nil, err
+	}
+
+	to := users.Get(swap.ToMattermostUserID)
+	sl.dmUserSwapRequested(to, swap)
+
+	out := &OutSwap{
+		MD:   md.Markdownf("proposed %s, waiting for %s to accept.", swap.Markdown(users), to.Markdown()),
+		Swap: swap,
+	}
+	sl.logAPI(out)
+	return out, nil
+}
+
+type InRespondSwap struct {
+	SwapID types.ID
+	Accept bool
+	Time   types.Time
+}
+
+// RespondSwap accepts, or declines the swap proposed to the acting user. On
+// accept, both sides of the swap are validated again before anything is
+// stored. The users' calendars are stored first, then the tasks, and the
+// accepted swap last; if any of them fails, the changes already stored are
+// rolled back, and the swap stays pending to be accepted again.
+func (sl *sl) RespondSwap(params InRespondSwap) (*OutSwap, error) {
+	err := sl.Setup(pushAPILogger("RespondSwap", params))
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	swap, err := sl.loadSwap(params.SwapID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := sl.LoadUsers(types.NewIDSet(swap.FromMattermostUserID, swap.ToMattermostUserID))
+	if err != nil {
+		return nil, err
+	}
+	if swap.ToMattermostUserID != sl.actingUser.MattermostUserID {
+		return nil, errors.Errorf("%s is not for %s", swap.Markdown(users), sl.actingUser.Markdown())
+	}
+	if swap.State != SwapStatePending {
+		return nil, errors.Errorf("%s is already %s", swap.Markdown(users), swap.State)
+	}
+
+	if !params.Accept {
+		swap.State = SwapStateDeclined
+		err = sl.storeSwap(swap)
+		if err != nil {
+			return nil, err
+		}
+		sl.dmUserSwapResponded(users.Get(swap.FromMattermostUserID), users, swap)
+		out := &OutSwap{
+			MD:   md.Markdownf("declined %s.", swap.Markdown(users)),
+			Swap: swap,
+		}
+		sl.logAPI(out)
+		return out, nil
+	}
+
+	tasks, rotations, users, err := sl.prepareSwap(swap, params.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the users, and the tasks' assignments as they were, to roll back
+	// to.
+	usersBefore := NewUsers()
+	for _, user := range users.AsArray() {
+		usersBefore.Set(user.Clone())
+	}
+	assignedBefore := []*types.IDSet{}
+	for _, task := range tasks {
+		assignedBefore = append(assignedBefore, types.NewIDSet(task.MattermostUserIDs.IDs()...))
+	}
+
+	from, to := users.Get(swap.FromMattermostUserID), users.Get(swap.ToMattermostUserID)
+	sl.handOver(rotations[0], tasks[0], from, to)
+	if len(tasks) > 1 {
+		sl.handOver(rotations[1], tasks[1], to, from)
+	}
+
+	err = sl.storeSwapped(users, tasks)
+	if err == nil {
+		swap.State = SwapStateAccepted
+		err = sl.storeSwap(swap)
+	}
+	if err != nil {
+		swap.State = SwapStatePending
+		for i, task := range tasks {
+			task.MattermostUserIDs = assignedBefore[i]
+		}
+		rollbackErr := sl.storeSwapped(usersBefore, tasks)
+		if rollbackErr != nil {
+			sl.Errorf("failed to roll back %s: %v", swap.Markdown(users), rollbackErr)
+		}
+		return nil, err
+	}
+
+	sl.dmUserSwapResponded(from, users, swap)
+	sl.fireWebhooks(tasks[0].RotationID, WebhookEventTaskAssigned, params.Time, tasks[0], NewUsers(to))
+	if len(tasks) > 1 {
+		sl.fireWebhooks(tasks[1].RotationID, WebhookEventTaskAssigned, params.Time, tasks[1], NewUsers(from))
+	}
+
+	out := &OutSwap{
+		MD:   md.Markdownf("accepted %s.", swap.Markdown(users)),
+		Swap: swap,
+	}
+	sl.logAPI(out)
+	return out, nil
+}
+
+// storeSwapped stores the users, then the tasks of a swap.
+func (sl *sl) storeSwapped(users *Users, tasks []*Task) error {
+	err := sl.storeUsers(users)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		err = sl.storeTask(task)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareSwap loads the swap's tasks, their rotations, and the two users, and
+// checks that the swap can be applied at the time. It does not store
+// anything.
+func (sl *sl) prepareSwap(swap *Swap, now types.Time) (tasks []*Task, rotations []*Rotation, users *Users, err error) {
+	if swap.FromMattermostUserID == swap.ToMattermostUserID {
+		return nil, nil, nil, errors.New("can not swap with self")
+	}
+	if swap.TradeTaskID == swap.TaskID {
+		return nil, nil, nil, errors.New("can not trade a task for itself")
+	}
+	users, err = sl.LoadUsers(types.NewIDSet(swap.FromMattermostUserID, swap.ToMattermostUserID))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	from, to := users.Get(swap.FromMattermostUserID), users.Get(swap.ToMattermostUserID)
+
+	taskIDs := []types.ID{swap.TaskID}
+	if swap.TradeTaskID != "" {
+		taskIDs = append(taskIDs, swap.TradeTaskID)
+	}
+	for _, taskID := range taskIDs {
+		task := NewTask("")
+		r := NewRotation()
+		err = sl.Setup(
+			withExpandedTask(&taskID, task),
+			withExpandedRotation(&task.RotationID, r),
+		)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		tasks = append(tasks, task)
+		rotations = append(rotations, r)
+	}
+
+	err = checkSwap(rotations[0], tasks[0], from, to, swap.TradeTaskID, now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if swap.TradeTaskID != "" {
+		err = checkSwap(rotations[1], tasks[1], to, from, swap.TaskID, now)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return tasks, rotations, users, nil
+}
@@ -22,7 +22,7 @@ func (sl *sl) UnassignTask(params InAssignTask) (*OutAssignTask, error) {
 	}
 	defer sl.popLogger()
 
-	removed, err := sl.unassignTask(task, users, params.Force)
+	removed, err := sl.unassignTask(r, task, users, params.Force)
 	if err != nil {
 		return nil, err
 	}
@@ -31,12 +31,39 @@ func (sl *sl) UnassignTask(params InAssignTask) (*OutAssignTask, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = sl.storeUsers(removed)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range removed.AsArray() {
+		sl.dmUserUnassignedTask(user, task)
+	}
+	sl.fireWebhooks(r.RotationID, WebhookEventTaskUnassigned, params.Time, task, removed)
 
 	out := &OutAssignTask{
-		MD:      md.Markdownf("assigned %s to ticket %s", removed.Markdown(), task.Markdown()),
+		MD:      md.Markdownf("unassigned %s from ticket %s", removed.Markdown(), task.Markdown()),
 		Task:    task,
 		Changed: removed,
 	}
+
+	// The pending tasks can simply be filled again, propose replacements for
+	// the scheduled and started ones to the lead.
+	if task.State == TaskStateScheduled || task.State == TaskStateStarted {
+		lead := sl.actingUser
+		if r.TraineeSettings.LeadMattermostUserID != "" {
+			lead = NewUser(r.TraineeSettings.LeadMattermostUserID)
+		}
+		replacement, fillErr := sl.fillReplacement(r, task, removed, params.Time)
+		switch {
+		case fillErr != nil:
+			out.MD += md.Markdownf("\nNo replacement found: %v", fillErr)
+			sl.dmLeadReplacement(lead, task, removed, nil, fillErr)
+		case !replacement.IsEmpty():
+			out.Replacement = replacement
+			out.MD += md.Markdownf("\nProposed replacement: %s", replacement.MarkdownWithSkills())
+			sl.dmLeadReplacement(lead, task, removed, replacement, nil)
+		}
+	}
 	sl.logAPI(out)
 	return out, nil
 }
@@ -0,0 +1,75 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InVolunteer struct {
+	TaskID types.ID
+
+	// Withdraw removes the acting user from the task's volunteers.
+	Withdraw bool
+}
+
+// Volunteer records the acting user as a volunteer for the pending task. The
+// rotation's volunteer policy decides how the filler treats the volunteers.
+func (sl *sl) Volunteer(params InVolunteer) (*OutAssignTask, error) {
+	task := NewTask("")
+	r := NewRotation()
+	err := sl.Setup(
+		pushAPILogger("Volunteer", params),
+		withExpandedTask(&params.TaskID, task),
+		withExpandedRotation(&task.RotationID, r),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	user := sl.actingUser
+	if task.State != TaskStatePending {
+		return nil, errors.Errorf("can not volunteer for %s in state %s", task.Markdown(), task.State)
+	}
+	if !r.MattermostUserIDs.Contains(user.MattermostUserID) {
+		return nil, errors.Errorf("%s is not a member of %s", user.Markdown(), r.Markdown())
+	}
+	if task.MattermostUserIDs.Contains(user.MattermostUserID) {
+		return nil, errors.Errorf("%s is already assigned to %s", user.Markdown(), task.Markdown())
+	}
+
+	verb := "volunteered for"
+	if params.Withdraw {
+		if !task.IsVolunteer(user.MattermostUserID) {
+			return nil, errors.Errorf("%s has not volunteered for %s", user.Markdown(), task.Markdown())
+		}
+		task.VolunteerMattermostUserIDs.Delete(user.MattermostUserID)
+		verb = "withdrew from"
+	} else {
+		if task.IsVolunteer(user.MattermostUserID) {
+			return nil, errors.Errorf("%s has already volunteered for %s", user.Markdown(), task.Markdown())
+		}
+		if task.VolunteerMattermostUserIDs == nil {
+			task.VolunteerMattermostUserIDs = types.NewIDSet()
+		}
+		task.VolunteerMattermostUserIDs.Set(user.MattermostUserID)
+	}
+
+	err = sl.storeTask(task)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutAssignTask{
+		MD:      md.Markdownf("%s %s %s.", user.Markdown(), verb, task.Markdown()),
+		Task:    task,
+		Changed: NewUsers(user),
+	}
+	sl.logAPI(out)
+	return out, nil
+}
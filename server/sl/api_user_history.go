@@ -0,0 +1,44 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InServiceHistory struct {
+	MattermostUserIDs *types.IDSet
+	RotationID        types.ID
+	Interval          types.Interval
+}
+
+type OutServiceHistory struct {
+	md.MD
+	History []*ServiceHistory
+}
+
+func (sl *sl) LoadServiceHistory(params InServiceHistory) (*OutServiceHistory, error) {
+	users := NewUsers()
+	err := sl.Setup(
+		pushAPILogger("LoadServiceHistory", params),
+		withExpandedUsers(&params.MattermostUserIDs, users),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	out := &OutServiceHistory{}
+	for _, user := range users.AsArray() {
+		h, err := sl.loadServiceHistory(user.MattermostUserID)
+		if err != nil {
+			return nil, err
+		}
+		h = h.Filter(params.RotationID, params.Interval)
+		out.History = append(out.History, h)
+		out.MD += h.MarkdownBullets(user)
+	}
+	return out, nil
+}
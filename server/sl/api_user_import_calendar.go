@@ -0,0 +1,64 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/ics"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InImportCalendar struct {
+	MattermostUserIDs *types.IDSet
+	Events            []*ics.Event
+	Time              types.Time
+
+	// Source names the calendar, so that the users can import several;
+	// DefaultCalendarSource if empty.
+	Source string `json:",omitempty"`
+}
+
+// ImportCalendar imports the busy events from the users' calendar as
+// personal unavailability. Re-importing the calendar updates the events by
+// their UIDs, and removes the previously imported upcoming events that are
+// no longer in it.
+func (sl *sl) ImportCalendar(params InImportCalendar) (*OutCalendar, error) {
+	users := NewUsers()
+	err := sl.Setup(
+		pushAPILogger("ImportCalendar", params),
+		withExpandedUsers(&params.MattermostUserIDs, users),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	source := params.Source
+	if source == "" {
+		source = DefaultCalendarSource
+	}
+	uu := unavailableFromEvents(params.Events, source, params.Time)
+	added, updated, removed := 0, 0, 0
+	for _, user := range users.AsArray() {
+		a, u, r := user.importUnavailable(uu, source, params.Time)
+		if a+u+r == 0 {
+			continue
+		}
+		err = sl.storeUserWelcomeNew(user)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to update user %s", user.Markdown())
+		}
+		added, updated, removed = added+a, updated+u, removed+r
+	}
+
+	out := &OutCalendar{
+		Users: users,
+		MD: md.Markdownf("imported calendar to %s: added %v, updated %v, removed %v unavailable events.",
+			users.Markdown(), added, updated, removed),
+	}
+	sl.logAPI(out)
+	return out, nil
+}
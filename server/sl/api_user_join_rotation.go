@@ -12,6 +12,11 @@ type InJoinRotation struct {
 	MattermostUserIDs *types.IDSet
 	RotationID        types.ID
 	Starting          types.Time
+	Time              types.Time
+
+	// Trainee adds the users to the rotation's trainee pool, rather than to
+	// the rotation.
+	Trainee bool
 }
 
 type OutJoinRotation struct {
@@ -32,17 +37,23 @@ func (sl *sl) JoinRotation(params InJoinRotation) (*OutJoinRotation, error) {
 
 	modified := NewUsers()
 	r, err := sl.UpdateRotation(params.RotationID, func(r *Rotation) error {
-		modified, err = sl.joinRotation(users, r, params.Starting)
+		modified, err = sl.joinRotation(users, r, params.Starting, params.Trainee)
 		return err
 	})
 	if err != nil {
 		return nil, err
 	}
+	if !modified.IsEmpty() {
+		sl.fireWebhooks(r.RotationID, WebhookEventJoined, params.Time, nil, modified)
+	}
 
 	out := &OutJoinRotation{
 		Modified: modified,
 		MD:       md.Markdownf("added %s to %s.", modified.MarkdownWithSkills(), r.Markdown()),
 	}
+	if params.Trainee {
+		out.MD = md.Markdownf("added %s to %s as trainees.", modified.MarkdownWithSkills(), r.Markdown())
+	}
 	sl.logAPI(out)
 	return out, nil
 }
@@ -26,6 +26,9 @@ func (sl *sl) LeaveRotation(params InJoinRotation) (*OutJoinRotation, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !modified.IsEmpty() {
+		sl.fireWebhooks(r.RotationID, WebhookEventLeft, params.Time, nil, modified)
+	}
 
 	out := &OutJoinRotation{
 		Modified: modified,
@@ -4,6 +4,8 @@
 package sl
 
 import (
+	"time"
+
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
@@ -11,6 +13,10 @@ import (
 type InQualify struct {
 	MattermostUserIDs *types.IDSet
 	SkillLevels       []SkillLevel
+	Time              types.Time
+
+	// Expires is the time the qualification expires, zero for never.
+	Expires types.Time
 }
 
 type OutQualify struct {
@@ -30,7 +36,10 @@ func (sl *sl) Qualify(params InQualify) (*OutQualify, error) {
 	}
 	defer sl.popLogger()
 
-	err = sl.qualify(users, params.SkillLevels)
+	if params.Time.IsZero() {
+		params.Time = types.NewTime(time.Now())
+	}
+	err = sl.qualify(users, params.SkillLevels, params.Time, params.Expires)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +48,10 @@ func (sl *sl) Qualify(params InQualify) (*OutQualify, error) {
 		Users: users,
 		MD:    md.Markdownf("added skill(s) %s to %s.", params.SkillLevels, users.Markdown()),
 	}
+	if !params.Expires.IsZero() {
+		out.MD = md.Markdownf("added skill(s) %s to %s, expiring %s.",
+			params.SkillLevels, users.Markdown(), sl.actingUser.Time(params.Expires))
+	}
 	sl.logAPI(out)
 	return out, nil
 }
@@ -0,0 +1,52 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type InSetWorkingHours struct {
+	MattermostUserIDs *types.IDSet
+
+	// WorkingHours to set, nil to clear.
+	WorkingHours *WorkingHours
+}
+
+type OutSetWorkingHours struct {
+	md.MD
+	Users *Users
+}
+
+func (sl *sl) SetWorkingHours(params InSetWorkingHours) (*OutSetWorkingHours, error) {
+	users := NewUsers()
+	err := sl.Setup(
+		pushAPILogger("SetWorkingHours", params),
+		withExpandedUsers(&params.MattermostUserIDs, users),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	for _, user := range users.AsArray() {
+		user.WorkingHours = params.WorkingHours
+	}
+	err = sl.storeUsers(users)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutSetWorkingHours{
+		Users: users,
+	}
+	if params.WorkingHours == nil {
+		out.MD = md.Markdownf("cleared working hours for %s.", users.Markdown())
+	} else {
+		out.MD = md.Markdownf("set working hours %s for %s.", params.WorkingHours.Markdown(), users.Markdown())
+	}
+	sl.logAPI(out)
+	return out, nil
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+const webhookSecretLength = 32
+
+type InSetWebhook struct {
+	RotationID types.ID
+	URL        string
+
+	// Secret signs the payloads; a new webhook gets a random one if empty,
+	// an existing one keeps its own.
+	Secret string `json:"-"`
+
+	// Events to send, all if empty.
+	Events *types.IDSet `json:",omitempty"`
+
+	// Remove removes the webhook with the URL.
+	Remove bool `json:",omitempty"`
+}
+
+type OutWebhooks struct {
+	md.MD
+	RotationID types.ID
+
+	// Webhooks are listed without their secrets.
+	Webhooks []*Webhook
+}
+
+// SetWebhook adds, updates, or removes the rotation's webhook with the URL.
+func (sl *sl) SetWebhook(params InSetWebhook) (*OutWebhooks, error) {
+	r := NewRotation()
+	err := sl.Setup(
+		pushAPILogger("SetWebhook", params),
+		withLoadRotation(&params.RotationID, r),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sl.popLogger()
+
+	err = validateWebhookURL(params.URL)
+	if err != nil {
+		return nil, err
+	}
+	if params.Events != nil {
+		for _, event := range params.Events.IDs() {
+			if !WebhookEvents.Contains(event) {
+				return nil, errors.Errorf("unknown webhook event %s, must be one of %v", event, WebhookEvents.IDs())
+			}
+		}
+	}
+
+	hooks, err := sl.loadRotationWebhooks(r.RotationID)
+	if err != nil {
+		return nil, err
+	}
+	var existing *Webhook
+	kept := []*Webhook{}
+	for _, h := range hooks.Webhooks {
+		if h.URL == params.URL {
+			existing = h
+			if params.Remove {
+				continue
+			}
+		}
+		kept = append(kept, h)
+	}
+	hooks.Webhooks = kept
+
+	var msg md.MD
+	generatedSecret := ""
+	switch {
+	case params.Remove:
+		if existing == nil {
+			return nil, errors.Errorf("webhook %s is not found in %s", params.URL, r.Markdown())
+		}
+		msg = md.Markdownf("removed webhook %s from %s.", params.URL, r.Markdown())
+
+	case existing != nil:
+		existing.Events = params.Events
+		if params.Secret != "" {
+			existing.Secret = params.Secret
+		}
+		msg = md.Markdownf("updated webhook %s in %s.", existing.Markdown(), r.Markdown())
+
+	default:
+		h := &Webhook{
+			URL:    params.URL,
+			Secret: params.Secret,
+			Events: params.Events,
+		}
+		if h.Secret == "" {
+			h.Secret = model.NewRandomString(webhookSecretLength)
+			generatedSecret = h.Secret
+		}
+		hooks.Webhooks = append(hooks.Webhooks, h)
+		msg = md.Markdownf("added webhook %s to %s.", h.Markdown(), r.Markdown())
+	}
+
+	err = sl.storeRotationWebhooks(hooks)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutWebhooks{
+		MD:         msg,
+		RotationID: r.RotationID,
+		Webhooks:   withoutSecrets(hooks.Webhooks),
+	}
+	// The output may have the secret, log the message before adding it.
+	sl.logAPI(out)
+	if generatedSecret != "" {
+		out.MD += md.Markdownf(" Verify the payloads' signatures with the secret `%s`, it is not shown again.", generatedSecret)
+	}
+	return out, nil
+}
+
+// ListWebhooks returns the rotation's webhooks, without their secrets.
+func (sl *sl) ListWebhooks(rotationID types.ID) (*OutWebhooks, error) {
+	r := NewRotation()
+	err := sl.Setup(withLoadRotation(&rotationID, r))
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := sl.loadRotationWebhooks(r.RotationID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &OutWebhooks{
+		RotationID: r.RotationID,
+		Webhooks:   withoutSecrets(hooks.Webhooks),
+	}
+	if len(out.Webhooks) == 0 {
+		out.MD = md.Markdownf("%s has no webhooks.", r.Markdown())
+		return out, nil
+	}
+	out.MD = md.Markdownf("%s webhooks:\n", r.Markdown())
+	for _, h := range out.Webhooks {
+		out.MD += md.Markdownf("- %s\n", h.Markdown())
+	}
+	return out, nil
+}
+
+func withoutSecrets(hooks []*Webhook) []*Webhook {
+	out := []*Webhook{}
+	for _, h := range hooks {
+		masked := *h
+		masked.Secret = ""
+		out = append(out, &masked)
+	}
+	return out
+}
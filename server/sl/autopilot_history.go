@@ -0,0 +1,163 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// What triggered an autopilot run.
+const (
+	AutopilotTriggerManual    = types.ID("manual")
+	AutopilotTriggerScheduled = types.ID("scheduled")
+)
+
+// DefaultAutopilotHistoryLimit is the number of runs kept in a rotation's
+// autopilot history, unless the rotation says otherwise.
+const DefaultAutopilotHistoryLimit = 100
+
+// AutopilotStage is the outcome of one of the autopilot's stages in a run.
+type AutopilotStage struct {
+	Name   string
+	Result string `json:",omitempty"`
+	Error  string `json:",omitempty"`
+
+	// idle is set if the stage had nothing to do.
+	idle bool
+}
+
+// autopilotIdle is the result of a stage that had nothing to do.
+type autopilotIdle md.MD
+
+func (m autopilotIdle) Markdown() md.MD { return md.MD(m) }
+func (m autopilotIdle) String() string  { return string(m) }
+
+// AutopilotRun records an autopilot run on a rotation. A run that failed has
+// the Error, and the stages up to, and including the failed one.
+type AutopilotRun struct {
+	Time                   types.Time
+	Trigger                types.ID
+	ActingMattermostUserID types.ID
+	Stages                 []*AutopilotStage
+	Error                  string `json:",omitempty"`
+}
+
+// isIdle returns true if none of the run's stages had anything to do.
+func (run *AutopilotRun) isIdle() bool {
+	if run.Error != "" {
+		return false
+	}
+	for _, stage := range run.Stages {
+		if !stage.idle {
+			return false
+		}
+	}
+	return true
+}
+
+// MarkdownBullets uses users, if available, to display the acting user's name.
+func (run *AutopilotRun) MarkdownBullets(users *Users) md.MD {
+	acting := NewUser(run.ActingMattermostUserID)
+	if users != nil && users.Contains(run.ActingMattermostUserID) {
+		acting = users.Get(run.ActingMattermostUserID)
+	}
+	out := md.Markdownf("- %v %s, by %s", run.Time, run.Trigger, acting.Markdown())
+	if run.Error != "" {
+		out += md.Markdownf(": **failed**: %s", run.Error)
+	}
+	out += "\n"
+	for _, stage := range run.Stages {
+		switch {
+		case stage.Error != "":
+			out += md.Markdownf("  - %s: **error**: %s\n", stage.Name, stage.Error)
+		case stage.Result != "":
+			// The results of the stages may have nested bullets.
+			out += md.Markdownf("  - %s\n", strings.ReplaceAll(stage.Result, "\n", "\n  "))
+		}
+	}
+	return out
+}
+
+// AutopilotHistory is the rotation's most recent autopilot runs, oldest first.
+type AutopilotHistory struct {
+	PluginVersion string `json:",omitempty"`
+	RotationID    types.ID
+	Runs          []*AutopilotRun `json:",omitempty"`
+}
+
+func NewAutopilotHistory(rotationID types.ID) *AutopilotHistory {
+	return &AutopilotHistory{
+		RotationID: rotationID,
+		Runs:       []*AutopilotRun{},
+	}
+}
+
+// Append adds the run to the history, and drops the oldest runs beyond limit.
+func (h *AutopilotHistory) Append(run *AutopilotRun, limit int) {
+	h.Runs = append(h.Runs, run)
+	if limit > 0 && len(h.Runs) > limit {
+		h.Runs = h.Runs[len(h.Runs)-limit:]
+	}
+}
+
+// MarkdownBullets displays up to the last n runs, most recent first; all of
+// them if n is 0.
+func (h *AutopilotHistory) MarkdownBullets(users *Users, n int) md.MD {
+	out := md.MD("")
+	for i := len(h.Runs) - 1; i >= 0; i-- {
+		if n > 0 && len(h.Runs)-i > n {
+			break
+		}
+		out += h.Runs[i].MarkdownBullets(users)
+	}
+	return out
+}
+
+// AutopilotHistoryLimit returns the number of runs to keep in the rotation's
+// autopilot history.
+func (r *Rotation) AutopilotHistoryLimit() int {
+	if r.AutopilotSettings.HistoryLimit > 0 {
+		return r.AutopilotSettings.HistoryLimit
+	}
+	return DefaultAutopilotHistoryLimit
+}
+
+func (sl *sl) loadAutopilotHistory(rotationID types.ID) (*AutopilotHistory, error) {
+	h := NewAutopilotHistory(rotationID)
+	err := sl.Store.Entity(KeyAutopilotHistory).Load(rotationID, h)
+	if err == kvstore.ErrNotFound {
+		return h, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load autopilot history for %s", rotationID)
+	}
+	return h, nil
+}
+
+// recordAutopilotRun appends the run to the rotation's autopilot history.
+// The scheduled runs that had nothing to do, including all of them on the
+// rotations with the autopilot off, are not recorded, not to crowd out the
+// others.
+func (sl *sl) recordAutopilotRun(r *Rotation, run *AutopilotRun) error {
+	if run.Trigger == AutopilotTriggerScheduled && (run.isIdle() || !r.AutopilotSettings.isOn()) {
+		return nil
+	}
+	h, err := sl.loadAutopilotHistory(r.RotationID)
+	if err != nil {
+		return err
+	}
+	h.Append(run, r.AutopilotHistoryLimit())
+	h.PluginVersion = sl.conf.PluginVersion
+	err = sl.Store.Entity(KeyAutopilotHistory).Store(r.RotationID, h)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store autopilot history for %s", r.RotationID)
+	}
+	return nil
+}
@@ -4,8 +4,10 @@
 package sl
 
 import (
+	"fmt"
 	"sort"
 
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/ics"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
@@ -13,6 +15,7 @@ const (
 	ReasonTask     = "task"
 	ReasonGrace    = "grace"
 	ReasonPersonal = "personal"
+	ReasonHoliday  = "holiday"
 )
 
 type Unavailable struct {
@@ -21,8 +24,17 @@ type Unavailable struct {
 
 	TaskID     types.ID
 	RotationID types.ID
+
+	// UID identifies the personal events imported from a calendar, and
+	// Source is the name of the calendar.
+	UID    string `json:",omitempty"`
+	Source string `json:",omitempty"`
 }
 
+// DefaultCalendarSource names the imported calendar, unless the import says
+// otherwise.
+const DefaultCalendarSource = "default"
+
 func NewUnavailable(reason string, interval types.Interval) *Unavailable {
 	return &Unavailable{
 		Reason:   reason,
@@ -65,3 +77,76 @@ func (by unavailableBy) Sort(uu []*Unavailable) {
 func byStartDate(u1, u2 *Unavailable) bool {
 	return u1.Start.Before(u2.Start.Time)
 }
+
+// unavailableFromEvents converts the busy calendar events that have not
+// finished by now into personal unavailability. Only the first event with a
+// UID is used; the events with no UID are identified by their start and
+// summary.
+func unavailableFromEvents(events []*ics.Event, source string, now types.Time) []*Unavailable {
+	uu := []*Unavailable{}
+	seen := map[string]bool{}
+	for _, e := range events {
+		if e.Free || !e.Finish.After(e.Start) || !e.Finish.After(now.Time) {
+			continue
+		}
+		uid := e.UID
+		if uid == "" {
+			uid = fmt.Sprintf("%v-%s", e.Start.Unix(), e.Summary)
+		}
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+
+		u := NewUnavailable(ReasonPersonal, types.NewInterval(types.NewTime(e.Start), types.NewTime(e.Finish)))
+		u.UID = uid
+		u.Source = source
+		uu = append(uu, u)
+	}
+	return uu
+}
+
+// importUnavailable replaces the user's personal events imported from the
+// source with uu, matching them by UID. The events that have finished by now
+// are kept, as uu does not have them; so are the events added by hand, and
+// the ones imported from the other sources.
+func (user *User) importUnavailable(uu []*Unavailable, source string, now types.Time) (added, updated, removed int) {
+	imported := map[string]*Unavailable{}
+	for _, u := range uu {
+		imported[u.UID] = u
+	}
+
+	kept := []*Unavailable{}
+	for _, existing := range user.Calendar {
+		if existing.Reason != ReasonPersonal || existing.UID == "" || existing.Source != source {
+			kept = append(kept, existing)
+			continue
+		}
+		u, ok := imported[existing.UID]
+		if !ok {
+			if !existing.Finish.After(now.Time) {
+				kept = append(kept, existing)
+				continue
+			}
+			removed++
+			continue
+		}
+		if !existing.Start.Equal(u.Start.Time) || !existing.Finish.Equal(u.Finish.Time) {
+			existing.Interval = u.Interval
+			updated++
+		}
+		kept = append(kept, existing)
+		delete(imported, existing.UID)
+	}
+	user.Calendar = kept
+
+	for _, u := range uu {
+		if imported[u.UID] == nil {
+			continue
+		}
+		user.Calendar = append(user.Calendar, u)
+		added++
+	}
+	unavailableBy(byStartDate).Sort(user.Calendar)
+	return added, updated, removed
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/ics"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// ErrInvalidCalendarToken is returned when a calendar feed is requested with
+// a token that is not the user's.
+var ErrInvalidCalendarToken = errors.New("invalid calendar token")
+
+const calendarTokenLength = 32
+
+// CalendarToken is the user's secret for the calendar feeds. It is stored
+// apart from the User, so that it is not shown with the user.
+type CalendarToken struct {
+	PluginVersion    string
+	MattermostUserID types.ID
+	Token            string
+}
+
+// uidDomain qualifies the UIDs of the exported events.
+const uidDomain = "solar-lottery"
+
+func (sl *sl) loadCalendarToken(mattermostUserID types.ID) (*CalendarToken, error) {
+	token := &CalendarToken{}
+	err := sl.Store.Entity(KeyCalendarToken).Load(mattermostUserID, token)
+	if err == kvstore.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load calendar token for %s", mattermostUserID)
+	}
+	return token, nil
+}
+
+func (sl *sl) storeCalendarToken(token *CalendarToken) error {
+	token.PluginVersion = sl.conf.PluginVersion
+	err := sl.Store.Entity(KeyCalendarToken).Store(token.MattermostUserID, token)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store calendar token for %s", token.MattermostUserID)
+	}
+	return nil
+}
+
+// verifyCalendarToken checks the token against the user's. It does not need
+// the user to be set up, nor does it store anything, so that the requests
+// with invalid tokens are rejected before the user is loaded.
+func (sl *sl) verifyCalendarToken(mattermostUserID types.ID, token string) error {
+	stored, err := sl.loadCalendarToken(mattermostUserID)
+	if err != nil {
+		return err
+	}
+	if stored == nil || token == "" ||
+		subtle.ConstantTimeCompare([]byte(stored.Token), []byte(token)) != 1 {
+		return ErrInvalidCalendarToken
+	}
+	return nil
+}
+
+func newCalendarToken(mattermostUserID types.ID) *CalendarToken {
+	return &CalendarToken{
+		MattermostUserID: mattermostUserID,
+		Token:            model.NewRandomString(calendarTokenLength),
+	}
+}
+
+// userCalendarEvents returns the user's tasks, grace periods, and personal
+// unavailability as calendar events.
+func userCalendarEvents(user *User) []*ics.Event {
+	events := []*ics.Event{}
+	for _, u := range user.Calendar {
+		if u.IsEmpty() {
+			continue
+		}
+		e := &ics.Event{
+			UID:    fmt.Sprintf("%s-%s-%v@%s", u.Reason, u.TaskID, u.Start.Unix(), uidDomain),
+			Start:  u.Start.Time,
+			Finish: u.Finish.Time,
+		}
+		switch u.Reason {
+		case ReasonTask:
+			e.Summary = fmt.Sprintf("On call: %s", u.TaskID)
+		case ReasonGrace:
+			e.Summary = fmt.Sprintf("Grace period after %s", u.TaskID)
+		default:
+			e.Summary = "Unavailable"
+		}
+		if u.RotationID != "" {
+			e.Description = fmt.Sprintf("Rotation %s", u.RotationID)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// rotationCalendarEvents returns the rotation's tasks, with their assignees,
+// as calendar events. The cancelled tasks, and the tasks with no time are
+// left out.
+func rotationCalendarEvents(r *Rotation) []*ics.Event {
+	events := []*ics.Event{}
+	for _, t := range r.Tasks.AsArray() {
+		interval := t.Interval()
+		if interval.IsEmpty() || t.State == TaskStateCancelled {
+			continue
+		}
+		assigned := "unassigned"
+		if !t.Users.IsEmpty() {
+			assigned = t.Users.String()
+		}
+		e := &ics.Event{
+			UID:     fmt.Sprintf("task-%s@%s", t.TaskID, uidDomain),
+			Summary: fmt.Sprintf("%s: %s", t.TaskID, assigned),
+			Start:   interval.Start.Time,
+			Finish:  interval.Finish.Time,
+		}
+		description := []string{fmt.Sprintf("Status: %s", t.State)}
+		if t.Summary != "" {
+			description = append(description, t.Summary)
+		}
+		if t.Holiday != "" {
+			description = append(description, fmt.Sprintf("Holiday: %s", t.Holiday))
+		}
+		e.Description = strings.Join(description, "\n")
+		events = append(events, e)
+	}
+	return events
+}
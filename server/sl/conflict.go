@@ -0,0 +1,126 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// The policies for the overlaps with the tasks, and their grace periods, in
+// other rotations.
+const (
+	ConflictIgnore = types.ID("ignore")
+	ConflictWarn   = types.ID("warn")
+	ConflictBlock  = types.ID("block")
+)
+
+var ConflictPolicies = types.NewIDSet(ConflictIgnore, ConflictWarn, ConflictBlock)
+
+// ConflictPolicy returns the rotation's policy for conflicts with other
+// rotations, block by default.
+func (r *Rotation) ConflictPolicy() types.ID {
+	if r.TaskSettings.Conflicts == "" {
+		return ConflictBlock
+	}
+	return r.TaskSettings.Conflicts
+}
+
+// FindBlocking returns the user's calendar events that keep the user from
+// serving the rotation's task in interval: the personal unavailability, the
+// regional holidays, the rotation's own tasks and, unless the conflict policy
+// says otherwise, the tasks and the grace periods in other rotations.
+func (r *Rotation) FindBlocking(user *User, interval types.Interval) []*Unavailable {
+	found := user.findHolidays(interval)
+	for _, u := range user.FindUnavailable(interval, "", "") {
+		if r.ConflictPolicy() != ConflictBlock && isConflict(u, r.RotationID) {
+			continue
+		}
+		found = append(found, u)
+	}
+	return found
+}
+
+// FindConflicts returns the user's tasks, and their grace periods, in other
+// rotations that overlap interval.
+func (user *User) FindConflicts(interval types.Interval, rotationID types.ID) []*Unavailable {
+	var found []*Unavailable
+	for _, u := range user.FindUnavailable(interval, "", "") {
+		if isConflict(u, rotationID) {
+			found = append(found, u)
+		}
+	}
+	return found
+}
+
+func isConflict(u *Unavailable, rotationID types.ID) bool {
+	return (u.Reason == ReasonTask || u.Reason == ReasonGrace) &&
+		u.RotationID != "" && u.RotationID != rotationID
+}
+
+// taskConflicts returns the conflicting events for each of the users that has
+// any.
+func taskConflicts(r *Rotation, task *Task, users *Users) map[types.ID][]*Unavailable {
+	interval := task.Interval()
+	conflicts := map[types.ID][]*Unavailable{}
+	if interval.IsEmpty() {
+		return conflicts
+	}
+	for _, user := range users.AsArray() {
+		found := user.FindConflicts(interval, r.RotationID)
+		if len(found) > 0 {
+			conflicts[user.MattermostUserID] = found
+		}
+	}
+	return conflicts
+}
+
+// checkConflicts fails if any of the users has a conflict, and the rotation's
+// policy is to block.
+func checkConflicts(r *Rotation, task *Task, users *Users) error {
+	if r.ConflictPolicy() != ConflictBlock {
+		return nil
+	}
+	for _, user := range users.AsArray() {
+		found := taskConflicts(r, task, NewUsers(user))[user.MattermostUserID]
+		if len(found) > 0 {
+			return errors.Errorf("user %s has conflicting tasks: %s", user.Markdown(), user.MarkdownConflicts(found))
+		}
+	}
+	return nil
+}
+
+// markdownConflictWarnings lists the users' conflicts with other rotations,
+// unless the rotation ignores them.
+func markdownConflictWarnings(r *Rotation, task *Task, users *Users) md.MD {
+	if r.ConflictPolicy() == ConflictIgnore {
+		return ""
+	}
+	conflicts := taskConflicts(r, task, users)
+	out := md.MD("")
+	for _, user := range users.AsArray() {
+		found := conflicts[user.MattermostUserID]
+		if len(found) == 0 {
+			continue
+		}
+		out += md.Markdownf("\n- warning: %s has conflicting tasks: %s", user.Markdown(), user.MarkdownConflicts(found))
+	}
+	return out
+}
+
+func (user *User) MarkdownConflicts(uu []*Unavailable) md.MD {
+	out := []string{}
+	for _, u := range uu {
+		name := u.TaskID.String()
+		if u.Reason == ReasonGrace {
+			name += " grace period"
+		}
+		out = append(out, md.Markdownf("%s (%s)", name, user.MarkdownInterval(u.Interval)).String())
+	}
+	return md.MD(strings.Join(out, ", "))
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestFindBlocking(t *testing.T) {
+	personal := NewUnavailable(ReasonPersonal, types.MustParseInterval("2020-03-01", "2020-03-02"))
+	own := &Unavailable{Reason: ReasonTask, TaskID: "r1#1", RotationID: "r1",
+		Interval: types.MustParseInterval("2020-03-01", "2020-03-02")}
+	other := &Unavailable{Reason: ReasonTask, TaskID: "r2#1", RotationID: "r2",
+		Interval: types.MustParseInterval("2020-03-01", "2020-03-02")}
+	otherGrace := &Unavailable{Reason: ReasonGrace, TaskID: "r2#1", RotationID: "r2",
+		Interval: types.MustParseInterval("2020-03-02", "2020-03-04")}
+	user := NewUser("test-user")
+	user.AddUnavailable(personal, own, other, otherGrace)
+
+	interval := types.MustParseInterval("2020-03-01", "2020-03-05")
+	r := NewRotation()
+	r.RotationID = "r1"
+
+	require.Equal(t, []*Unavailable{own}, user.FindUnavailable(interval, "r1", ""))
+	require.Equal(t, []*Unavailable{other, otherGrace}, user.FindConflicts(interval, "r1"))
+
+	require.Equal(t, ConflictBlock, r.ConflictPolicy())
+	require.Len(t, r.FindBlocking(user, interval), 4)
+
+	r.TaskSettings.Conflicts = ConflictWarn
+	require.ElementsMatch(t, []*Unavailable{personal, own}, r.FindBlocking(user, interval))
+
+	r.TaskSettings.Conflicts = ConflictIgnore
+	require.ElementsMatch(t, []*Unavailable{personal, own}, r.FindBlocking(user, interval))
+}
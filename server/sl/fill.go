@@ -5,15 +5,20 @@ package sl
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
 type TaskFiller interface {
-	FillTask(rotation *Rotation, task *Task, forTime types.Time, logger bot.Logger) (*Users, error)
+	// FillTask returns the users to add to the task, and the explanation of
+	// how they were picked. The explanation is also returned, if available,
+	// when the fill fails.
+	FillTask(rotation *Rotation, task *Task, forTime types.Time, logger bot.Logger) (*Users, *FillExplanation, error)
 }
 
 var ErrFillInsufficient = errors.New("insufficient")
@@ -24,6 +29,9 @@ type FillError struct {
 	FailedNeed *Need
 	UnmetNeeds *Needs
 	TaskID     types.ID
+
+	// ViolatedPairs are the pair rules that kept users from being picked.
+	ViolatedPairs PairRules `json:",omitempty"`
 }
 
 func (e FillError) Error() string {
@@ -40,8 +48,122 @@ func (e FillError) Error() string {
 		}
 		message += fmt.Sprintf("unfilled needs %s", e.UnmetNeeds.Markdown())
 	}
+	if len(e.ViolatedPairs) > 0 {
+		message += fmt.Sprintf(", violated pair rules %s", e.ViolatedPairs.Markdown(nil))
+	}
 	if e.Err != nil {
 		message = errors.WithMessage(e.Err, message).Error()
 	}
 	return message
 }
+
+const (
+	DisqualifiedUnavailable  = "unavailable"
+	DisqualifiedLimit        = "limit violation"
+	DisqualifiedMissingSkill = "missing skill"
+	DisqualifiedPair         = "pair rule"
+	DisqualifiedWorkload     = "workload cap"
+	DisqualifiedWorkingHours = "working hours"
+)
+
+// FillExplanation describes how a filler made its choices: the candidate pool,
+// the users that were disqualified and why, and the order of the picks.
+type FillExplanation struct {
+	Filler       types.ID
+	TaskID       types.ID
+	Preassigned  *types.IDSet
+	Pool         []*FillCandidate    `json:",omitempty"`
+	Disqualified []*FillDisqualified `json:",omitempty"`
+	Picks        []*FillPick         `json:",omitempty"`
+}
+
+// FillCandidate is a user in the pool, with the probability of being picked
+// first. For the fillers that do not pick at random, the probability is 1 for
+// the next user in line, and 0 for all others.
+type FillCandidate struct {
+	MattermostUserID types.ID
+	Weight           float64
+	Probability      float64
+}
+
+type FillDisqualified struct {
+	MattermostUserID types.ID
+	Reason           string
+	Details          string `json:",omitempty"`
+}
+
+type FillPick struct {
+	Need             string
+	MattermostUserID types.ID
+}
+
+func NewFillExplanation(filler types.ID, task *Task) *FillExplanation {
+	return &FillExplanation{
+		Filler:      filler,
+		TaskID:      task.TaskID,
+		Preassigned: types.NewIDSet(task.MattermostUserIDs.IDs()...),
+	}
+}
+
+func (e *FillExplanation) AddCandidate(mattermostUserID types.ID, weight, probability float64) {
+	e.Pool = append(e.Pool, &FillCandidate{
+		MattermostUserID: mattermostUserID,
+		Weight:           weight,
+		Probability:      probability,
+	})
+}
+
+func (e *FillExplanation) Disqualify(mattermostUserID types.ID, reason, details string) {
+	e.Disqualified = append(e.Disqualified, &FillDisqualified{
+		MattermostUserID: mattermostUserID,
+		Reason:           reason,
+		Details:          details,
+	})
+}
+
+func (e *FillExplanation) Pick(need Need, mattermostUserID types.ID) {
+	e.Picks = append(e.Picks, &FillPick{
+		Need:             need.SkillLevel().String(),
+		MattermostUserID: mattermostUserID,
+	})
+}
+
+// Markdown uses users, if available, to display the user names.
+func (e *FillExplanation) Markdown(users *Users) md.MD {
+	name := func(id types.ID) md.MD {
+		if users != nil && users.Contains(id) {
+			return users.Get(id).Markdown()
+		}
+		return md.Markdownf("`%s`", id)
+	}
+
+	out := md.Markdownf("Explanation (%s filler) for %s:\n", e.Filler, e.TaskID)
+	if !e.Preassigned.IsEmpty() {
+		names := []string{}
+		for _, id := range e.Preassigned.IDs() {
+			names = append(names, name(id).String())
+		}
+		out += md.Markdownf("- Pre-assigned: %s\n", strings.Join(names, ", "))
+	}
+	out += md.Markdownf("- Pool (%v):\n", len(e.Pool))
+	for i, c := range e.Pool {
+		out += md.Markdownf("  %v. **%.5f**: %s\n", i+1, c.Probability, name(c.MattermostUserID))
+	}
+	if len(e.Disqualified) > 0 {
+		out += md.Markdownf("- Disqualified (%v):\n", len(e.Disqualified))
+		for _, d := range e.Disqualified {
+			out += md.Markdownf("  - %s: %s", name(d.MattermostUserID), d.Reason)
+			if d.Details != "" {
+				out += md.Markdownf(" (%s)", d.Details)
+			}
+			out += "\n"
+		}
+	}
+	if len(e.Picks) > 0 {
+		out += md.Markdownf("- Picked:\n")
+		for i, p := range e.Picks {
+			out += md.Markdownf("  %v. %s for %s\n", i+1, name(p.MattermostUserID), p.Need)
+		}
+	}
+	return out
+}
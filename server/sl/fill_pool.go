@@ -0,0 +1,101 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// NewFillPool returns the rotation's users who can fill the task, for all the
+// fillers to start from the same pool. The users who are unavailable, would
+// exceed the workload cap, or, unless the rotation only prefers the users
+// within them, are outside of their working hours, are disqualified in the
+// explanation. offHours are the users left in the pool outside of their
+// working hours, for the filler to deprioritize.
+func NewFillPool(r *Rotation, t *Task, explanation *FillExplanation, logger bot.Logger) (pool *Users, offHours *types.IDSet) {
+	pool = NewUsers()
+	if r.Users != nil {
+		pool = r.Users.Clone()
+	}
+	offHours = types.NewIDSet()
+	interval := types.NewDurationInterval(t.ExpectedStart, t.ExpectedDuration)
+
+	// remove any unavailable users from the pool
+	for _, user := range pool.AsArray() {
+		overlapping := r.FindBlocking(user, interval)
+		if len(overlapping) == 0 {
+			continue
+		}
+		pool.Delete(user.MattermostUserID)
+		explanation.Disqualify(user.MattermostUserID, DisqualifiedUnavailable,
+			user.MarkdownUnavailableList(overlapping).String())
+		logger.Debugf("Disqualified %s: unavailable", user.Markdown())
+	}
+
+	// remove the users who would exceed the workload cap
+	workload := r.Workload()
+	for _, user := range pool.AsArray() {
+		exceeded := workload.Exceeded(user, t.TaskID, interval)
+		if exceeded == "" {
+			continue
+		}
+		pool.Delete(user.MattermostUserID)
+		explanation.Disqualify(user.MattermostUserID, DisqualifiedWorkload, exceeded)
+		logger.Debugf("Disqualified %s: workload cap", user.Markdown())
+	}
+
+	// remove, or deprioritize, the users whose working hours do not cover
+	// the task
+	if r.WorkingHoursPolicy() != WorkingHoursIgnore {
+		for _, user := range pool.AsArray() {
+			if !user.OffHours(interval) {
+				continue
+			}
+			if r.WorkingHoursPolicy() == WorkingHoursPrefer {
+				offHours.Set(user.MattermostUserID)
+				continue
+			}
+			pool.Delete(user.MattermostUserID)
+			explanation.Disqualify(user.MattermostUserID, DisqualifiedWorkingHours, user.WorkingHours.Markdown().String())
+			logger.Debugf("Disqualified %s: working hours", user.Markdown())
+		}
+	}
+	return pool, offHours
+}
+
+// PreassignVolunteers picks the task's volunteers from the pool, first come,
+// first served, if the rotation says so. A volunteer is picked if qualified
+// for an unmet need, within the limits and the pair rules, given the users
+// already on the task and the volunteers picked before. fill is called for
+// each of the picked volunteers, in order, with the need they meet.
+func PreassignVolunteers(r *Rotation, t *Task, pool, onTask *Users, require, limit *Needs, skillsAt time.Time, fill func(user *User, need Need)) {
+	if r.VolunteerPolicy() != VolunteerFirstCome {
+		return
+	}
+	onTask = onTask.Clone()
+	for _, id := range t.Volunteers() {
+		if !pool.Contains(id) {
+			continue
+		}
+		user := pool.Get(id)
+		need, ok := require.FirstUnmet(user, skillsAt)
+		if !ok {
+			continue
+		}
+		updatedLimit, _, violated := limit.CheckLimits(user, skillsAt)
+		if !violated.IsEmpty() {
+			continue
+		}
+		if len(r.TaskSettings.Pairs.Violated(onTask, user, skillsAt)) > 0 {
+			continue
+		}
+		limit = updatedLimit
+		require = require.CheckRequired(user, skillsAt)
+		onTask.Set(user)
+		fill(user, need)
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+type fill struct {
+	bot.Logger
+
+	// Parameters
+	r        *sl.Rotation
+	task     *sl.Task
+	skillsAt time.Time // the users' qualifications are checked at
+
+	// State
+	queue       *types.IDSet
+	pool        *sl.Users
+	served      *sl.Users
+	filled      *sl.Users
+	require     *sl.Needs
+	limit       *sl.Needs
+	explanation *sl.FillExplanation
+	violated    sl.PairRules
+	offHours    *types.IDSet
+}
+
+func newFill(r *sl.Rotation, t *sl.Task, now types.Time, logger bot.Logger) *fill {
+	explanation := sl.NewFillExplanation(Type, t)
+	pool, offHours := sl.NewFillPool(r, t, explanation, logger)
+
+	f := fill{
+		Logger:      logger,
+		r:           r,
+		task:        t,
+		skillsAt:    t.SkillsAt(now),
+		queue:       syncQueue(r),
+		pool:        pool,
+		served:      sl.NewUsers(),
+		filled:      sl.NewUsers(),
+		require:     t.Require.Clone(),
+		limit:       t.Limit.Clone(),
+		explanation: explanation,
+		offHours:    offHours,
+	}
+
+	// fill in all users already in the task
+	for _, user := range t.Users.AsArray() {
+		_ = f.fillUser(user, true)
+		f.Debugf("%s is already assigned", user.MarkdownWithSkills())
+	}
+	f.preassignVolunteers()
+	f.explainPool()
+
+	return &f
+}
+
+// explainPool adds the available users that qualify for any of the required
+// needs to the explanation, in the queue order. The first one is the next in
+// line, with the probability of 1.
+func (f *fill) explainPool() {
+	probability := 1.0
+	for _, id := range f.line() {
+		if !f.pool.Contains(id) {
+			continue
+		}
+		user := f.pool.Get(id)
+		qualified := false
+		for _, need := range f.require.AsArray() {
+			if ok, _ := need.QualifyUser(user, f.skillsAt); ok && need.Count() > 0 {
+				qualified = true
+				break
+			}
+		}
+		if !qualified {
+			f.explanation.Disqualify(id, sl.DisqualifiedMissingSkill, "does not qualify for "+f.require.String())
+			continue
+		}
+		f.explanation.AddCandidate(id, probability, probability)
+		probability = 0
+	}
+}
+
+// line is the order in which the users are considered: the queue, with the
+// users outside of their working hours moved to the back, if the rotation
+// prefers the ones within, and the volunteers moved to the front, if the
+// rotation weighs them up.
+func (f *fill) line() []types.ID {
+	ids := f.queue.IDs()
+	rank := func(id types.ID) int {
+		r := 0
+		if f.offHours.Contains(id) {
+			r += 2
+		}
+		if f.r.VolunteerPolicy() != sl.VolunteerWeight || !f.task.IsVolunteer(id) {
+			r++
+		}
+		return r
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return rank(ids[i]) < rank(ids[j])
+	})
+	return ids
+}
+
+// preassignVolunteers fills the task with the volunteers, first come, first
+// served, if the rotation says so.
+func (f *fill) preassignVolunteers() {
+	sl.PreassignVolunteers(f.r, f.task, f.pool, f.served, f.require, f.limit, f.skillsAt, func(user *sl.User, need sl.Need) {
+		f.fillUser(user, false)
+		f.explanation.Pick(need, user.MattermostUserID)
+		f.Debugf("...picked volunteer %s for %s", user.MarkdownWithSkills(), need)
+	})
+}
+
+// syncQueue makes the rotation's persisted queue match its current membership.
+// Users who joined since the last fill are appended to the back in the order
+// they joined, users who left are dropped.
+func syncQueue(r *sl.Rotation) *types.IDSet {
+	if r.FillSettings.Queue == nil {
+		r.FillSettings.Queue = types.NewIDSet()
+	}
+	queue := r.FillSettings.Queue
+	for _, id := range queue.IDs() {
+		if !r.MattermostUserIDs.Contains(id) {
+			queue.Delete(id)
+		}
+	}
+	for _, id := range r.MattermostUserIDs.IDs() {
+		if !queue.Contains(id) {
+			queue.Set(id)
+		}
+	}
+	return queue
+}
+
+func (f *fill) fill() (*sl.Users, error) {
+	f.Debugf(f.markdown())
+
+	for _, need := range f.sortedRequire() {
+		for f.require.Get(need.GetID()).Count() > 0 {
+			user := f.pickUser(need)
+			if user == nil {
+				return nil, f.newError(f.require.Get(need.GetID()), sl.ErrFillInsufficient)
+			}
+			f.explanation.Pick(need, user.MattermostUserID)
+			f.Debugf("...picked %s for %s", user.MarkdownWithSkills(), need)
+		}
+	}
+
+	// Everyone who served, including the pre-assigned users, goes to the back
+	// of the queue, in the order they were picked.
+	for _, user := range f.served.AsArray() {
+		f.queue.Delete(user.MattermostUserID)
+		f.queue.Set(user.MattermostUserID)
+	}
+
+	f.Debugf("filled %s for %s", f.filled.MarkdownWithSkills(), f.task.Markdown())
+	return f.filled, nil
+}
+
+// sortedRequire returns the unmet needs, with the specific skills first, and
+// "any" last so that it does not consume users that could fill a specific need.
+func (f *fill) sortedRequire() []sl.Need {
+	needs := []sl.Need{}
+	for _, need := range f.require.AsArray() {
+		if need.Count() > 0 {
+			needs = append(needs, need)
+		}
+	}
+	sort.SliceStable(needs, func(i, j int) bool {
+		return needs[i].SkillLevel().Skill != sl.AnySkill && needs[j].SkillLevel().Skill == sl.AnySkill
+	})
+	return needs
+}
+
+// pickUser walks the queue from the front, and fills the first available user
+// that qualifies for the need without violating any limits.
+func (f *fill) pickUser(need sl.Need) *sl.User {
+	for _, id := range f.line() {
+		if !f.pool.Contains(id) {
+			continue
+		}
+		user := f.pool.Get(id)
+		qualified, _ := need.QualifyUser(user, f.skillsAt)
+		if !qualified {
+			continue
+		}
+
+		// Users who violate the pair rules stay in the pool, a mentor picked
+		// later may make them eligible.
+		if violated := f.r.TaskSettings.Pairs.Violated(f.served, user, f.skillsAt); len(violated) > 0 {
+			for _, rule := range violated {
+				f.violated = f.violated.With(rule)
+			}
+			f.explanation.Disqualify(user.MattermostUserID, sl.DisqualifiedPair, violated.Markdown(f.r.Users).String())
+			f.Debugf("...skipped user %s: would violate pair rules %s", user.Markdown(), violated.Markdown(f.r.Users))
+			continue
+		}
+
+		violated := f.fillUser(user, false)
+		if !violated.IsEmpty() {
+			f.explanation.Disqualify(user.MattermostUserID, sl.DisqualifiedLimit, "would exceed the limit on "+violated.MarkdownSkillLevels())
+			f.Debugf("...skipped user %s: would exceed limits on %s", user.Markdown(), violated.Markdown())
+			continue
+		}
+		return user
+	}
+	return nil
+}
+
+func (f *fill) fillUser(user *sl.User, preassigned bool) (violated *sl.Needs) {
+	// The picked user is either accepted, or declined based on Limit
+	// constraints, so remove it from the pool right away
+	f.pool.Delete(user.MattermostUserID)
+
+	updatedLimit, _, violated := f.limit.CheckLimits(user, f.skillsAt)
+	if !preassigned && !violated.IsEmpty() {
+		return violated
+	}
+
+	f.limit = updatedLimit
+	f.require = f.require.CheckRequired(user, f.skillsAt)
+	f.served.Set(user)
+	if !preassigned {
+		f.filled.Set(user)
+	}
+	return violated
+}
+
+func (f *fill) markdown() string {
+	out := ""
+	out += fmt.Sprintf("filling task %s:\n", f.task.Markdown())
+	if !f.require.IsEmpty() {
+		out += fmt.Sprintf("- Requires: %s\n", f.require.Markdown())
+	}
+	if !f.limit.IsEmpty() {
+		out += fmt.Sprintf("- Limits: %s\n", f.limit.Markdown())
+	}
+	if !f.served.IsEmpty() {
+		out += fmt.Sprintf("- Pre-assigned users: %s\n", f.served.MarkdownWithSkills())
+	}
+	out += fmt.Sprintf("- Queue (%v):\n", f.queue.Len())
+	for i, id := range f.queue.IDs() {
+		if !f.pool.Contains(id) {
+			out += fmt.Sprintf("  %v. `%s` (not available)\n", i, id)
+			continue
+		}
+		out += fmt.Sprintf("  %v. %s\n", i, f.pool.Get(id).MarkdownWithSkills())
+	}
+	return out
+}
+
+func (f *fill) newError(need sl.Need, err error) *sl.FillError {
+	unmet := sl.NewNeeds()
+	for _, need := range f.require.AsArray() {
+		if need.Count() > 0 {
+			unmet.Set(need)
+		}
+	}
+	return &sl.FillError{
+		Err:           err,
+		UnmetNeeds:    unmet,
+		FailedNeed:    &need,
+		TaskID:        f.task.TaskID,
+		ViolatedPairs: f.violated,
+	}
+}
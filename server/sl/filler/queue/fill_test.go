@@ -0,0 +1,174 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/test"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestFill(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		require          *sl.Needs
+		limit            *sl.Needs
+		pairs            sl.PairRules
+		pool             *sl.Users
+		queue            *types.IDSet
+		assigned         *sl.Users
+		unavailable      *types.IDSet
+		expectFillError  error
+		expectFailedNeed sl.Need
+		expectUnmetNeeds *sl.Needs
+		expectFilled     []string
+		expectQueue      []types.ID
+	}{
+		{
+			name:         "happy first in queue",
+			require:      sl.NewNeeds(test.C1Any()),
+			pool:         sl.NewUsers(test.UserMobile1(), test.UserMobile2(), test.UserServer1()),
+			expectFilled: []string{test.UserIDMobile1},
+			expectQueue:  []types.ID{test.UserIDMobile2, test.UserIDServer1, test.UserIDMobile1},
+		},
+		{
+			name:         "happy persisted order",
+			require:      sl.NewNeeds(test.C1Any()),
+			pool:         sl.NewUsers(test.UserMobile1(), test.UserMobile2(), test.UserServer1()),
+			queue:        types.NewIDSet(test.UserIDServer1, test.UserIDMobile1, test.UserIDMobile2),
+			expectFilled: []string{test.UserIDServer1},
+			expectQueue:  []types.ID{test.UserIDMobile1, test.UserIDMobile2, test.UserIDServer1},
+		},
+		{
+			name:         "new users go to the back, departed are dropped",
+			require:      sl.NewNeeds(test.C1Any()),
+			pool:         sl.NewUsers(test.UserMobile1(), test.UserMobile2(), test.UserServer1()),
+			queue:        types.NewIDSet(test.UserIDGuru, test.UserIDMobile2),
+			expectFilled: []string{test.UserIDMobile2},
+			expectQueue:  []types.ID{test.UserIDMobile1, test.UserIDServer1, test.UserIDMobile2},
+		},
+		{
+			name:         "skip unqualified",
+			require:      sl.NewNeeds(test.C1ServerL1()),
+			pool:         sl.NewUsers(test.UserMobile1(), test.UserMobile2(), test.UserServer1()),
+			expectFilled: []string{test.UserIDServer1},
+			expectQueue:  []types.ID{test.UserIDMobile1, test.UserIDMobile2, test.UserIDServer1},
+		},
+		{
+			name:         "skip unavailable",
+			require:      sl.NewNeeds(test.C1Any()),
+			pool:         sl.NewUsers(test.UserMobile1(), test.UserMobile2(), test.UserServer1()),
+			unavailable:  types.NewIDSet(test.UserIDMobile1),
+			expectFilled: []string{test.UserIDMobile2},
+			expectQueue:  []types.ID{test.UserIDMobile1, test.UserIDServer1, test.UserIDMobile2},
+		},
+		{
+			name:         "specific needs before any",
+			require:      sl.NewNeeds(test.C1Any(), test.C1ServerL1()),
+			pool:         sl.NewUsers(test.UserMobile1(), test.UserServer1()),
+			queue:        types.NewIDSet(test.UserIDMobile1, test.UserIDServer1),
+			expectFilled: []string{test.UserIDServer1},
+			expectQueue:  []types.ID{test.UserIDMobile1, test.UserIDServer1},
+		},
+		{
+			name:         "skip limit violation",
+			require:      sl.NewNeeds(test.C2MobileL1()),
+			limit:        sl.NewNeeds(sl.NewNeed(1, test.ServerL1())),
+			assigned:     sl.NewUsers(test.UserServer1()),
+			pool:         sl.NewUsers(test.UserServer1(), test.UserGuru(), test.UserMobile1(), test.UserMobile2()),
+			expectFilled: []string{test.UserIDMobile1, test.UserIDMobile2},
+			expectQueue:  []types.ID{test.UserIDGuru, test.UserIDServer1, test.UserIDMobile1, test.UserIDMobile2},
+		},
+		{
+			name:         "skip pair rule violation",
+			require:      sl.NewNeeds(test.C2MobileL1()),
+			pairs:        sl.PairRules{sl.NewNeverTogether(test.UserIDGuru, test.UserIDMobile1)},
+			pool:         sl.NewUsers(test.UserMobile1(), test.UserMobile2(), test.UserGuru()),
+			queue:        types.NewIDSet(test.UserIDGuru, test.UserIDMobile1, test.UserIDMobile2),
+			expectFilled: []string{test.UserIDGuru, test.UserIDMobile2},
+			expectQueue:  []types.ID{test.UserIDMobile1, test.UserIDGuru, test.UserIDMobile2},
+		},
+		{
+			name:             "Err Insufficient",
+			require:          sl.NewNeeds(test.C2MobileL1()),
+			pool:             sl.NewUsers(test.UserServer1(), test.UserMobile1()),
+			expectFillError:  sl.ErrFillInsufficient,
+			expectUnmetNeeds: sl.NewNeeds(test.C1MobileL1()),
+			expectFailedNeed: test.C1MobileL1(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := makeTestFiller(t, tc.pool, tc.queue, tc.assigned, tc.unavailable, tc.require, tc.limit)
+			f.r.TaskSettings.Pairs = tc.pairs
+			filled, err := f.fill()
+			if tc.expectFillError == nil {
+				require.NoError(t, err)
+				require.Equal(t, tc.expectFilled, filled.TestIDs())
+				require.Equal(t, tc.expectQueue, f.r.FillSettings.Queue.IDs())
+				require.Equal(t, len(tc.expectFilled), len(f.explanation.Picks))
+				for i, pick := range f.explanation.Picks {
+					require.Equal(t, tc.expectFilled[i], string(pick.MattermostUserID))
+				}
+				return
+			}
+
+			require.Error(t, err)
+			ferr, _ := err.(*sl.FillError)
+			require.NotNil(t, ferr)
+			require.Equal(t, tc.expectFillError, ferr.Err)
+			require.Equal(t, tc.expectUnmetNeeds.AsArray(), ferr.UnmetNeeds.AsArray())
+			require.EqualValues(t, &tc.expectFailedNeed, ferr.FailedNeed)
+		})
+	}
+}
+
+func makeTestFiller(t testing.TB, pool *sl.Users, queue *types.IDSet, assigned *sl.Users, unavailable *types.IDSet, require, limit *sl.Needs) *fill {
+	if pool.IsEmpty() {
+		pool = sl.NewUsers()
+	}
+	r := &sl.Rotation{
+		RotationID: test.RotationID,
+		FillSettings: sl.FillSettings{
+			Beginning: types.MustParseTime("2020-01-01"),
+			Period: types.Period{
+				Period: types.EveryWeek,
+			},
+			Queue: queue,
+		},
+		MattermostUserIDs: types.NewIDSet(pool.IDs()...),
+		Users:             pool,
+	}
+	r.Init()
+
+	task := sl.NewTask(r.RotationID)
+	task.TaskID = r.RotationID + "#1"
+	task.ExpectedStart = types.MustParseTime("2020-02-02")
+	task.ExpectedDuration = 7 * 24 * time.Hour
+	if assigned != nil {
+		task.Users = assigned
+	}
+	if !limit.IsEmpty() {
+		task.Limit = limit
+	}
+	if !require.IsEmpty() {
+		task.Require = require
+	}
+	if unavailable != nil {
+		for _, id := range unavailable.IDs() {
+			pool.Get(id).AddUnavailable(sl.NewUnavailable(sl.ReasonPersonal,
+				types.MustParseInterval("2020-02-01", "2020-02-05")))
+		}
+	}
+
+	return newFill(r, task, types.MustParseTime("2020-01-15"),
+		// &bot.TestLogger{TB: t},
+		&bot.NilLogger{},
+	)
+}
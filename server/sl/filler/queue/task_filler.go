@@ -4,8 +4,6 @@
 package queue
 
 import (
-	"github.com/pkg/errors"
-
 	sl "github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
@@ -21,6 +19,8 @@ func New() sl.TaskFiller {
 	return &taskFiller{}
 }
 
-func (*taskFiller) FillTask(r *sl.Rotation, task *sl.Task, now types.Time, logger bot.Logger) (*sl.Users, error) {
-	return nil, errors.New("Queue autofill is not implemented")
+func (*taskFiller) FillTask(r *sl.Rotation, task *sl.Task, now types.Time, logger bot.Logger) (*sl.Users, *sl.FillExplanation, error) {
+	f := newFill(r, task, now, logger)
+	filled, err := f.fill()
+	return filled, f.explanation, err
 }
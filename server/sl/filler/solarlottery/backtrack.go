@@ -0,0 +1,112 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package solarlottery
+
+import (
+	"sort"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+)
+
+// maxBacktrackSteps bounds the backtracking search, so that a pathological
+// combination of needs and a large pool does not hang the fill.
+const maxBacktrackSteps = 100000
+
+type backtrackPick struct {
+	need sl.Need
+	user *sl.User
+}
+
+// backtrack searches for an assignment of users from pool that meets require
+// without violating limit, or the rotation's pair rules given the users
+// already on the task. At each step it fills the need with the fewest
+// qualified candidates, trying the candidates in the order of decreasing
+// weight, so the result still favors the users who have waited the longest.
+// Once a candidate has been tried for a need, it is excluded from the
+// alternatives, so each combination of users is visited only once.
+func (f *fill) backtrack(pool, onTask *sl.Users, require, limit *sl.Needs) ([]backtrackPick, bool) {
+	rules := f.r.TaskSettings.Pairs
+	neverTogether := sl.PairRules{}
+	for _, rule := range rules {
+		if rule.Type == sl.PairNeverTogether {
+			neverTogether = append(neverTogether, rule)
+		}
+	}
+
+	steps := 0
+	var search func(available, onTask *sl.Users, require, limit *sl.Needs) ([]backtrackPick, bool)
+	search = func(available, onTask *sl.Users, require, limit *sl.Needs) ([]backtrackPick, bool) {
+		steps++
+		if steps > maxBacktrackSteps {
+			return nil, false
+		}
+
+		var need *sl.Need
+		var candidates *sl.Users
+		for _, n := range require.AsArray() {
+			if n.Count() <= 0 {
+				continue
+			}
+			qualified, _ := n.QualifyUsers(available, f.skillsAt)
+			if qualified.IsEmpty() {
+				return nil, false
+			}
+			if need != nil && !fewerCandidates(n, qualified, *need, candidates) {
+				continue
+			}
+			n := n
+			need, candidates = &n, qualified
+		}
+		if need == nil {
+			// A mentee may be picked before the mentor, so the complete
+			// assignment is checked against the pair rules at the end.
+			for _, user := range onTask.AsArray() {
+				if pool.Contains(user.MattermostUserID) && len(rules.Violated(onTask, user, f.skillsAt)) > 0 {
+					return nil, false
+				}
+			}
+			return nil, true
+		}
+
+		w := NewWeighted()
+		for _, user := range candidates.AsArray() {
+			w.Append(user.MattermostUserID, f.userWeightF(user))
+		}
+		sort.Stable(w)
+
+		available = available.Clone()
+		for _, id := range w.ids {
+			user := available.Get(id)
+			available.Delete(id)
+			updatedLimit, _, violated := limit.CheckLimits(user, f.skillsAt)
+			if !violated.IsEmpty() {
+				continue
+			}
+			withUser := onTask.Join(sl.NewUsers(user))
+			if len(neverTogether.Violated(withUser, user, f.skillsAt)) > 0 {
+				continue
+			}
+			picks, ok := search(available, withUser, require.CheckRequired(user, f.skillsAt), updatedLimit)
+			if ok {
+				return append([]backtrackPick{{need: *need, user: user}}, picks...), true
+			}
+			if steps > maxBacktrackSteps {
+				break
+			}
+		}
+		return nil, false
+	}
+
+	return search(pool, onTask, require, limit)
+}
+
+// fewerCandidates is true if need a should be filled before need b: the needs
+// for specific skills go before "any", then the ones with fewer candidates.
+func fewerCandidates(a sl.Need, aCandidates *sl.Users, b sl.Need, bCandidates *sl.Users) bool {
+	aAny, bAny := a.GetID() == sl.AnySkill, b.GetID() == sl.AnySkill
+	if aAny != bAny {
+		return bAny
+	}
+	return len(aCandidates.AsArray()) < len(bCandidates.AsArray())
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package solarlottery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl/test"
+)
+
+func TestBacktrack(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		require      *sl.Needs
+		limit        *sl.Needs
+		pool         *sl.Users
+		expectFailed bool
+		expectPicks  []string
+	}{
+		{
+			name:    "prefers higher weight",
+			require: sl.NewNeeds(test.C1ServerL3()),
+			pool: sl.NewUsers(
+				test.UserServer1().WithLastServed(test.RotationID, recently),
+				test.UserServer2().WithLastServed(test.RotationID, longTimeAgo),
+			),
+			expectPicks: []string{test.UserIDServer2},
+		},
+		{
+			name:    "most constrained need first",
+			require: sl.NewNeeds(test.C1Any(), test.C1MobileL1()),
+			pool: sl.NewUsers(
+				test.UserServer1().WithLastServed(test.RotationID, longTimeAgo),
+				test.UserMobile1().WithLastServed(test.RotationID, recently),
+			),
+			expectPicks: []string{test.UserIDMobile1},
+		},
+		{
+			name:    "skips the higher weight user that leads to a dead end",
+			require: sl.NewNeeds(test.C1WebappL3(), test.C1ServerL3()),
+			limit:   sl.NewNeeds(test.C1ServerL1()),
+			pool: sl.NewUsers(
+				test.UserWebapp1().WithLastServed(test.RotationID, longTimeAgo),
+				test.UserServer1().WithLastServed(test.RotationID, recently),
+				test.UserGuru().WithLastServed(test.RotationID, recently),
+			),
+			expectPicks: []string{test.UserIDGuru},
+		},
+		{
+			name:    "no valid assignment",
+			require: sl.NewNeeds(test.C1WebappL3(), test.C1ServerL3()),
+			limit:   sl.NewNeeds(test.C1ServerL1()),
+			pool: sl.NewUsers(
+				test.UserWebapp1(),
+				test.UserServer1(),
+			),
+			expectFailed: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := makeTestFiller(t, tc.pool, nil, tc.require, tc.limit)
+			picks, ok := f.backtrack(f.pool, f.onTask(), f.require, f.limit)
+			if tc.expectFailed {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			ids := []string{}
+			for _, pick := range picks {
+				ids = append(ids, string(pick.user.MattermostUserID))
+			}
+			require.Equal(t, tc.expectPicks, ids)
+		})
+	}
+}
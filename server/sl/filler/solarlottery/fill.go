@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/sl"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
@@ -20,8 +21,9 @@ type fill struct {
 	// Parameters
 	r              *sl.Rotation
 	task           *sl.Task
-	forTime        int64 // seconds, unix time
-	doublingPeriod int64 // seconds
+	forTime        int64     // seconds, unix time
+	skillsAt       time.Time // the users' qualifications are checked at
+	doublingPeriod int64     // seconds
 	rand           *rand.Rand
 	pickRequire    func() (done bool, picked *sl.Need)
 
@@ -32,6 +34,10 @@ type fill struct {
 	require      *sl.Needs
 	requirePools map[types.ID]*sl.Users // by need ID (SkillLevel as string)
 	limit        *sl.Needs
+	explanation  *sl.FillExplanation
+	violated     sl.PairRules
+	offHours     *types.IDSet
+	volunteered  *sl.Users
 }
 
 func newFill(r *sl.Rotation, t *sl.Task, now types.Time, logger bot.Logger) *fill {
@@ -40,10 +46,8 @@ func newFill(r *sl.Rotation, t *sl.Task, now types.Time, logger bot.Logger) *fil
 		forTime = now
 	}
 
-	pool := sl.NewUsers()
-	if r.Users != nil {
-		pool = r.Users.Clone()
-	}
+	explanation := sl.NewFillExplanation(Type, t)
+	pool, offHours := sl.NewFillPool(r, t, explanation, logger)
 
 	// Double the weights every period (on average). Specifying fuzz makes the
 	// weights grow slower, thus making the user choice more random.
@@ -55,6 +59,7 @@ func newFill(r *sl.Rotation, t *sl.Task, now types.Time, logger bot.Logger) *fil
 		r:              r,
 		task:           t,
 		forTime:        forTime.Unix(),
+		skillsAt:       t.SkillsAt(now),
 		pool:           pool,
 		poolWeights:    map[types.ID]float64{},
 		filled:         sl.NewUsers(),
@@ -63,39 +68,99 @@ func newFill(r *sl.Rotation, t *sl.Task, now types.Time, logger bot.Logger) *fil
 		requirePools:   map[types.ID]*sl.Users{},
 		doublingPeriod: doubling,
 		rand:           rand.New(rand.NewSource(r.FillSettings.Seed)),
+		explanation:    explanation,
+		offHours:       offHours,
+		volunteered:    sl.NewUsers(),
 	}
 	f.userWeightF = f.userWeight
 
-	// remove any unavailable users from the pool
-	for _, user := range f.pool.AsArray() {
-		overlapping := user.FindUnavailable(
-			types.NewDurationInterval(t.ExpectedStart, t.ExpectedDuration), r.RotationID, "")
-		if len(overlapping) == 0 {
-			continue
-		}
-		f.pool.Delete(user.MattermostUserID)
-		logger.Debugf("Disqualified %s: unavailable", user.Markdown())
-	}
-
 	// fill in all users already in the task
 	for _, user := range t.Users.AsArray() {
 		_ = f.fillUser(user, true)
 		f.Debugf("%s is already assigned", user.MarkdownWithSkills())
 	}
+	f.preassignVolunteers()
 	f.trimRequire()
 
 	// create pools for all required needs
 	for _, need := range f.require.AsArray() {
-		qualified, _ := need.QualifyUsers(f.pool)
+		qualified, _ := need.QualifyUsers(f.pool, f.skillsAt)
 		f.requirePools[need.GetID()] = qualified
 	}
+	f.explainPool()
 
 	return &f
 }
 
+// explainPool adds the users that qualify for at least one of the required
+// needs to the explanation, with the probabilities of being picked; the rest
+// are disqualified.
+func (f *fill) explainPool() {
+	w := NewWeighted()
+	for _, user := range f.pool.AsArray() {
+		qualified := false
+		for _, pool := range f.requirePools {
+			if pool.Contains(user.MattermostUserID) {
+				qualified = true
+				break
+			}
+		}
+		if !qualified {
+			f.explanation.Disqualify(user.MattermostUserID, sl.DisqualifiedMissingSkill,
+				"does not qualify for "+f.require.String())
+			continue
+		}
+		w.Append(user.MattermostUserID, f.userWeight(user))
+	}
+	sort.Sort(w)
+	for i, id := range w.ids {
+		f.explanation.AddCandidate(id, w.weights[i], w.weights[i]/w.total)
+	}
+}
+
 func (f *fill) fill() (*sl.Users, error) {
 	f.Debugf(f.markdown())
 
+	// The greedy fill may hit a dead end, where an earlier pick made the
+	// remaining needs impossible to meet within the limits. Save the initial
+	// state to search for an assignment with backtracking in that case.
+	pool, onTask, require, limit := f.pool.Clone(), f.onTask(), f.require.Clone(), f.limit.Clone()
+	npicks, ndisqualified := len(f.explanation.Picks), len(f.explanation.Disqualified)
+
+	err := f.fillGreedy()
+	if err == nil {
+		f.Debugf("filled %s for %s", f.filled.MarkdownWithSkills(), f.task.Markdown())
+		return f.filled, nil
+	}
+	if err.Err != sl.ErrFillInsufficient {
+		return nil, err
+	}
+
+	f.Debugf("greedy fill failed: %v, backtracking", err)
+	picks, ok := f.backtrack(pool, onTask, require, limit)
+	if !ok {
+		return nil, err
+	}
+
+	// Replay the found assignment from the initial state.
+	f.pool, f.require, f.limit = pool, require, limit
+	f.filled = f.volunteered.Clone()
+	f.explanation.Picks = f.explanation.Picks[:npicks]
+	f.explanation.Disqualified = f.explanation.Disqualified[:ndisqualified]
+	for _, pick := range picks {
+		f.fillUser(pick.user, false)
+		f.explanation.Pick(pick.need, pick.user.MattermostUserID)
+		f.Debugf("...picked %s for %s", pick.user.MarkdownWithSkills(), pick.need)
+	}
+	f.trimRequire()
+
+	f.Debugf("filled %s for %s", f.filled.MarkdownWithSkills(), f.task.Markdown())
+	return f.filled, nil
+}
+
+// fillGreedy picks the needs and the users for them at random, weighted, and
+// never revisits a pick.
+func (f *fill) fillGreedy() *sl.FillError {
 	for {
 		pickRequire := f.pickRequire
 		if pickRequire == nil {
@@ -110,38 +175,40 @@ func (f *fill) fill() (*sl.Users, error) {
 		for {
 			user := f.pickUser(f.requirePools[need.GetID()])
 			if user == nil {
-				return nil, f.newError(*need, sl.ErrFillInsufficient)
+				return f.newError(*need, sl.ErrFillInsufficient)
+			}
+
+			if f.violatesPairs(user) {
+				f.dropUser(user)
+				continue
 			}
 
 			// The picked user is either accepted, or declined based on Limit
 			// constraints, so remove it from the pools right away
 			violated := f.fillUser(user, false)
 			if !violated.IsEmpty() {
+				f.explanation.Disqualify(user.MattermostUserID, sl.DisqualifiedLimit, "would exceed the limit on "+violated.MarkdownSkillLevels())
 				f.Debugf("...skipped user %s: would exceed limits on %s", user.Markdown(), violated.Markdown())
 				continue
 			}
+			f.explanation.Pick(*need, user.MattermostUserID)
 			f.Debugf("...picked %s for %s", user.MarkdownWithSkills(), need)
 			break
 		}
 	}
-
-	f.Debugf("filled %s for %s", f.filled.MarkdownWithSkills(), f.task.Markdown())
-	return f.filled, nil
+	return nil
 }
 
 func (f *fill) fillUser(user *sl.User, preassigned bool) (violated *sl.Needs) {
 	// The picked user is either accepted, or declined based on Limit
 	// constraints, so remove it from the pools right away
-	f.pool.Delete(user.MattermostUserID)
-	for _, pool := range f.requirePools {
-		pool.Delete(user.MattermostUserID)
-	}
+	f.dropUser(user)
 
-	updatedLimit, _, violated := f.limit.CheckLimits(user)
+	updatedLimit, _, violated := f.limit.CheckLimits(user, f.skillsAt)
 	if !preassigned && !violated.IsEmpty() {
 		return violated
 	}
-	updatedRequire := f.require.CheckRequired(user)
+	updatedRequire := f.require.CheckRequired(user, f.skillsAt)
 
 	f.limit = updatedLimit
 	f.require = updatedRequire
@@ -151,6 +218,48 @@ func (f *fill) fillUser(user *sl.User, preassigned bool) (violated *sl.Needs) {
 	return violated
 }
 
+// preassignVolunteers fills the task with the volunteers, first come, first
+// served, if the rotation says so.
+func (f *fill) preassignVolunteers() {
+	sl.PreassignVolunteers(f.r, f.task, f.pool, f.onTask(), f.require, f.limit, f.skillsAt, func(user *sl.User, need sl.Need) {
+		f.fillUser(user, false)
+		f.volunteered.Set(user)
+		f.explanation.Pick(need, user.MattermostUserID)
+		f.Debugf("...picked volunteer %s for %s", user.MarkdownWithSkills(), need)
+	})
+}
+
+func (f *fill) dropUser(user *sl.User) {
+	f.pool.Delete(user.MattermostUserID)
+	for _, pool := range f.requirePools {
+		pool.Delete(user.MattermostUserID)
+	}
+}
+
+// violatesPairs checks if the user can join the users already on the task
+// without violating the rotation's pair rules, and explains if not.
+func (f *fill) violatesPairs(user *sl.User) bool {
+	violated := f.r.TaskSettings.Pairs.Violated(f.onTask(), user, f.skillsAt)
+	if len(violated) == 0 {
+		return false
+	}
+	for _, rule := range violated {
+		f.violated = f.violated.With(rule)
+	}
+	f.explanation.Disqualify(user.MattermostUserID, sl.DisqualifiedPair, violated.Markdown(f.r.Users).String())
+	f.Debugf("...skipped user %s: would violate pair rules %s", user.Markdown(), violated.Markdown(f.r.Users))
+	return true
+}
+
+// onTask returns the users already assigned to the task, and the ones filled
+// so far.
+func (f *fill) onTask() *sl.Users {
+	if f.task.Users == nil {
+		return f.filled.Clone()
+	}
+	return f.task.Users.Join(f.filled)
+}
+
 func (f *fill) markdown() string {
 	w := NewWeighted()
 	for _, user := range f.pool.AsArray() {
@@ -182,9 +291,10 @@ func (f *fill) newError(need sl.Need, err error) *sl.FillError {
 		unmet.Set(need)
 	}
 	return &sl.FillError{
-		Err:        err,
-		UnmetNeeds: unmet,
-		FailedNeed: &need,
-		TaskID:     f.task.TaskID,
+		Err:           err,
+		UnmetNeeds:    unmet,
+		FailedNeed:    &need,
+		TaskID:        f.task.TaskID,
+		ViolatedPairs: f.violated,
 	}
 }
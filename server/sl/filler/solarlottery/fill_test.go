@@ -5,6 +5,7 @@ package solarlottery
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -28,6 +29,7 @@ func TestFill(t *testing.T) {
 		name                       string
 		require                    *sl.Needs
 		limit                      *sl.Needs
+		pairs                      sl.PairRules
 		pool                       *sl.Users
 		assigned                   *sl.Users
 		time                       types.Time
@@ -35,6 +37,7 @@ func TestFill(t *testing.T) {
 		expectFillError            error
 		expectFailedNeed           sl.Need
 		expectUnmetNeeds           *sl.Needs
+		expectViolatedPairs        sl.PairRules
 		skipSucessResultValidation bool
 		expectFilled               *sl.Users
 		expectPool                 *sl.Users
@@ -76,6 +79,58 @@ func TestFill(t *testing.T) {
 			),
 			expectPool: sl.NewUsers(),
 		},
+		{
+			name:    "backtrack out of a dead end",
+			require: sl.NewNeeds(test.C1WebappL3(), test.C1ServerL3()),
+			limit:   sl.NewNeeds(test.C1ServerL1()),
+			pool: sl.NewUsers(
+				// the likely pick for webapp, but then no one can meet the
+				// server need without exceeding the limit.
+				test.UserWebapp1().WithLastServed(test.RotationID, longTimeAgo),
+				test.UserServer1().WithLastServed(test.RotationID, recently),
+				test.UserGuru().WithLastServed(test.RotationID, recently),
+			),
+			expectFilled: sl.NewUsers(test.UserGuru().WithLastServed(test.RotationID, recently)),
+			expectPool: sl.NewUsers(
+				test.UserWebapp1().WithLastServed(test.RotationID, longTimeAgo),
+				test.UserServer1().WithLastServed(test.RotationID, recently),
+			),
+		},
+		{
+			name:    "Err no valid assignment",
+			require: sl.NewNeeds(test.C1WebappL3(), test.C1ServerL3()),
+			limit:   sl.NewNeeds(test.C1ServerL1()),
+			pool: sl.NewUsers(
+				test.UserWebapp1(),
+				test.UserServer1(),
+			),
+			expectError: true,
+		},
+		{
+			name:    "mentee picked with a mentor",
+			require: sl.NewNeeds(test.C2WebappL1()),
+			pairs:   sl.PairRules{sl.NewAccompaniedBy(test.WebappL1(), test.WebappL3())},
+			pool: sl.NewUsers(
+				// picked first, but can not be placed until a mentor is
+				test.UserMobile1().WithLastServed(test.RotationID, longTimeAgo),
+				test.UserWebapp1().WithLastServed(test.RotationID, recently),
+			),
+			expectFilled: sl.NewUsers(
+				test.UserMobile1().WithLastServed(test.RotationID, longTimeAgo),
+				test.UserWebapp1().WithLastServed(test.RotationID, recently),
+			),
+			expectPool: sl.NewUsers(),
+		},
+		{
+			name:                "Err never together",
+			require:             sl.NewNeeds(test.C2ServerL3()),
+			pairs:               sl.PairRules{sl.NewNeverTogether(test.UserIDServer1, test.UserIDServer2)},
+			pool:                sl.NewUsers(test.UserServer1(), test.UserServer2(), test.UserMobile1()),
+			expectFillError:     sl.ErrFillInsufficient,
+			expectUnmetNeeds:    sl.NewNeeds(test.C1ServerL3()),
+			expectFailedNeed:    test.C1ServerL3(),
+			expectViolatedPairs: sl.PairRules{sl.NewNeverTogether(test.UserIDServer1, test.UserIDServer2)},
+		},
 		{
 			name:             "Err Insufficient simple",
 			require:          sl.NewNeeds(test.C2MobileL1()),
@@ -115,6 +170,7 @@ func TestFill(t *testing.T) {
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			f := makeTestFiller(t, tc.pool, tc.assigned, tc.require, tc.limit)
+			f.r.TaskSettings.Pairs = tc.pairs
 			filled, err := f.fill()
 			if !tc.expectError && tc.expectFillError == nil {
 				require.NoError(t, err)
@@ -137,10 +193,35 @@ func TestFill(t *testing.T) {
 			require.Equal(t, tc.expectFillError, ferr.Err)
 			require.Equal(t, tc.expectUnmetNeeds.AsArray(), ferr.UnmetNeeds.AsArray())
 			require.EqualValues(t, &tc.expectFailedNeed, ferr.FailedNeed)
+			require.Equal(t, tc.expectViolatedPairs, ferr.ViolatedPairs)
 		})
 	}
 }
 
+func TestFillWorkload(t *testing.T) {
+	busy := test.UserServer1().WithLastServed(test.RotationID, longTimeAgo)
+	busy.AddUnavailable(&sl.Unavailable{
+		Reason:     sl.ReasonTask,
+		Interval:   types.MustParseInterval("2020-02-03", "2020-02-05"),
+		TaskID:     "other-rotation#1",
+		RotationID: "other-rotation",
+	})
+	idle := test.UserServer2().WithLastServed(test.RotationID, recently)
+
+	f := makeTestFiller(t, sl.NewUsers(busy, idle), nil, sl.NewNeeds(test.C1ServerL1()), nil)
+	f.r.TaskSettings.Workload = sl.Workload{MaxTasks: 1, Window: 7 * 24 * time.Hour}
+	f.task.ExpectedStart = types.MustParseTime("2020-02-06")
+	f.task.ExpectedDuration = 24 * time.Hour
+	f = newFill(f.r, f.task, types.MustParseTime("2020-03-01"), &bot.NilLogger{})
+
+	filled, err := f.fill()
+	require.NoError(t, err)
+	require.Equal(t, []string{test.UserIDServer2}, filled.TestIDs())
+	require.Len(t, f.explanation.Disqualified, 1)
+	require.Equal(t, types.ID(test.UserIDServer1), f.explanation.Disqualified[0].MattermostUserID)
+	require.Equal(t, sl.DisqualifiedWorkload, f.explanation.Disqualified[0].Reason)
+}
+
 func makeTestFiller(t testing.TB, pool, assigned *sl.Users, require, limit *sl.Needs) *fill {
 	if pool.IsEmpty() {
 		pool = sl.NewUsers()
@@ -79,6 +79,14 @@ func (f *fill) userWeight(user *sl.User) (w float64) {
 		return w
 	}
 	defer func() { f.poolWeights[user.MattermostUserID] = w }()
+	if f.offHours != nil && f.offHours.Contains(user.MattermostUserID) {
+		// The rotation prefers the users within their working hours, so
+		// this one is picked only if there is no one else.
+		return negligibleWeight
+	}
+	if f.r.VolunteerPolicy() == sl.VolunteerWeight && f.task.IsVolunteer(user.MattermostUserID) {
+		defer func() { w *= sl.VolunteerWeightFactor }()
+	}
 
 	lastServed := user.LastServed.Get(f.r.RotationID)
 	if lastServed <= 0 {
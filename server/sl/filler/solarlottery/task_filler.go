@@ -19,7 +19,8 @@ func New() sl.TaskFiller {
 	return &taskFiller{}
 }
 
-func (*taskFiller) FillTask(r *sl.Rotation, task *sl.Task, now types.Time, logger bot.Logger) (*sl.Users, error) {
+func (*taskFiller) FillTask(r *sl.Rotation, task *sl.Task, now types.Time, logger bot.Logger) (*sl.Users, *sl.FillExplanation, error) {
 	f := newFill(r, task, now, logger)
-	return f.fill()
+	filled, err := f.fill()
+	return filled, f.explanation, err
 }
@@ -0,0 +1,193 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// The policies for the rotation's shifts that fall on the holidays.
+const (
+	HolidaySkip = types.ID("skip")
+	HolidayMark = types.ID("mark")
+)
+
+var HolidayPolicies = types.NewIDSet(HolidaySkip, HolidayMark)
+
+// Holiday is a day off, or any other interval, in a holiday calendar.
+type Holiday struct {
+	types.Interval
+	Summary string
+
+	// UID is the event's UID in the imported calendar, used to update it
+	// when the calendar is imported again.
+	UID string `json:",omitempty"`
+}
+
+func (h *Holiday) Markdown() md.MD {
+	return md.Markdownf("**%s** (%v to %v)", h.Summary, h.Start, h.Finish)
+}
+
+// HolidayCalendar is a named list of holidays, e.g. the public holidays in a
+// country. Rotations use it to skip, or mark the shifts on the holidays, and
+// users to be unavailable on their regional holidays.
+type HolidayCalendar struct {
+	PluginVersion string
+	CalendarID    types.ID
+	Holidays      []*Holiday `json:",omitempty"` // Sorted by start date.
+}
+
+func NewHolidayCalendar(calendarID types.ID) *HolidayCalendar {
+	return &HolidayCalendar{
+		CalendarID: calendarID,
+		Holidays:   []*Holiday{},
+	}
+}
+
+// Find returns the holidays that overlap interval.
+func (c *HolidayCalendar) Find(interval types.Interval) []*Holiday {
+	if c == nil {
+		return nil
+	}
+	var found []*Holiday
+	for _, h := range c.Holidays {
+		if h.Overlaps(interval) {
+			found = append(found, h)
+		}
+	}
+	return found
+}
+
+// Merge adds the holidays to the calendar, replacing the ones with the same
+// UID or, for the holidays without one, with the same start and summary.
+// It returns the number of the holidays that were added, and updated.
+func (c *HolidayCalendar) Merge(holidays []*Holiday) (added, updated int) {
+HOLIDAYS:
+	for _, h := range holidays {
+		for i, existing := range c.Holidays {
+			if (h.UID != "" && existing.UID == h.UID) ||
+				(h.UID == "" && existing.UID == "" && existing.Start.Equal(h.Start.Time) && existing.Summary == h.Summary) {
+				c.Holidays[i] = h
+				updated++
+				continue HOLIDAYS
+			}
+		}
+		c.Holidays = append(c.Holidays, h)
+		added++
+	}
+	sort.SliceStable(c.Holidays, func(i, j int) bool {
+		return c.Holidays[i].Start.Before(c.Holidays[j].Start.Time)
+	})
+	return added, updated
+}
+
+// Remove deletes the holidays that overlap interval, and returns them.
+func (c *HolidayCalendar) Remove(interval types.Interval) []*Holiday {
+	var removed, kept []*Holiday
+	for _, h := range c.Holidays {
+		if h.Overlaps(interval) {
+			removed = append(removed, h)
+		} else {
+			kept = append(kept, h)
+		}
+	}
+	c.Holidays = kept
+	return removed
+}
+
+func (c *HolidayCalendar) Markdown() md.MD {
+	return md.Markdownf("%s", c.CalendarID)
+}
+
+func (c *HolidayCalendar) MarkdownBullets() md.MD {
+	out := md.Markdownf("- **%s**: %v holidays\n", c.CalendarID, len(c.Holidays))
+	for _, h := range c.Holidays {
+		out += md.Markdownf("  - %s\n", h.Markdown())
+	}
+	return out
+}
+
+// HolidayPolicy returns the rotation's policy for the shifts on holidays,
+// skip by default.
+func (r *Rotation) HolidayPolicy() types.ID {
+	if r.TaskSettings.HolidayPolicy == "" {
+		return HolidaySkip
+	}
+	return r.TaskSettings.HolidayPolicy
+}
+
+// findHoliday returns the first of the rotation's holidays that overlaps
+// interval, or nil.
+func (r *Rotation) findHoliday(interval types.Interval) *Holiday {
+	found := r.holidays.Find(interval)
+	if len(found) == 0 {
+		return nil
+	}
+	return found[0]
+}
+
+// findHolidays returns the user's regional holidays that overlap interval, as
+// unavailability events.
+func (user *User) findHolidays(interval types.Interval) []*Unavailable {
+	var found []*Unavailable
+	for _, h := range user.holidays.Find(interval) {
+		found = append(found, NewUnavailable(ReasonHoliday, h.Interval))
+	}
+	return found
+}
+
+// loadHolidayCalendar returns the calendar, or an empty one if it does not
+// exist. The calendars are cached for the duration of the request.
+func (sl *sl) loadHolidayCalendar(calendarID types.ID) (*HolidayCalendar, error) {
+	if c, ok := sl.holidayCalendars[calendarID]; ok {
+		return c, nil
+	}
+	c := NewHolidayCalendar(calendarID)
+	err := sl.Store.Entity(KeyHolidayCalendar).Load(calendarID, c)
+	if err != nil && err != kvstore.ErrNotFound {
+		return nil, errors.Wrapf(err, "failed to load holiday calendar %s", calendarID)
+	}
+	sl.cacheHolidayCalendar(c)
+	return c, nil
+}
+
+func (sl *sl) storeHolidayCalendar(c *HolidayCalendar) error {
+	c.PluginVersion = sl.conf.PluginVersion
+	err := sl.Store.Entity(KeyHolidayCalendar).Store(c.CalendarID, c)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store holiday calendar %s", c.CalendarID)
+	}
+	_, err = sl.Store.IDIndex(KeyHolidayCalendars).Set(c.CalendarID)
+	if err != nil {
+		return err
+	}
+	sl.cacheHolidayCalendar(c)
+	return nil
+}
+
+func (sl *sl) cacheHolidayCalendar(c *HolidayCalendar) {
+	if sl.holidayCalendars == nil {
+		sl.holidayCalendars = map[types.ID]*HolidayCalendar{}
+	}
+	sl.holidayCalendars[c.CalendarID] = c
+}
+
+func (sl *sl) expandRotationHolidays(r *Rotation) error {
+	if r.TaskSettings.Holidays == "" {
+		r.holidays = nil
+		return nil
+	}
+	c, err := sl.loadHolidayCalendar(r.TaskSettings.Holidays)
+	if err != nil {
+		return err
+	}
+	r.holidays = c
+	return nil
+}
@@ -5,6 +5,7 @@ package sl
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
@@ -36,11 +37,13 @@ func (need Need) Markdown() md.MD {
 	return md.Markdownf("**%v** %s", need.Count(), need.SkillLevel())
 }
 
-func (need Need) QualifyUser(user *User) (bool, Need) {
+// QualifyUser checks the user's qualification at the time, see
+// Task.SkillsAt.
+func (need Need) QualifyUser(user *User, at time.Time) (bool, Need) {
 	skillLevel := need.SkillLevel()
 	skill := skillLevel.Skill
 	level := int64(skillLevel.Level)
-	ulevel := user.SkillLevels.Get(skill)
+	ulevel := user.SkillLevel(skill, at)
 
 	if ulevel >= level {
 		need.Value--
@@ -49,10 +52,10 @@ func (need Need) QualifyUser(user *User) (bool, Need) {
 	return false, need
 }
 
-func (need Need) QualifyUsers(users *Users) (*Users, Need) {
+func (need Need) QualifyUsers(users *Users, at time.Time) (*Users, Need) {
 	qualified := NewUsers()
 	for _, user := range users.AsArray() {
-		isQualified, adj := need.QualifyUser(user)
+		isQualified, adj := need.QualifyUser(user, at)
 		if !isQualified {
 			continue
 		}
@@ -6,6 +6,7 @@ package sl
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
@@ -85,20 +86,20 @@ func (needs Needs) AsArray() []Need {
 	return a
 }
 
-func (needs Needs) Unmet(users *Users) *Needs {
+func (needs Needs) Unmet(users *Users, at time.Time) *Needs {
 	out := &needs
 	for _, user := range users.AsArray() {
-		out = out.CheckRequired(user)
+		out = out.CheckRequired(user, at)
 	}
 	return out
 }
 
-func (needs *Needs) CheckLimits(user *User) (adjusted, modified, violated *Needs) {
+func (needs *Needs) CheckLimits(user *User, at time.Time) (adjusted, modified, violated *Needs) {
 	violated = NewNeeds()
 	modified = NewNeeds()
 	adjusted = NewNeeds()
 	for _, need := range needs.AsArray() {
-		qualified, adjustedNeed := need.QualifyUser(user)
+		qualified, adjustedNeed := need.QualifyUser(user, at)
 		if !qualified {
 			adjusted.Set(need)
 			continue
@@ -113,10 +114,35 @@ func (needs *Needs) CheckLimits(user *User) (adjusted, modified, violated *Needs
 	return adjusted, modified, violated
 }
 
-func (require *Needs) CheckRequired(user *User) (adjusted *Needs) {
+// FirstUnmet returns the first need that is not yet met, and that the user
+// qualifies for. The specific skills come before "any".
+func (needs *Needs) FirstUnmet(user *User, at time.Time) (Need, bool) {
+	var found *Need
+	for _, need := range needs.AsArray() {
+		if need.Count() <= 0 {
+			continue
+		}
+		if ok, _ := need.QualifyUser(user, at); !ok {
+			continue
+		}
+		if need.SkillLevel().Skill != AnySkill {
+			return need, true
+		}
+		if found == nil {
+			n := need
+			found = &n
+		}
+	}
+	if found == nil {
+		return Need{}, false
+	}
+	return *found, true
+}
+
+func (require *Needs) CheckRequired(user *User, at time.Time) (adjusted *Needs) {
 	adjusted = NewNeeds()
 	for _, need := range require.AsArray() {
-		qualified, adjustedNeed := need.QualifyUser(user)
+		qualified, adjustedNeed := need.QualifyUser(user, at)
 		if !qualified {
 			adjusted.Set(need)
 			continue
@@ -0,0 +1,163 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+const (
+	// PairNeverTogether keeps the users from serving on the same task.
+	PairNeverTogether = types.ID("never-together")
+
+	// PairAccompaniedBy places a mentee on a task only if a mentor is
+	// already on it.
+	PairAccompaniedBy = types.ID("accompanied-by")
+)
+
+// PairRule is a rotation-level constraint on which users may serve on a task
+// together.
+type PairRule struct {
+	Type types.ID
+
+	// MattermostUserIDs are the users that may not serve together, for
+	// PairNeverTogether.
+	MattermostUserIDs *types.IDSet `json:",omitempty"`
+
+	// For PairAccompaniedBy, a user with the Mentee skill at or below its level
+	// must be accompanied by a user with the Mentor skill at or above its
+	// level.
+	Mentee SkillLevel `json:",omitempty"`
+	Mentor SkillLevel `json:",omitempty"`
+}
+
+type PairRules []*PairRule
+
+func NewNeverTogether(mattermostUserIDs ...types.ID) *PairRule {
+	return &PairRule{
+		Type:              PairNeverTogether,
+		MattermostUserIDs: types.NewIDSet(mattermostUserIDs...),
+	}
+}
+
+func NewAccompaniedBy(mentee, mentor SkillLevel) *PairRule {
+	return &PairRule{
+		Type:   PairAccompaniedBy,
+		Mentee: mentee,
+		Mentor: mentor,
+	}
+}
+
+// Violated returns the rules that user violates on a task with users. users
+// may include user. The skills are checked at the time, see Task.SkillsAt.
+func (rules PairRules) Violated(users *Users, user *User, at time.Time) PairRules {
+	var violated PairRules
+	for _, rule := range rules {
+		if !rule.Allows(users, user, at) {
+			violated = append(violated, rule)
+		}
+	}
+	return violated
+}
+
+func (rule *PairRule) Allows(users *Users, user *User, at time.Time) bool {
+	switch rule.Type {
+	case PairNeverTogether:
+		if !rule.MattermostUserIDs.Contains(user.MattermostUserID) {
+			return true
+		}
+		for _, other := range users.AsArray() {
+			if other.MattermostUserID != user.MattermostUserID &&
+				rule.MattermostUserIDs.Contains(other.MattermostUserID) {
+				return false
+			}
+		}
+		return true
+
+	case PairAccompaniedBy:
+		if !rule.isMentee(user, at) {
+			return true
+		}
+		for _, other := range users.AsArray() {
+			if other.MattermostUserID != user.MattermostUserID && rule.isMentor(other, at) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (rule *PairRule) isMentee(user *User, at time.Time) bool {
+	level := Level(user.SkillLevel(rule.Mentee.Skill, at))
+	return level > AnyLevel && level <= rule.Mentee.Level && !rule.isMentor(user, at)
+}
+
+func (rule *PairRule) isMentor(user *User, at time.Time) bool {
+	return Level(user.SkillLevel(rule.Mentor.Skill, at)) >= rule.Mentor.Level
+}
+
+// Markdown uses users, if available, to display the user names.
+func (rule *PairRule) Markdown(users *Users) md.MD {
+	switch rule.Type {
+	case PairNeverTogether:
+		names := []string{}
+		for _, id := range rule.MattermostUserIDs.IDs() {
+			if users != nil && users.Contains(id) {
+				names = append(names, users.Get(id).Markdown().String())
+			} else {
+				names = append(names, NewUser(id).Markdown().String())
+			}
+		}
+		return md.Markdownf("never together: %s", strings.Join(names, ", "))
+	case PairAccompaniedBy:
+		return md.Markdownf("%s accompanied by %s", rule.Mentee, rule.Mentor)
+	}
+	return md.Markdownf("unknown rule %s", rule.Type)
+}
+
+func (rules PairRules) Markdown(users *Users) md.MD {
+	out := []string{}
+	for _, rule := range rules {
+		out = append(out, rule.Markdown(users).String())
+	}
+	return md.MD(strings.Join(out, "; "))
+}
+
+// With returns rules with rule appended, unless it is already in.
+func (rules PairRules) With(rule *PairRule) PairRules {
+	for _, r := range rules {
+		if r == rule {
+			return rules
+		}
+	}
+	return append(rules, rule)
+}
+
+// Matches is true if the rules are of the same type, for the same users or
+// the same mentee skill.
+func (rule *PairRule) Matches(other *PairRule) bool {
+	if rule.Type != other.Type {
+		return false
+	}
+	switch rule.Type {
+	case PairNeverTogether:
+		if rule.MattermostUserIDs.Len() != other.MattermostUserIDs.Len() {
+			return false
+		}
+		for _, id := range other.MattermostUserIDs.IDs() {
+			if !rule.MattermostUserIDs.Contains(id) {
+				return false
+			}
+		}
+		return true
+	case PairAccompaniedBy:
+		return rule.Mentee == other.Mentee
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestPairRuleAllows(t *testing.T) {
+	skilled := func(id types.ID, level Level) *User {
+		skills := types.NewIntSet()
+		skills.Set("server", int64(level))
+		return NewUser(id).WithSkills(skills)
+	}
+	beginner := skilled("beginner", BeginnerLevel)
+	intermediate := skilled("intermediate", IntermediateLevel)
+	expert := skilled("expert", ExpertLevel)
+	none := NewUser("none")
+	now := time.Now()
+
+	t.Run("never together", func(t *testing.T) {
+		rule := NewNeverTogether("beginner", "expert")
+		require.True(t, rule.Allows(NewUsers(beginner, intermediate), beginner, now))
+		require.False(t, rule.Allows(NewUsers(beginner, expert), beginner, now))
+		require.False(t, rule.Allows(NewUsers(beginner, expert), expert, now))
+		require.True(t, rule.Allows(NewUsers(beginner, expert, intermediate), intermediate, now))
+	})
+
+	t.Run("accompanied by", func(t *testing.T) {
+		rule := NewAccompaniedBy(NewSkillLevel("server", BeginnerLevel), NewSkillLevel("server", ExpertLevel))
+		require.False(t, rule.Allows(NewUsers(), beginner, now))
+		require.False(t, rule.Allows(NewUsers(beginner, intermediate), beginner, now))
+		require.True(t, rule.Allows(NewUsers(beginner, expert), beginner, now))
+		require.True(t, rule.Allows(NewUsers(), intermediate, now))
+		require.True(t, rule.Allows(NewUsers(), expert, now))
+		require.True(t, rule.Allows(NewUsers(), none, now))
+	})
+}
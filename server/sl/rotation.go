@@ -26,22 +26,43 @@ type Rotation struct {
 	MattermostUserIDs *types.IDSet `json:",omitempty"`
 	TaskIDs           *types.IDSet `json:",omitempty"`
 
+	// TraineeMattermostUserIDs is the trainee pool, the users who shadow the
+	// rotation's tasks without being in the rotation.
+	TraineeMattermostUserIDs *types.IDSet `json:",omitempty"`
+
 	TaskSettings      TaskSettings      `json:",omitempty"`
 	FillSettings      FillSettings      `json:",omitempty"`
 	AutopilotSettings AutopilotSettings `json:",omitempty"`
+	TraineeSettings   TraineeSettings   `json:",omitempty"`
+
+	loaded   bool
+	Users    *Users `json:"-"`
+	Trainees *Users `json:"-"`
+	Tasks    *Tasks `json:"-"`
+
+	// defaultWorkload is the plugin-wide workload cap.
+	defaultWorkload Workload
 
-	loaded bool
-	Users  *Users `json:"-"`
-	Tasks  *Tasks `json:"-"`
+	// holidays is the rotation's holiday calendar, if any.
+	holidays *HolidayCalendar
 }
 
 type TaskSettings struct {
 	Seq         int           `json:",omitempty"`
 	Require     *Needs        `json:",omitempty"`
 	Limit       *Needs        `json:",omitempty"`
+	Pairs       PairRules     `json:",omitempty"`
+	Workload    Workload      `json:",omitempty"`
+	Conflicts   types.ID      `json:",omitempty"`
 	Duration    time.Duration `json:",omitempty"`
 	Grace       time.Duration `json:",omitempty"`
 	Description string        `json:",omitempty"`
+
+	// Holidays is the holiday calendar for the rotation's shifts.
+	// HolidayPolicy is to skip creating the shifts on the holidays, or to
+	// mark them as holiday shifts.
+	Holidays      types.ID `json:",omitempty"`
+	HolidayPolicy types.ID `json:",omitempty"`
 }
 
 type FillSettings struct {
@@ -58,6 +79,19 @@ type FillSettings struct {
 	// Fuzz is the number of periods that gets added to the default doubling
 	// duration when calculating user weights.
 	Fuzz int64 `json:",omitempty"`
+
+	// WorkingHours is the policy for filling tasks outside of the users'
+	// working hours: ignore, prefer the users whose hours cover the task, or
+	// require it.
+	WorkingHours types.ID `json:",omitempty"`
+
+	// Volunteers is the policy for the users who volunteer for tasks: fill
+	// them first come, first served, or increase their weight.
+	Volunteers types.ID `json:",omitempty"`
+
+	// Queue is the persisted order of users for the queue filler. Users who
+	// serve are moved to the back.
+	Queue *types.IDSet `json:",omitempty"`
 }
 
 type AutopilotSettings struct {
@@ -70,6 +104,29 @@ type AutopilotSettings struct {
 	RemindStartPrior  time.Duration `json:",omitempty"`
 	RemindFinish      bool          `json:",omitempty"`
 	RemindFinishPrior time.Duration `json:",omitempty"`
+
+	// RemindExpiry reminds the users, and the lead, of the users'
+	// qualifications expiring within RemindExpiryPrior, which defaults to
+	// DefaultRemindExpiryPrior.
+	RemindExpiry      bool          `json:",omitempty"`
+	RemindExpiryPrior time.Duration `json:",omitempty"`
+
+	// HistoryLimit is the number of runs to keep in the autopilot history.
+	// Defaults to DefaultAutopilotHistoryLimit.
+	HistoryLimit int `json:",omitempty"`
+}
+
+type TraineeSettings struct {
+	// Shadows is the number of trainees added to each filled task.
+	Shadows int `json:",omitempty"`
+
+	// GraduateAfter is the number of shadowed tasks after which the lead is
+	// prompted to qualify a trainee.
+	GraduateAfter int `json:",omitempty"`
+
+	// LeadMattermostUserID is the user to prompt when trainees graduate.
+	// Without a lead, the graduations are only logged.
+	LeadMattermostUserID types.ID `json:",omitempty"`
 }
 
 const (
@@ -90,6 +147,9 @@ func (r *Rotation) Init() {
 	if r.TaskIDs == nil {
 		r.TaskIDs = types.NewIDSet()
 	}
+	if r.TraineeMattermostUserIDs == nil {
+		r.TraineeMattermostUserIDs = types.NewIDSet()
+	}
 	if r.TaskSettings.Require == nil {
 		r.TaskSettings.Require = NewNeeds()
 	}
@@ -136,13 +196,41 @@ func (r *Rotation) MarkdownBullets() md.MD {
 	out += md.Markdownf("    - Task type: **%s**\n", r.TaskType)
 	out += md.Markdownf("    - Require: %s\n", r.TaskSettings.Require.Markdown())
 	out += md.Markdownf("    - Limit: %v\n", r.TaskSettings.Limit.Markdown())
+	if len(r.TaskSettings.Pairs) > 0 {
+		out += md.Markdownf("    - Pairs: %s\n", r.TaskSettings.Pairs.Markdown(r.Users))
+	}
+	if w := r.Workload(); !w.IsEmpty() {
+		out += md.Markdownf("    - Workload cap: %s\n", w)
+	}
 	out += md.Markdownf("    - Grace: **%v**\n", r.TaskSettings.Grace)
+	out += md.Markdownf("    - Conflicts with other rotations: **%s**\n", r.ConflictPolicy())
+	if r.TaskSettings.Holidays != "" {
+		out += md.Markdownf("    - Holidays: **%s**, %s\n", r.TaskSettings.Holidays, r.HolidayPolicy())
+	}
 
 	out += md.Markdownf("  - Fill settings:\n")
 	out += md.Markdownf("    - Filler type: **%s**\n", r.FillerType)
 	out += md.Markdownf("    - Beginning: **%s**\n", r.FillSettings.Beginning)
 	out += md.Markdownf("    - Shift period: **%s**\n", r.FillSettings.Period)
 	out += md.Markdownf("    - Fuzz: **%v**\n", r.FillSettings.Fuzz)
+	out += md.Markdownf("    - Working hours: **%s**\n", r.WorkingHoursPolicy())
+	out += md.Markdownf("    - Volunteers: **%s**\n", r.VolunteerPolicy())
+	if r.FillSettings.Queue != nil && !r.FillSettings.Queue.IsEmpty() {
+		out += md.Markdownf("    - Queue: %s\n", r.FillSettings.Queue.IDs())
+	}
+
+	if !r.TraineeMattermostUserIDs.IsEmpty() || r.TraineeSettings.Shadows > 0 {
+		out += md.Markdownf("  - Trainees:\n")
+		if r.Trainees != nil {
+			out += md.Markdownf("    - Pool (%v): %s\n", r.TraineeMattermostUserIDs.Len(), r.Trainees.MarkdownWithSkills())
+		} else {
+			out += md.Markdownf("    - Pool (%v): %s\n", r.TraineeMattermostUserIDs.Len(), r.TraineeMattermostUserIDs.IDs())
+		}
+		out += md.Markdownf("    - Shadows per task: **%v**\n", r.TraineeSettings.Shadows)
+		if r.TraineeSettings.GraduateAfter > 0 {
+			out += md.Markdownf("    - Graduate after **%v** tasks\n", r.TraineeSettings.GraduateAfter)
+		}
+	}
 
 	if r.AutopilotSettings.isOn() {
 		out += md.Markdownf("  - Autopilot: **on**\n")
@@ -162,6 +250,16 @@ func (r *Rotation) MarkdownBullets() md.MD {
 		if r.AutopilotSettings.RemindFinish {
 			out += md.Markdownf("    - Remind task users **%v** prior to finish\n", r.AutopilotSettings.RemindFinishPrior)
 		}
+		if r.AutopilotSettings.RemindExpiry {
+			prior := r.AutopilotSettings.RemindExpiryPrior
+			if prior == 0 {
+				prior = DefaultRemindExpiryPrior
+			}
+			out += md.Markdownf("    - Remind users, and the lead, **%v** prior to qualifications expiring\n", prior)
+		}
+		if r.AutopilotSettings.HistoryLimit > 0 {
+			out += md.Markdownf("    - Keep the last **%v** runs in the history\n", r.AutopilotSettings.HistoryLimit)
+		}
 	} else {
 		out += md.Markdownf("  - Autopilot: **off**\n")
 	}
@@ -231,9 +329,32 @@ func (r *Rotation) makeShift(shiftNumber int, now types.Time) (*Task, error) {
 		t.ExpectedDuration = nextTime.Sub(startTime.Time)
 	}
 
+	if h := r.findHoliday(t.Interval()); h != nil {
+		if r.HolidayPolicy() == HolidaySkip {
+			return nil, errors.Errorf(
+				"failed to make shift #%v (%v to %v): it falls on holiday %s",
+				shiftNumber, startTime, nextTime, h.Markdown())
+		}
+		t.Holiday = h.Summary
+	}
+
 	return t, nil
 }
 
+// skippedHoliday returns the holiday the shift falls on, if the rotation
+// skips the shifts on holidays.
+func (r *Rotation) skippedHoliday(shiftNumber int) *Holiday {
+	if r.HolidayPolicy() != HolidaySkip {
+		return nil
+	}
+	startTime := r.FillSettings.Period.ForNumber(r.FillSettings.Beginning, shiftNumber)
+	duration := r.TaskSettings.Duration
+	if duration == 0 {
+		duration = r.FillSettings.Period.ForNumber(startTime, 1).Sub(startTime.Time)
+	}
+	return r.findHoliday(types.NewDurationInterval(startTime, duration))
+}
+
 func (r *Rotation) queryTasks(finclude func(*Task, types.Time) bool, now types.Time) *Tasks {
 	tasks := NewTasks()
 	for _, t := range r.Tasks.AsArray() {
@@ -280,9 +401,9 @@ func (r *Rotation) allTasksForTime(t *Task, now types.Time) bool {
 
 func (r *Rotation) isAutopilotSchedule(t *Task, now types.Time) bool {
 	scheduleTime := t.ExpectedStart.Time.Add(-r.AutopilotSettings.SchedulePrior)
-	return t.State == TaskStatePending && !now.Before(scheduleTime)
+	return t.State == TaskStatePending && !t.Unscheduled && !now.Before(scheduleTime)
 }
 
 func (as AutopilotSettings) isOn() bool {
-	return as.Create || as.RemindFinish || as.RemindStart || as.Schedule || as.StartFinish
+	return as.Create || as.RemindFinish || as.RemindStart || as.Schedule || as.StartFinish || as.RemindExpiry
 }
@@ -0,0 +1,100 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// ServiceRecord is an entry in a user's service history. It is appended when a
+// task the user served is finished, and is never modified afterwards.
+type ServiceRecord struct {
+	RotationID types.ID
+	TaskID     types.ID
+	Start      types.Time
+	Finish     types.Time
+	Hours      float64
+}
+
+// NewServiceRecord makes the record of a finished task. A task finished
+// without starting is recorded with zero hours, at the time it was finished.
+func NewServiceRecord(t *Task) *ServiceRecord {
+	start := t.ActualStart
+	finish := t.ActualFinish
+	if start.IsZero() || start.After(finish.Time) {
+		start = finish
+	}
+	return &ServiceRecord{
+		RotationID: t.RotationID,
+		TaskID:     t.TaskID,
+		Start:      start,
+		Finish:     finish,
+		Hours:      finish.Sub(start.Time).Hours(),
+	}
+}
+
+// ServiceHistory is the append-only ledger of a user's served tasks, sorted by
+// the time they were finished.
+type ServiceHistory struct {
+	PluginVersion    string `json:",omitempty"`
+	MattermostUserID types.ID
+	Records          []*ServiceRecord `json:",omitempty"`
+}
+
+func NewServiceHistory(mattermostUserID types.ID) *ServiceHistory {
+	return &ServiceHistory{
+		MattermostUserID: mattermostUserID,
+		Records:          []*ServiceRecord{},
+	}
+}
+
+// Append adds a record to the history, unless a record for the same task
+// already exists.
+func (h *ServiceHistory) Append(rec *ServiceRecord) bool {
+	for _, existing := range h.Records {
+		if existing.TaskID == rec.TaskID {
+			return false
+		}
+	}
+	h.Records = append(h.Records, rec)
+	return true
+}
+
+// Filter returns the records for a rotation (or all if rotationID is empty)
+// that overlap with the interval. A zero Start or Finish leaves the interval
+// open on that end.
+func (h *ServiceHistory) Filter(rotationID types.ID, interval types.Interval) *ServiceHistory {
+	filtered := NewServiceHistory(h.MattermostUserID)
+	for _, rec := range h.Records {
+		if rotationID != "" && rec.RotationID != rotationID {
+			continue
+		}
+		if !interval.Start.IsZero() && rec.Finish.Before(interval.Start.Time) {
+			continue
+		}
+		if !interval.Finish.IsZero() && !rec.Start.Before(interval.Finish.Time) {
+			continue
+		}
+		filtered.Records = append(filtered.Records, rec)
+	}
+	return filtered
+}
+
+func (h *ServiceHistory) Hours() float64 {
+	hours := 0.0
+	for _, rec := range h.Records {
+		hours += rec.Hours
+	}
+	return hours
+}
+
+func (h *ServiceHistory) MarkdownBullets(user *User) md.MD {
+	out := md.Markdownf("- %s: served **%v** tasks, **%.1f** hours\n", user.Markdown(), len(h.Records), h.Hours())
+	for _, rec := range h.Records {
+		out += md.Markdownf("  - %s %s: %v to %v, %.1f hours\n",
+			rec.RotationID, rec.TaskID, user.Time(rec.Start), user.Time(rec.Finish), rec.Hours)
+	}
+	return out
+}
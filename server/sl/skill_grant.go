@@ -0,0 +1,122 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// DefaultRemindExpiryPrior is how long before a qualification expires the
+// user, and the rotation's lead, are reminded, unless the rotation says
+// otherwise.
+const DefaultRemindExpiryPrior = 14 * 24 * time.Hour
+
+// SkillGrant records who qualified a user for a skill, and when. A
+// qualification with an Expires time stops counting after it.
+type SkillGrant struct {
+	GrantedAt types.Time
+	GrantedBy types.ID   `json:",omitempty"`
+	Expires   types.Time `json:",omitempty"`
+
+	// Reminded is set once the user has been reminded of the expiry, and
+	// RemindedRotations lists the rotations whose leads have been. A
+	// recertification makes a new grant, clearing both.
+	Reminded          bool         `json:",omitempty"`
+	RemindedRotations *types.IDSet `json:",omitempty"`
+}
+
+func (g *SkillGrant) remindedLead(rotationID types.ID) bool {
+	return g.RemindedRotations != nil && g.RemindedRotations.Contains(rotationID)
+}
+
+func (g *SkillGrant) setRemindedLead(rotationID types.ID) {
+	if g.RemindedRotations == nil {
+		g.RemindedRotations = types.NewIDSet()
+	}
+	g.RemindedRotations.Set(rotationID)
+}
+
+func (g *SkillGrant) IsExpired(at time.Time) bool {
+	return g != nil && !g.Expires.IsZero() && !at.Before(g.Expires.Time)
+}
+
+// SkillLevel returns the user's level in skill at the time, 0 if the user is
+// not qualified, or if the qualification expires by then. The skills implied
+// by the user's other skills count, at the implied levels.
+func (user *User) SkillLevel(skill types.ID, at time.Time) int64 {
+	level := user.grantedSkillLevel(skill, at)
+	for _, s := range user.SkillLevels.IDs() {
+		if s == skill {
+			continue
+		}
+		implied := user.skillTree.ImpliedLevel(s, user.grantedSkillLevel(s, at), skill)
+		if implied > level {
+			level = implied
+		}
+	}
+	return level
+}
+
+func (user *User) grantedSkillLevel(skill types.ID, at time.Time) int64 {
+	if user.SkillGrants[skill].IsExpired(at) {
+		return 0
+	}
+	return user.SkillLevels.Get(skill)
+}
+
+// ExpiringSkill is a qualification that expires, or has expired, by the
+// audit time.
+type ExpiringSkill struct {
+	MattermostUserID types.ID
+	SkillLevel       SkillLevel
+	Grant            *SkillGrant
+}
+
+// FindExpiringSkills returns the qualifications of the users that expire
+// before the time, sorted by the expiry.
+func FindExpiringSkills(users *Users, before time.Time) []*ExpiringSkill {
+	expiring := []*ExpiringSkill{}
+	for _, user := range users.AsArray() {
+		for _, skill := range user.SkillLevels.IDs() {
+			grant := user.SkillGrants[skill]
+			if grant == nil || grant.Expires.IsZero() || !grant.Expires.Before(before) {
+				continue
+			}
+			expiring = append(expiring, &ExpiringSkill{
+				MattermostUserID: user.MattermostUserID,
+				SkillLevel:       NewSkillLevel(skill, Level(user.SkillLevels.Get(skill))),
+				Grant:            grant,
+			})
+		}
+	}
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].Grant.Expires.Before(expiring[j].Grant.Expires.Time)
+	})
+	return expiring
+}
+
+// Markdown uses users, if available, to display the user names, including
+// the ones who granted the qualifications.
+func (e *ExpiringSkill) Markdown(users *Users, now time.Time) md.MD {
+	get := func(id types.ID) *User {
+		if users != nil && users.Contains(id) {
+			return users.Get(id)
+		}
+		return NewUser(id)
+	}
+	user := get(e.MattermostUserID)
+	verb := "expires"
+	if e.Grant.IsExpired(now) {
+		verb = "expired"
+	}
+	out := md.Markdownf("%s %s %s %s", user.Markdown(), e.SkillLevel, verb, user.Time(e.Grant.Expires))
+	if e.Grant.GrantedBy != "" {
+		out += md.Markdownf(", granted by %s on %s", get(e.Grant.GrantedBy).Markdown(), user.Time(e.Grant.GrantedAt))
+	}
+	return out
+}
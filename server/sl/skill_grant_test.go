@@ -0,0 +1,42 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestSkillGrantExpiry(t *testing.T) {
+	user := NewUser("test-user")
+	user.SkillLevels.Set("web", 2)
+	user.SkillLevels.Set("server", 3)
+	user.SkillLevels.Set("mobile", 1)
+	user.SkillGrants = map[types.ID]*SkillGrant{
+		"web":    {GrantedAt: types.MustParseTime("2020-01-01"), Expires: types.MustParseTime("2020-02-01")},
+		"server": {GrantedAt: types.MustParseTime("2020-01-01"), Expires: types.MustParseTime("2099-01-01")},
+	}
+
+	at := types.MustParseTime("2020-03-01").Time
+	require.Equal(t, int64(0), user.SkillLevel("web", at))
+	require.Equal(t, int64(2), user.SkillLevel("web", types.MustParseTime("2020-01-15").Time))
+	require.Equal(t, int64(3), user.SkillLevel("server", at))
+	require.Equal(t, int64(1), user.SkillLevel("mobile", at))
+	require.Equal(t, int64(0), user.SkillLevel("unknown", at))
+
+	require.True(t, user.SkillGrants["web"].IsExpired(types.MustParseTime("2020-02-01").Time))
+	require.False(t, user.SkillGrants["web"].IsExpired(types.MustParseTime("2020-01-31").Time))
+
+	expiring := FindExpiringSkills(NewUsers(user), types.MustParseTime("2020-03-01").Time)
+	require.Len(t, expiring, 1)
+	require.Equal(t, NewSkillLevel("web", 2), expiring[0].SkillLevel)
+
+	expiring = FindExpiringSkills(NewUsers(user), types.MustParseTime("2100-01-01").Time)
+	require.Len(t, expiring, 2)
+	require.Equal(t, types.ID("web"), expiring[0].SkillLevel.Skill)
+	require.Equal(t, types.ID("server"), expiring[1].SkillLevel.Skill)
+}
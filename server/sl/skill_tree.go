@@ -0,0 +1,108 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// SkillParent makes a skill imply its Parent skill, LevelOffset levels
+// lower. E.g., with sre -> ops, offset 1, sre-3 implies ops-2. An implied
+// level below beginner is not implied.
+type SkillParent struct {
+	Parent      types.ID
+	LevelOffset int64 `json:",omitempty"`
+}
+
+// SkillTree maps the known skills to their parents. The skills without a
+// parent are the roots.
+type SkillTree map[types.ID]SkillParent
+
+// SetParent makes skill imply parent, unless that would create a cycle. An
+// empty parent removes the skill's parent.
+func (tree SkillTree) SetParent(skill, parent types.ID, levelOffset int64) error {
+	if parent == "" {
+		delete(tree, skill)
+		return nil
+	}
+	if levelOffset < 0 || levelOffset >= int64(ExpertLevel) {
+		return errors.Errorf("level offset must be 0-%v", int64(ExpertLevel)-1)
+	}
+	for p := parent; p != ""; p = tree[p].Parent {
+		if p == skill {
+			return errors.Errorf("%s already implies %s", parent, skill)
+		}
+	}
+	tree[skill] = SkillParent{
+		Parent:      parent,
+		LevelOffset: levelOffset,
+	}
+	return nil
+}
+
+// Delete removes skill from the tree; its children become roots.
+func (tree SkillTree) Delete(skill types.ID) {
+	delete(tree, skill)
+	for child, p := range tree {
+		if p.Parent == skill {
+			delete(tree, child)
+		}
+	}
+}
+
+// ImpliedLevel returns the level in skill implied by having level in from,
+// following the parents up the tree, or 0 if skill is not implied.
+func (tree SkillTree) ImpliedLevel(from types.ID, level int64, skill types.ID) int64 {
+	for s := from; level >= int64(BeginnerLevel); {
+		if s == skill {
+			return level
+		}
+		p, ok := tree[s]
+		if !ok {
+			break
+		}
+		s = p.Parent
+		level -= p.LevelOffset
+	}
+	return 0
+}
+
+func (tree SkillTree) children(known *types.IDSet) map[types.ID][]types.ID {
+	children := map[types.ID][]types.ID{}
+	for _, skill := range known.IDs() {
+		parent := tree[skill].Parent
+		if !known.Contains(parent) {
+			parent = ""
+		}
+		children[parent] = append(children[parent], skill)
+	}
+	for _, cc := range children {
+		sort.Slice(cc, func(i, j int) bool { return cc[i] < cc[j] })
+	}
+	return children
+}
+
+// MarkdownTree displays the known skills as a tree, with the implied levels.
+func (tree SkillTree) MarkdownTree(known *types.IDSet) md.MD {
+	children := tree.children(known)
+	out := md.MD("")
+	var add func(parent types.ID, indent string)
+	add = func(parent types.ID, indent string) {
+		for _, skill := range children[parent] {
+			out += md.Markdownf("%s- %s", indent, skill)
+			if p := tree[skill]; parent != "" && p.LevelOffset > 0 {
+				out += md.Markdownf(" (implies %s, %v level(s) lower)", parent, p.LevelOffset)
+			}
+			out += "\n"
+			add(skill, indent+"  ")
+		}
+	}
+	add("", "")
+	return out
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestSkillTree(t *testing.T) {
+	tree := SkillTree{}
+	require.NoError(t, tree.SetParent("sre", "ops", 1))
+	require.NoError(t, tree.SetParent("kubernetes", "sre", 0))
+	require.NoError(t, tree.SetParent("webapp", "web", 0))
+
+	require.EqualError(t, tree.SetParent("ops", "kubernetes", 0), "kubernetes already implies ops")
+	require.EqualError(t, tree.SetParent("ops", "ops", 0), "ops already implies ops")
+	require.Error(t, tree.SetParent("ops", "web", 4))
+
+	require.Equal(t, int64(4), tree.ImpliedLevel("kubernetes", 4, "sre"))
+	require.Equal(t, int64(3), tree.ImpliedLevel("kubernetes", 4, "ops"))
+	require.Equal(t, int64(0), tree.ImpliedLevel("kubernetes", 1, "ops"))
+	require.Equal(t, int64(0), tree.ImpliedLevel("ops", 4, "sre"))
+	require.Equal(t, int64(0), tree.ImpliedLevel("webapp", 4, "ops"))
+
+	user := NewUser("test-user")
+	user.SkillLevels.Set("kubernetes", 4)
+	user.SkillLevels.Set("ops", 1)
+	user.skillTree = tree
+	require.Equal(t, int64(4), user.SkillLevel("kubernetes", time.Now()))
+	require.Equal(t, int64(4), user.SkillLevel("sre", time.Now()))
+	require.Equal(t, int64(3), user.SkillLevel("ops", time.Now()))
+	require.Equal(t, int64(0), user.SkillLevel("web", time.Now()))
+
+	ok, _ := NewNeed(1, NewSkillLevel("ops", BeginnerLevel)).QualifyUser(user, time.Now())
+	require.True(t, ok)
+	ok, _ = NewNeed(1, NewSkillLevel("ops", ExpertLevel)).QualifyUser(user, time.Now())
+	require.False(t, ok)
+
+	require.Equal(t, ""+
+		"- mobile\n"+
+		"- ops\n"+
+		"  - sre (implies ops, 1 level(s) lower)\n"+
+		"    - kubernetes\n"+
+		"- web\n"+
+		"  - webapp\n",
+		tree.MarkdownTree(types.NewIDSet("ops", "sre", "kubernetes", "web", "webapp", "mobile")).String())
+
+	tree.Delete("sre")
+	require.Equal(t, int64(0), tree.ImpliedLevel("kubernetes", 4, "ops"))
+}
@@ -37,32 +37,43 @@ var ErrAlreadyExists = errors.New("already exists")
 type TaskService interface {
 	AssignTask(InAssignTask) (*OutAssignTask, error)
 	UnassignTask(InAssignTask) (*OutAssignTask, error)
-	FillTask(InAssignTask) (*OutAssignTask, error)
+	FillTask(InFillTask) (*OutFillTask, error)
 	LoadTask(types.ID) (*Task, error)
 	TransitionTask(params InTransitionTask) (*OutTransitionTask, error)
 	CreateTicket(InCreateTicket) (*OutCreateTask, error)
 	CreateShift(InCreateShift) (*OutCreateTask, error)
+	RequestSwap(InRequestSwap) (*OutSwap, error)
+	RespondSwap(InRespondSwap) (*OutSwap, error)
+	Volunteer(InVolunteer) (*OutAssignTask, error)
 }
 
 type SkillService interface {
 	ListKnownSkills() (*types.IDSet, error)
 	AddKnownSkill(types.ID) error
 	DeleteKnownSkill(types.ID) error
+	LoadSkillTree() (SkillTree, error)
+	SetSkillParent(skill, parent types.ID, levelOffset int64) error
+	AuditSkills(InAuditSkills) (*OutAuditSkills, error)
 }
 
 type UserService interface {
 	AddToCalendar(InAddToCalendar) (*OutCalendar, error)
 	ClearCalendar(InClearCalendar) (*OutCalendar, error)
+	ImportCalendar(InImportCalendar) (*OutCalendar, error)
 	Disqualify(InDisqualify) (*OutQualify, error)
 	JoinRotation(InJoinRotation) (*OutJoinRotation, error)
 	LeaveRotation(InJoinRotation) (*OutJoinRotation, error)
+	LoadServiceHistory(InServiceHistory) (*OutServiceHistory, error)
 	Qualify(InQualify) (*OutQualify, error)
+	SetUserHolidays(InSetUserHolidays) (*OutSetUserHolidays, error)
+	SetWorkingHours(InSetWorkingHours) (*OutSetWorkingHours, error)
 }
 
 type RotationService interface {
 	AddRotation(*Rotation) error
 	ArchiveRotation(rotationID types.ID) (*Rotation, error)
 	DebugDeleteRotation(rotationID types.ID) error
+	Forecast(InForecast) (*OutForecast, error)
 	LoadActiveRotations() (*types.IDSet, error)
 	LoadRotation(rotationID types.ID) (*Rotation, error)
 	MakeRotation(rotationName string) (*Rotation, error)
@@ -71,7 +82,26 @@ type RotationService interface {
 }
 
 type AutopilotService interface {
+	LoadAutopilotHistory(InAutopilotHistory) (*OutAutopilotHistory, error)
 	RunAutopilot(in *InRunAutopilot) (*OutRunAutopilot, error)
+	RunAutopilotAll(in *InRunAutopilotAll) (*OutRunAutopilotAll, error)
+}
+
+type HolidayService interface {
+	ImportHolidays(InImportHolidays) (*OutHolidays, error)
+	ListHolidayCalendars() (*types.IDSet, error)
+	LoadHolidayCalendar(calendarID types.ID) (*HolidayCalendar, error)
+	RemoveHolidays(InRemoveHolidays) (*OutHolidays, error)
+}
+
+type CalendarFeedService interface {
+	CalendarToken(InCalendarToken) (*OutCalendarToken, error)
+	ExportCalendar(InExportCalendar) (*OutExportCalendar, error)
+}
+
+type WebhookService interface {
+	ListWebhooks(rotationID types.ID) (*OutWebhooks, error)
+	SetWebhook(InSetWebhook) (*OutWebhooks, error)
 }
 
 type SL interface {
@@ -80,6 +110,9 @@ type SL interface {
 	UserService
 	TaskService
 	AutopilotService
+	HolidayService
+	CalendarFeedService
+	WebhookService
 
 	PluginAPI
 	bot.Logger
@@ -105,6 +138,12 @@ type sl struct {
 
 	// Stack of loggers
 	loggers []bot.Logger
+
+	// loaded on the first use, by loadSkillTree.
+	skillTree SkillTree
+
+	// loaded on the first use, by loadHolidayCalendar.
+	holidayCalendars map[types.ID]*HolidayCalendar
 }
 
 func (sl *sl) Config() *config.Config {
@@ -13,11 +13,11 @@ import (
 
 func (sl *sl) autopilotRemindFinish(r *Rotation, now types.Time) (md.Markdowner, error) {
 	if !r.AutopilotSettings.RemindFinish {
-		return md.MD("finish reminder: not configured"), nil
+		return autopilotIdle("finish reminder: not configured"), nil
 	}
 	filtered := r.queryTasks(r.isAutopilotRemindFinish, now)
 	if filtered.IsEmpty() {
-		return md.MD("finish reminder: nothing to do"), nil
+		return autopilotIdle("finish reminder: nothing to do"), nil
 	}
 
 	var notified = NewUsers()
@@ -39,11 +39,11 @@ func (sl *sl) autopilotRemindFinish(r *Rotation, now types.Time) (md.Markdowner,
 
 func (sl *sl) autopilotRemindStart(r *Rotation, now types.Time) (md.Markdowner, error) {
 	if !r.AutopilotSettings.RemindStart {
-		return md.MD("start reminder: not configured"), nil
+		return autopilotIdle("start reminder: not configured"), nil
 	}
 	filtered := r.queryTasks(r.isAutopilotRemindStart, now)
 	if filtered.IsEmpty() {
-		return md.MD("start reminder: nothing to do"), nil
+		return autopilotIdle("start reminder: nothing to do"), nil
 	}
 
 	var notified = NewUsers()
@@ -65,11 +65,11 @@ func (sl *sl) autopilotRemindStart(r *Rotation, now types.Time) (md.Markdowner,
 
 func (sl *sl) autopilotFinish(r *Rotation, now types.Time) (md.Markdowner, error) {
 	if !r.AutopilotSettings.StartFinish {
-		return md.MD("finish: not configured"), nil
+		return autopilotIdle("finish: not configured"), nil
 	}
 	filtered := r.queryTasks(r.isAutopilotFinish, now)
 	if filtered.IsEmpty() {
-		return md.MD("finish: nothing to do"), nil
+		return autopilotIdle("finish: nothing to do"), nil
 	}
 
 	for _, t := range filtered.AsArray() {
@@ -84,11 +84,11 @@ func (sl *sl) autopilotFinish(r *Rotation, now types.Time) (md.Markdowner, error
 
 func (sl *sl) autopilotStart(r *Rotation, now types.Time) (md.Markdowner, error) {
 	if !r.AutopilotSettings.StartFinish {
-		return md.MD("start: not configured"), nil
+		return autopilotIdle("start: not configured"), nil
 	}
 	filtered := r.queryTasks(r.isAutopilotStart, now)
 	if filtered.IsEmpty() {
-		return md.MD("start: nothing to do"), nil
+		return autopilotIdle("start: nothing to do"), nil
 	}
 
 	for _, t := range filtered.AsArray() {
@@ -103,16 +103,16 @@ func (sl *sl) autopilotStart(r *Rotation, now types.Time) (md.Markdowner, error)
 
 func (s *sl) autopilotFillSchedule(r *Rotation, now types.Time) (md.Markdowner, error) {
 	if !r.AutopilotSettings.Schedule {
-		return md.MD("fill and schedule: not configured"), nil
+		return autopilotIdle("fill and schedule: not configured"), nil
 	}
 	filtered := r.queryTasks(r.isAutopilotSchedule, now)
 	if filtered.IsEmpty() {
-		return md.MD("fill and schedule: nothing to do"), nil
+		return autopilotIdle("fill and schedule: nothing to do"), nil
 	}
 
 	var messages []string
 	for _, t := range filtered.AsArray() {
-		outFill, err := s.FillTask(InAssignTask{
+		outFill, err := s.FillTask(InFillTask{
 			TaskID: t.TaskID,
 			Time:   now,
 		})
@@ -138,13 +138,14 @@ func (s *sl) autopilotFillSchedule(r *Rotation, now types.Time) (md.Markdowner,
 
 func (sl *sl) autopilotCreate(r *Rotation, now types.Time) (md.Markdowner, error) {
 	if r.TaskType == TaskTypeTicket {
-		return md.MD("create shift: tickets can not be auto-created"), nil
+		return autopilotIdle("create shift: tickets can not be auto-created"), nil
 	}
 	if !r.AutopilotSettings.Create {
-		return md.MD("create shift: not configured"), nil
+		return autopilotIdle("create shift: not configured"), nil
 	}
 
 	var messages []md.Markdowner
+	skipped := 0
 	period := r.FillSettings.Period
 	upTo := now.Add(r.AutopilotSettings.CreatePrior)
 	num, start := period.ForTime(r.FillSettings.Beginning, now)
@@ -156,6 +157,11 @@ func (sl *sl) autopilotCreate(r *Rotation, now types.Time) (md.Markdowner, error
 		if !exists.IsEmpty() {
 			continue
 		}
+		if h := r.skippedHoliday(num); h != nil {
+			sl.Debugf("skipped shift #%v on holiday %s", num, h.Markdown())
+			skipped++
+			continue
+		}
 		out, err := sl.CreateShift(InCreateShift{
 			RotationID: r.RotationID,
 			Number:     num,
@@ -167,111 +173,95 @@ func (sl *sl) autopilotCreate(r *Rotation, now types.Time) (md.Markdowner, error
 		messages = append(messages, out)
 	}
 
-	if len(messages) == 0 {
-		return md.MD("create shift: nothing to do"), nil
+	if len(messages) == 0 && skipped == 0 {
+		return autopilotIdle("create shift: nothing to do"), nil
+	}
+	text := fmt.Sprintf("create shift: created %v shifts", len(messages))
+	if skipped > 0 {
+		text += fmt.Sprintf(", skipped %v on holidays", skipped)
+	}
+	if len(messages) > 0 {
+		text += ":\n"
 	}
-	text := fmt.Sprintf("create shift: created %v shifts:\n", len(messages))
 	for _, m := range messages {
 		text += "    - " + m.Markdown().String() + "\n"
 	}
 	return md.MD(strings.TrimSpace(text)), nil
 }
 
-// func (sl *sl) autopilotFill(rotation *Rotation, now time.Time, currentShiftNumber int, logger bot.Logger) ([]int, []*Shift, []Users, error) {
-// 	if !rotation.Autopilot.Fill {
-// 		return nil, nil, nil, errors.New("not configured to auto-fill")
-// 	}
-
-// 	startingShiftNumber := currentShiftNumber
-// 	if startingShiftNumber < 0 {
-// 		startingShiftNumber = 0
-// 	}
-
-// 	upToShiftNumber, err := rotation.ShiftNumberForTime(now.Add(rotation.Autopilot.FillPrior))
-// 	if err != nil {
-// 		return nil, nil, nil, err
-// 	}
-// 	if upToShiftNumber < startingShiftNumber {
-// 		return nil, nil, nil, errors.New("nothing to do")
-// 	}
-// 	numShifts := upToShiftNumber - startingShiftNumber + 1
-
-// 	return sl.fillShifts(rotation, startingShiftNumber, numShifts, now, logger)
-// }
-
-// func (sl *sl) fillShifts(rotation *Rotation, startingShiftNumber, numShifts int, now time.Time, logger bot.Logger) ([]int, []*Shift, []Users, error) {
-// 	// Guess' logs are too verbose - suppress
-// 	prevLogger := sl.Logger
-// 	sl.Logger = &bot.NilLogger{}
-// 	shifts, err := sl.Guess(rotation, startingShiftNumber, numShifts)
-// 	sl.Logger = prevLogger
-// 	if err != nil {
-// 		return nil, nil, nil, err
-// 	}
-// 	if len(shifts) != numShifts {
-// 		return nil, nil, nil, errors.New("unreachable, must match")
-// 	}
-
-// 	var filledShiftNumbers []int
-// 	var filledShifts []*Shift
-// 	var addedUsers []Users
-
-// 	appendShift := func(shiftNumber int, shift *Shift, added Users) {
-// 		filledShiftNumbers = append(filledShiftNumbers, shiftNumber)
-// 		filledShifts = append(filledShifts, shift)
-// 		addedUsers = append(addedUsers, added)
-// 	}
-
-// 	shiftNumber := startingShiftNumber - 1
-// 	for n := 0; n < numShifts; n++ {
-// 		shiftNumber++
-
-// 		loadedShift, err := sl.OpenShift(rotation, shiftNumber)
-// 		if err != nil && err != ErrAlreadyExists {
-// 			return nil, nil, nil, err
-// 		}
-// 		if loadedShift.Status != store.ShiftStatusOpen {
-// 			appendShift(shiftNumber, loadedShift, nil)
-// 			continue
-// 		}
-// 		if !loadedShift.Autopilot.Filled.IsZero() {
-// 			appendShift(shiftNumber, loadedShift, nil)
-// 			continue
-// 		}
-
-// 		before := rotation.ShiftUsers(loadedShift).Clone(false)
-
-// 		// shifts coming from Guess are either loaded with their respective
-// 		// status, or are Open. (in reality should always be Open).
-// 		shift := shifts[n]
-// 		added := NewUsers()
-// 		for id, user := range rotation.ShiftUsers(shift) {
-// 			if before[id] == nil {
-// 				added[id] = user
-// 			}
-// 		}
-// 		if len(added) == 0 {
-// 			appendShift(shiftNumber, loadedShift, nil)
-// 			continue
-// 		}
-
-// 		loadedShift.Autopilot.Filled = now
-
-// 		_, err = sl.joinShift(rotation, shiftNumber, loadedShift, added, true)
-// 		if err != nil {
-// 			return filledShiftNumbers, filledShifts, addedUsers,
-// 				errors.WithMessagef(err, "failed to join autofilled users to %s", loadedShift.Markdown())
-// 		}
-
-// 		err = sl.ShiftStore.StoreShift(rotation.RotationID, shiftNumber, loadedShift.Shift)
-// 		if err != nil {
-// 			return filledShiftNumbers, filledShifts, addedUsers,
-// 				errors.WithMessagef(err, "failed to store autofilled %s", loadedShift.Markdown())
-// 		}
+// autopilotRemindExpiry messages the rotation's users whose qualifications
+// are about to expire, and the rotation's lead. Each user is reminded of a
+// qualification once, and so is the lead of each of the user's rotations. It
+// reports only when there is something to do.
+func (sl *sl) autopilotRemindExpiry(r *Rotation, now types.Time) (md.Markdowner, error) {
+	if !r.AutopilotSettings.RemindExpiry {
+		return autopilotIdle(""), nil
+	}
+	prior := r.AutopilotSettings.RemindExpiryPrior
+	if prior == 0 {
+		prior = DefaultRemindExpiryPrior
+	}
+	leadID := r.TraineeSettings.LeadMattermostUserID
+
+	remindedUsers := 0
+	remindedLead := []*ExpiringSkill{}
+	for _, user := range r.Users.AsArray() {
+		// The earlier stages store the users they fill and schedule, reload
+		// the user so that storing the reminders does not undo them.
+		user, err := sl.loadUser(user.MattermostUserID)
+		if err != nil {
+			return nil, err
+		}
+		err = sl.expandUser(user)
+		if err != nil {
+			return nil, err
+		}
+		r.Users.Set(user)
+
+		expiring := []*ExpiringSkill{}
+		forLead := []*ExpiringSkill{}
+		for _, e := range FindExpiringSkills(NewUsers(user), now.Add(prior)) {
+			if !e.Grant.Reminded {
+				expiring = append(expiring, e)
+			}
+			if leadID != "" && !e.Grant.remindedLead(r.RotationID) {
+				forLead = append(forLead, e)
+			}
+		}
+		if len(expiring) == 0 && len(forLead) == 0 {
+			continue
+		}
 
-// 		sl.messageShiftJoined(added, rotation, shift)
-// 		appendShift(shiftNumber, shift, added)
-// 	}
+		if len(expiring) > 0 {
+			users, err := sl.loadGrantors(expiring, NewUsers(user))
+			if err != nil {
+				return nil, err
+			}
+			sl.dmUserSkillsExpiring(user, users, expiring, now)
+			for _, e := range expiring {
+				e.Grant.Reminded = true
+			}
+			remindedUsers++
+		}
+		for _, e := range forLead {
+			e.Grant.setRemindedLead(r.RotationID)
+		}
+		err = sl.storeUser(user)
+		if err != nil {
+			return nil, err
+		}
+		remindedLead = append(remindedLead, forLead...)
+	}
+	if remindedUsers == 0 && len(remindedLead) == 0 {
+		return autopilotIdle(""), nil
+	}
 
-// 	return filledShiftNumbers, filledShifts, addedUsers, nil
-// }
+	if len(remindedLead) > 0 {
+		users, err := sl.loadGrantors(remindedLead, r.Users)
+		if err != nil {
+			return nil, err
+		}
+		sl.dmLeadSkillsExpiring(NewUser(leadID), r, users, remindedLead, now)
+	}
+	return md.Markdownf("expiry reminder: messaged %v users, and the lead about %v expiring qualifications", remindedUsers, len(remindedLead)), nil
+}
@@ -193,6 +193,7 @@ func (sl *sl) loadRotation(rotationID types.ID) (*Rotation, error) {
 		return nil, err
 	}
 	r.Init()
+	r.defaultWorkload = configWorkload(sl.Config())
 	r.loaded = true
 
 	return r, nil
@@ -204,6 +205,14 @@ func (sl *sl) expandRotationUsers(r *Rotation) error {
 		return err
 	}
 	r.Users = users
+
+	r.Trainees = NewUsers()
+	if r.TraineeMattermostUserIDs != nil {
+		r.Trainees, err = sl.LoadUsers(r.TraineeMattermostUserIDs)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
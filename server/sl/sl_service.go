@@ -10,6 +10,7 @@ import (
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/webhook"
 )
 
 type PluginAPI interface {
@@ -28,6 +29,9 @@ type Service struct {
 	Logger      bot.Logger
 	Poster      bot.Poster
 	Store       kvstore.Store
+
+	// Webhooks delivers the rotations' webhooks; nil disables them.
+	Webhooks *webhook.Sender
 }
 
 func (s *Service) ActingAs(mattermostUserID types.ID) SL {
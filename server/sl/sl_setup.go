@@ -70,10 +70,17 @@ func withExpandedRotation(idref *types.ID, r *Rotation) func(sl *sl) error {
 			withLoadRotation(idref, r),
 			withExpandRotationUsers(r),
 			withExpandRotationTasks(r),
+			withExpandRotationHolidays(r),
 		)
 	}
 }
 
+func withExpandRotationHolidays(r *Rotation) func(sl *sl) error {
+	return func(sl *sl) error {
+		return sl.expandRotationHolidays(r)
+	}
+}
+
 func withExpandedActingUser(sl *sl) error {
 	user, _, err := sl.loadOrMakeUser(sl.actingMattermostUserID)
 	if err != nil {
@@ -113,6 +120,20 @@ func withValidSkillName(skillName *types.ID) func(sl *sl) error {
 	}
 }
 
+func withValidHolidayCalendar(calendarID *types.ID) func(sl *sl) error {
+	return func(sl *sl) error {
+		calendars := types.NewIDSet()
+		err := sl.Setup(withLoadIDIndex(KeyHolidayCalendars, calendars))
+		if err != nil {
+			return err
+		}
+		if !calendars.Contains(*calendarID) {
+			return errors.Errorf("holiday calendar %s is not found", *calendarID)
+		}
+		return nil
+	}
+}
+
 func withValidSkillNames(skillNames ...string) func(sl *sl) error {
 	return func(sl *sl) error {
 		for _, skill := range skillNames {
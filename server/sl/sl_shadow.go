@@ -0,0 +1,112 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// fillShadows adds trainees from the rotation's trainee pool to the task, up
+// to TraineeSettings.Shadows. The trainees who have shadowed the fewest tasks
+// in the rotation go first.
+func (sl *sl) fillShadows(r *Rotation, task *Task) (*Users, error) {
+	if task.ShadowMattermostUserIDs == nil {
+		task.ShadowMattermostUserIDs = types.NewIDSet()
+	}
+	need := r.TraineeSettings.Shadows - task.ShadowMattermostUserIDs.Len()
+	if need <= 0 || r.Trainees.IsEmpty() {
+		return NewUsers(), nil
+	}
+
+	candidates := []*User{}
+	interval := task.Interval()
+	for _, user := range r.Trainees.AsArray() {
+		if task.MattermostUserIDs.Contains(user.MattermostUserID) ||
+			task.ShadowMattermostUserIDs.Contains(user.MattermostUserID) {
+			continue
+		}
+		if !interval.IsEmpty() && len(r.FindBlocking(user, interval)) > 0 {
+			continue
+		}
+		candidates = append(candidates, user)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ShadowedCount(r.RotationID) < candidates[j].ShadowedCount(r.RotationID)
+	})
+	if len(candidates) > need {
+		candidates = candidates[:need]
+	}
+
+	return sl.assignShadows(r, task, NewUsers(candidates...))
+}
+
+func (sl *sl) assignShadows(r *Rotation, task *Task, users *Users) (added *Users, err error) {
+	defer task.WrapError(&err, "shadow")
+
+	if !allowedAssignTaskStates[true].Contains(task.State) {
+		return nil, errors.Errorf("can not shadow task in state %s", task.State)
+	}
+	if task.ShadowMattermostUserIDs == nil {
+		task.ShadowMattermostUserIDs = types.NewIDSet()
+	}
+
+	added = NewUsers()
+	for _, user := range users.AsArray() {
+		if task.MattermostUserIDs.Contains(user.MattermostUserID) {
+			return nil, errors.Errorf("%s is already assigned to the task", user.Markdown())
+		}
+		if task.ShadowMattermostUserIDs.Contains(user.MattermostUserID) {
+			continue
+		}
+		task.ShadowMattermostUserIDs.Set(user.MattermostUserID)
+		if task.Shadows != nil {
+			task.Shadows.Set(user)
+		}
+		added.Set(user)
+	}
+
+	markUsersShadowed(task, added)
+	return added, nil
+}
+
+// markUsersShadowed adds the task's calendar entries for the shadows. Unlike
+// markUsersServed, it does not affect LastServed since the trainees are not in
+// the rotation.
+func markUsersShadowed(t *Task, users *Users) {
+	cal := t.NewUnavailable()
+	if cal == nil {
+		return
+	}
+	for _, user := range users.AsArray() {
+		user.ClearUnavailable(types.Interval{}, t.RotationID, t.TaskID)
+		user.AddUnavailable(cal...)
+	}
+}
+
+// graduateShadows counts the finished task for its shadows, and prompts the
+// lead to qualify the ones who have shadowed enough tasks. The tasks are often
+// finished by the autopilot, so without a lead there is no one to prompt, and
+// the graduation is only logged.
+func (sl *sl) graduateShadows(r *Rotation, t *Task) error {
+	for _, user := range t.Shadows.AsArray() {
+		if user.Shadowed == nil {
+			user.Shadowed = types.NewIntSet()
+		}
+		count := user.Shadowed.Get(r.RotationID) + 1
+		user.Shadowed.Set(r.RotationID, count)
+
+		if r.TraineeSettings.GraduateAfter > 0 && count == int64(r.TraineeSettings.GraduateAfter) {
+			if r.TraineeSettings.LeadMattermostUserID == "" {
+				sl.Warnf("%s is ready to graduate from %s, but the rotation has no lead to prompt", user.Markdown(), r.Markdown())
+				continue
+			}
+			sl.dmLeadTraineeGraduated(NewUser(r.TraineeSettings.LeadMattermostUserID), user, r, count)
+		}
+	}
+	return sl.storeUsers(t.Shadows)
+}
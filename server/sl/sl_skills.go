@@ -4,6 +4,7 @@
 package sl
 
 import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 	"github.com/pkg/errors"
 )
@@ -50,6 +51,82 @@ func (sl *sl) DeleteKnownSkill(skillName types.ID) error {
 		return err
 	}
 
+	tree, err := sl.loadSkillTree()
+	if err != nil {
+		return err
+	}
+	tree.Delete(skillName)
+	err = sl.storeSkillTree(tree)
+	if err != nil {
+		return err
+	}
+
 	sl.Infof("%s deleted skill %s.", sl.actingUser.Markdown(), skillName)
 	return nil
 }
+
+func (sl *sl) LoadSkillTree() (SkillTree, error) {
+	return sl.loadSkillTree()
+}
+
+// SetSkillParent makes skill imply parent, levelOffset levels lower. An empty
+// parent makes skill a root.
+func (sl *sl) SetSkillParent(skill, parent types.ID, levelOffset int64) error {
+	err := sl.Setup(
+		pushAPILogger("SetSkillParent", []interface{}{skill, parent, levelOffset}),
+		withValidSkillName(&skill),
+	)
+	if err != nil {
+		return err
+	}
+	defer sl.popLogger()
+
+	if parent != "" {
+		err = sl.Setup(withValidSkillName(&parent))
+		if err != nil {
+			return err
+		}
+	}
+
+	tree, err := sl.loadSkillTree()
+	if err != nil {
+		return err
+	}
+	err = tree.SetParent(skill, parent, levelOffset)
+	if err != nil {
+		return err
+	}
+	err = sl.storeSkillTree(tree)
+	if err != nil {
+		return err
+	}
+
+	if parent == "" {
+		sl.Infof("%s removed the parent of skill %s.", sl.actingUser.Markdown(), skill)
+	} else {
+		sl.Infof("%s set the parent of skill %s to %s, %v level(s) lower.", sl.actingUser.Markdown(), skill, parent, levelOffset)
+	}
+	return nil
+}
+
+func (sl *sl) loadSkillTree() (SkillTree, error) {
+	if sl.skillTree != nil {
+		return sl.skillTree, nil
+	}
+	tree := SkillTree{}
+	err := kvstore.LoadJSON(sl.Store, KeySkillTree, &tree)
+	if err != nil && err != kvstore.ErrNotFound {
+		return nil, err
+	}
+	sl.skillTree = tree
+	return tree, nil
+}
+
+func (sl *sl) storeSkillTree(tree SkillTree) error {
+	err := kvstore.StoreJSON(sl.Store, KeySkillTree, tree)
+	if err != nil {
+		return err
+	}
+	sl.skillTree = tree
+	return nil
+}
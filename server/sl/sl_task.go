@@ -59,6 +59,7 @@ func (sl *sl) createShift(r *Rotation, shiftNumber int, now types.Time) (task *T
 		r.Tasks.Set(task)
 	}
 
+	sl.fireWebhooks(r.RotationID, WebhookEventTaskCreated, now, task, nil)
 	return task, nil
 }
 
@@ -86,6 +87,14 @@ func (sl *sl) expandTaskUsers(task *Task) error {
 		return err
 	}
 	task.Users = users
+
+	task.Shadows = NewUsers()
+	if task.ShadowMattermostUserIDs != nil {
+		task.Shadows, err = sl.LoadUsers(task.ShadowMattermostUserIDs)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -94,7 +103,7 @@ var allowedAssignTaskStates = map[bool]*types.IDSet{
 	true:  types.NewIDSet(TaskStatePending, TaskStateScheduled, TaskStateStarted),
 }
 
-func (sl *sl) assignTask(r *Rotation, task *Task, users *Users, force bool) (assigned *Users, err error) {
+func (sl *sl) assignTask(r *Rotation, task *Task, users *Users, force bool, now types.Time) (assigned *Users, err error) {
 	defer task.WrapError(&err, "assign")
 
 	if !allowedAssignTaskStates[force].Contains(task.State) {
@@ -105,6 +114,21 @@ func (sl *sl) assignTask(r *Rotation, task *Task, users *Users, force bool) (ass
 		return nil, errors.Errorf("%s assign to task in state %s", out, task.State)
 	}
 
+	if !force {
+		err = checkPairs(r, task, users, now)
+		if err != nil {
+			return nil, err
+		}
+		err = checkWorkload(r, task, users)
+		if err != nil {
+			return nil, err
+		}
+		err = checkConflicts(r, task, users)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	limit := NewNeeds(task.Limit.AsArray()...)
 	require := NewNeeds(task.Require.AsArray()...)
 	assigned = NewUsers()
@@ -115,12 +139,12 @@ func (sl *sl) assignTask(r *Rotation, task *Task, users *Users, force bool) (ass
 
 		if !force {
 			var failed *Needs
-			limit, _, failed = limit.CheckLimits(user)
+			limit, _, failed = limit.CheckLimits(user, task.SkillsAt(now))
 			if !failed.IsEmpty() {
 				return nil, errors.Errorf("user %s failed max constraints %s", user.Markdown(), failed.MarkdownSkillLevels())
 			}
 		}
-		require = require.CheckRequired(user)
+		require = require.CheckRequired(user, task.SkillsAt(now))
 
 		task.MattermostUserIDs.Set(user.MattermostUserID)
 		if task.Users != nil {
@@ -133,7 +157,31 @@ func (sl *sl) assignTask(r *Rotation, task *Task, users *Users, force bool) (ass
 	return assigned, nil
 }
 
-func (sl *sl) unassignTask(task *Task, users *Users, force bool) (removed *Users, err error) {
+// checkPairs checks the users being assigned against the rotation's pair
+// rules, as if all of them were already on the task.
+func checkPairs(r *Rotation, task *Task, users *Users, now types.Time) error {
+	if len(r.TaskSettings.Pairs) == 0 {
+		return nil
+	}
+	onTask := NewUsers(users.AsArray()...)
+	for _, id := range task.MattermostUserIDs.IDs() {
+		switch {
+		case task.Users != nil && task.Users.Contains(id):
+			onTask.Set(task.Users.Get(id))
+		case r.Users != nil && r.Users.Contains(id):
+			onTask.Set(r.Users.Get(id))
+		}
+	}
+	for _, user := range users.AsArray() {
+		violated := r.TaskSettings.Pairs.Violated(onTask, user, task.SkillsAt(now))
+		if len(violated) > 0 {
+			return errors.Errorf("user %s violates pair rules %s", user.Markdown(), violated.Markdown(onTask))
+		}
+	}
+	return nil
+}
+
+func (sl *sl) unassignTask(r *Rotation, task *Task, users *Users, force bool) (removed *Users, err error) {
 	defer task.WrapError(&err, "unassign")
 
 	if !allowedAssignTaskStates[force].Contains(task.State) {
@@ -146,6 +194,15 @@ func (sl *sl) unassignTask(task *Task, users *Users, force bool) (removed *Users
 
 	removed = NewUsers()
 	for _, user := range users.AsArray() {
+		if task.ShadowMattermostUserIDs != nil && task.ShadowMattermostUserIDs.Contains(user.MattermostUserID) {
+			task.ShadowMattermostUserIDs.Delete(user.MattermostUserID)
+			if task.Shadows != nil {
+				task.Shadows.Delete(user.MattermostUserID)
+			}
+			user.ClearUnavailable(types.Interval{}, "", task.TaskID)
+			removed.Set(user)
+			continue
+		}
 		if !task.MattermostUserIDs.Contains(user.MattermostUserID) {
 			return nil, errors.Wrapf(kvstore.ErrNotFound, "%s is not assigned", user.Markdown())
 		}
@@ -154,36 +211,80 @@ func (sl *sl) unassignTask(task *Task, users *Users, force bool) (removed *Users
 		if task.Users != nil {
 			task.Users.Delete(user.MattermostUserID)
 		}
+		sl.unmarkUserServed(r, task, user)
 		removed.Set(user)
 	}
-	// TODO clear users' calendars
 	return removed, nil
 }
 
-func (sl *sl) fillTask(r *Rotation, task *Task, now types.Time) (added *Users, err error) {
+// fillReplacement fills, without saving, the requirements of the task that
+// were met before the removed users were unassigned, and are not anymore.
+// The removed users are not considered.
+func (sl *sl) fillReplacement(r *Rotation, task *Task, removed *Users, now types.Time) (*Users, error) {
+	before := NewUsers(task.Users.AsArray()...)
+	for _, user := range removed.AsArray() {
+		before.Set(user)
+	}
+	at := task.SkillsAt(now)
+	unmetBefore := task.Require.Unmet(before, at)
+	vacated := NewNeeds()
+	for _, need := range task.Require.AsArray() {
+		met := need.Count() - unmetBefore.Get(need.GetID()).Count()
+		if met > 0 {
+			vacated.Set(NewNeed(met, need.SkillLevel()))
+		}
+	}
+	if vacated.Unmet(task.Users, at).IsEmpty() {
+		return NewUsers(), nil
+	}
+
+	candidates := *r
+	candidates.Users = NewUsers()
+	for _, user := range r.Users.AsArray() {
+		if !removed.Contains(user.MattermostUserID) {
+			candidates.Users.Set(user)
+		}
+	}
+	replacement := *task
+	replacement.Require = vacated
+	replacement.MattermostUserIDs = types.NewIDSet(task.MattermostUserIDs.IDs()...)
+	replacement.Users = NewUsers(task.Users.AsArray()...)
+
+	filler, err := sl.taskFiller(&candidates)
+	if err != nil {
+		return nil, err
+	}
+	filled, _, err := filler.FillTask(&candidates, &replacement, now, sl.Logger)
+	return filled, err
+}
+
+func (sl *sl) fillTask(r *Rotation, task *Task, now types.Time) (added *Users, explanation *FillExplanation, err error) {
 	defer task.WrapError(&err, "fill")
 
 	// Autofill is only allowed on pending tasks
 	if task.State != TaskStatePending {
-		return nil, errors.Wrap(ErrWrongState, string(task.State))
+		return nil, nil, errors.Wrap(ErrWrongState, string(task.State))
 	}
 
 	filler, err := sl.taskFiller(r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	added, err = filler.FillTask(r, task, now, sl.Logger)
+	added, explanation, err = filler.FillTask(r, task, now, sl.Logger)
 	if err != nil {
-		return nil, err
+		return nil, explanation, err
 	}
 
-	return sl.assignTask(r, task, added, true)
+	added, err = sl.assignTask(r, task, added, true, now)
+	return added, explanation, err
 }
 
 var validPriorStates = map[types.ID]*types.IDSet{
-	TaskStatePending:   types.NewIDSet("none"),
+	TaskStatePending:   types.NewIDSet("none", TaskStateScheduled),
 	TaskStateScheduled: types.NewIDSet(TaskStatePending),
 	TaskStateStarted:   types.NewIDSet(TaskStateScheduled),
+	TaskStateFinished:  types.NewIDSet(TaskStatePending, TaskStateScheduled, TaskStateStarted),
+	TaskStateCancelled: types.NewIDSet(TaskStatePending, TaskStateScheduled),
 }
 
 func (sl *sl) transitionTask(r *Rotation, t *Task, now types.Time, to types.ID) (err error) {
@@ -191,6 +292,11 @@ func (sl *sl) transitionTask(r *Rotation, t *Task, now types.Time, to types.ID)
 		return nil
 	}
 	defer t.WrapError(&err, "transition to "+to.String())
+	defer func() {
+		if err == nil {
+			sl.fireWebhooks(r.RotationID, webhookTaskEvent(to), now, t, t.Users)
+		}
+	}()
 
 	priorStates, ok := validPriorStates[to]
 	if ok && !priorStates.Contains(t.State) {
@@ -199,10 +305,18 @@ func (sl *sl) transitionTask(r *Rotation, t *Task, now types.Time, to types.ID)
 
 	switch to {
 	case TaskStatePending:
+		if t.State == TaskStateScheduled {
+			sl.announceTaskUsers(t, sl.dmUserTaskUnscheduled)
+			t.Unscheduled = true
+			t.AutopilotRemindedStart = false
+			t.AutopilotRemindedFinish = false
+			return sl.releaseTaskUsers(r, t, to)
+		}
 		sl.announceRotationUsers(r, func(user *User, _ *Rotation) {
 			sl.dmUserTaskPending(user, t)
 		})
 	case TaskStateScheduled:
+		t.Unscheduled = false
 		sl.announceTaskUsers(t, sl.dmUserTaskScheduled)
 	case TaskStateStarted:
 		t.ActualStart = now
@@ -210,6 +324,9 @@ func (sl *sl) transitionTask(r *Rotation, t *Task, now types.Time, to types.ID)
 	case TaskStateFinished:
 		t.ActualFinish = now
 		sl.announceTaskUsers(t, sl.dmUserTaskFinished)
+	case TaskStateCancelled:
+		sl.announceTaskUsers(t, sl.dmUserTaskCancelled)
+		return sl.releaseTaskUsers(r, t, to)
 	}
 
 	sl.markUsersServed(r, t, t.Users)
@@ -218,6 +335,43 @@ func (sl *sl) transitionTask(r *Rotation, t *Task, now types.Time, to types.ID)
 	if err != nil {
 		return err
 	}
+	markUsersShadowed(t, t.Shadows)
+	err = sl.storeUsers(t.Shadows)
+	if err != nil {
+		return err
+	}
+	if to == TaskStateFinished {
+		err = sl.recordService(t)
+		if err != nil {
+			return err
+		}
+		err = sl.graduateShadows(r, t)
+		if err != nil {
+			return err
+		}
+	}
+	t.State = to
+	return sl.storeTask(t)
+}
+
+// releaseTaskUsers undoes the task's effect on its users' and shadows'
+// calendars, for a task that is cancelled, or moved back to pending. The
+// users remain assigned.
+func (sl *sl) releaseTaskUsers(r *Rotation, t *Task, to types.ID) error {
+	for _, user := range t.Users.AsArray() {
+		sl.unmarkUserServed(r, t, user)
+	}
+	err := sl.storeUsers(t.Users)
+	if err != nil {
+		return err
+	}
+	for _, user := range t.Shadows.AsArray() {
+		user.ClearUnavailable(types.Interval{}, "", t.TaskID)
+	}
+	err = sl.storeUsers(t.Shadows)
+	if err != nil {
+		return err
+	}
 	t.State = to
 	return sl.storeTask(t)
 }
@@ -227,10 +381,36 @@ func (sl *sl) markUsersServed(r *Rotation, t *Task, users *Users) {
 	if cal == nil {
 		return
 	}
+	if t.PriorLastServed == nil {
+		t.PriorLastServed = types.NewIntSet()
+	}
 	for _, user := range users.AsArray() {
+		if !t.PriorLastServed.Contains(user.MattermostUserID) {
+			t.PriorLastServed.Set(user.MattermostUserID, user.LastServed.Get(r.RotationID))
+		}
 		// TODO: add a Task method to return projected end date, package cal[0].Interval.Finish
 		user.LastServed.Set(r.RotationID, cal[0].Interval.Finish.Unix())
 		user.ClearUnavailable(types.Interval{}, t.RotationID, t.TaskID)
 		user.AddUnavailable(cal...)
 	}
 }
+
+// unmarkUserServed undoes markUsersServed for a user removed from the task.
+// LastServed is restored only if it is still set by the task, i.e. the user
+// has not served a later task since.
+func (sl *sl) unmarkUserServed(r *Rotation, t *Task, user *User) {
+	user.ClearUnavailable(types.Interval{}, "", t.TaskID)
+	if t.PriorLastServed == nil || !t.PriorLastServed.Contains(user.MattermostUserID) {
+		return
+	}
+	cal := t.NewUnavailable()
+	if cal != nil && user.LastServed.Get(r.RotationID) == cal[0].Interval.Finish.Unix() {
+		prior := t.PriorLastServed.Get(user.MattermostUserID)
+		if prior == 0 {
+			user.LastServed.Delete(r.RotationID)
+		} else {
+			user.LastServed.Set(r.RotationID, prior)
+		}
+	}
+	t.PriorLastServed.Delete(user.MattermostUserID)
+}
@@ -49,7 +49,9 @@ func (sl *sl) LoadUsers(mattermostUserIDs *types.IDSet) (*Users, error) {
 	return users, nil
 }
 
-func (sl *sl) qualify(users *Users, skillLevels []SkillLevel) error {
+// qualify sets the users' skill levels, and records the grants. Qualifying
+// again at the same level renews the grant.
+func (sl *sl) qualify(users *Users, skillLevels []SkillLevel, now, expires types.Time) error {
 	for _, skillLevel := range skillLevels {
 		err := sl.AddKnownSkill(skillLevel.Skill)
 		if err != nil {
@@ -61,13 +63,22 @@ func (sl *sl) qualify(users *Users, skillLevels []SkillLevel) error {
 		updated := []SkillLevel{}
 		for _, skillLevel := range skillLevels {
 			newSkill, newLevel := skillLevel.Skill, skillLevel.Level
-			if !user.SkillLevels.Contains(newSkill) || Level(user.SkillLevels.Get(newSkill)) != newLevel {
-				user.SkillLevels.Set(newSkill, int64(newLevel))
-				updated = append(updated, skillLevel)
+			user.SkillLevels.Set(newSkill, int64(newLevel))
+			if user.SkillGrants == nil {
+				user.SkillGrants = map[types.ID]*SkillGrant{}
 			}
+			grant := &SkillGrant{
+				GrantedAt: now,
+				Expires:   expires,
+			}
+			if sl.actingUser != nil {
+				grant.GrantedBy = sl.actingUser.MattermostUserID
+			}
+			user.SkillGrants[newSkill] = grant
+			updated = append(updated, skillLevel)
 		}
 		if len(updated) == 0 {
-			return nil
+			continue
 		}
 
 		err := sl.storeUserWelcomeNew(user)
@@ -85,6 +96,7 @@ func (sl *sl) disqualify(users *Users, skillNames []string) error {
 		for _, skill := range skillNames {
 			if user.SkillLevels.Contains(types.ID(skill)) {
 				user.SkillLevels.Delete(types.ID(skill))
+				delete(user.SkillGrants, types.ID(skill))
 				updated = append(updated, types.ID(skill))
 			}
 		}
@@ -101,7 +113,7 @@ func (sl *sl) disqualify(users *Users, skillNames []string) error {
 	return nil
 }
 
-func (sl *sl) joinRotation(users *Users, r *Rotation, starting types.Time) (added *Users, err error) {
+func (sl *sl) joinRotation(users *Users, r *Rotation, starting types.Time, trainee bool) (added *Users, err error) {
 	added = NewUsers()
 
 	for _, user := range users.AsArray() {
@@ -111,6 +123,23 @@ func (sl *sl) joinRotation(users *Users, r *Rotation, starting types.Time) (adde
 			continue
 		}
 
+		if trainee {
+			if r.TraineeMattermostUserIDs.Contains(user.MattermostUserID) {
+				sl.Debugf("%s is already a trainee in rotation %s.", user.Markdown(), r.Markdown())
+				continue
+			}
+			err = sl.storeUserWelcomeNew(user)
+			if err != nil {
+				return added, err
+			}
+			r.TraineeMattermostUserIDs.Set(user.MattermostUserID)
+			sl.dmUserWelcomeToRotation(user, r)
+			added.Set(user)
+			continue
+		}
+		// A trainee joining the rotation graduates from the trainee pool.
+		r.TraineeMattermostUserIDs.Delete(user.MattermostUserID)
+
 		// A new person may be given some slack - setting starting in the
 		// future all but guarantees they won't be selected until then.
 		user.LastServed.Set(r.RotationID, starting.Unix())
@@ -133,6 +162,12 @@ func (sl *sl) joinRotation(users *Users, r *Rotation, starting types.Time) (adde
 func (sl *sl) leaveRotation(users *Users, r *Rotation) (*Users, error) {
 	deleted := NewUsers()
 	for _, user := range users.AsArray() {
+		if r.TraineeMattermostUserIDs.Contains(user.MattermostUserID) {
+			r.TraineeMattermostUserIDs.Delete(user.MattermostUserID)
+			sl.dmUserLeftRotation(user, r)
+			deleted.Set(user)
+			continue
+		}
 		if !r.MattermostUserIDs.Contains(user.MattermostUserID) {
 			sl.Debugf("%s is not found in rotation %s", user.Markdown(), r.Markdown())
 			continue
@@ -198,6 +233,20 @@ func (sl *sl) expandUser(user *User) error {
 		}
 		user.location = loc
 	}
+	if user.skillTree == nil {
+		tree, err := sl.loadSkillTree()
+		if err != nil {
+			return err
+		}
+		user.skillTree = tree
+	}
+	if user.HolidayCalendarID != "" && user.holidays == nil {
+		holidays, err := sl.loadHolidayCalendar(user.HolidayCalendarID)
+		if err != nil {
+			return err
+		}
+		user.holidays = holidays
+	}
 	return nil
 }
 
@@ -268,3 +317,34 @@ func (sl *sl) addUserUnavailable(user *User, u *Unavailable) error {
 	}
 	return nil
 }
+
+func (sl *sl) loadServiceHistory(mattermostUserID types.ID) (*ServiceHistory, error) {
+	h := NewServiceHistory(mattermostUserID)
+	err := sl.Store.Entity(KeyServiceHistory).Load(mattermostUserID, h)
+	if err == kvstore.ErrNotFound {
+		return h, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load service history for %s", mattermostUserID)
+	}
+	return h, nil
+}
+
+// recordService appends the finished task to its users' service histories.
+func (sl *sl) recordService(t *Task) error {
+	for _, id := range t.MattermostUserIDs.IDs() {
+		h, err := sl.loadServiceHistory(id)
+		if err != nil {
+			return err
+		}
+		if !h.Append(NewServiceRecord(t)) {
+			continue
+		}
+		h.PluginVersion = sl.conf.PluginVersion
+		err = sl.Store.Entity(KeyServiceHistory).Store(id, h)
+		if err != nil {
+			return errors.Wrapf(err, "failed to store service history for %s", id)
+		}
+	}
+	return nil
+}
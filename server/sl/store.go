@@ -4,10 +4,18 @@
 package sl
 
 const (
-	KeyRotation        = "rotation_"
-	KeyRotationTasks   = "rotation_tasks_"
-	KeyTask            = "task_"
-	KeyUser            = "user_"
-	KeyKnownSkills     = "known_skills"
-	KeyActiveRotations = "active_rotations"
+	KeyRotation         = "rotation_"
+	KeyRotationTasks    = "rotation_tasks_"
+	KeyTask             = "task_"
+	KeyUser             = "user_"
+	KeyKnownSkills      = "known_skills"
+	KeySkillTree        = "skill_tree"
+	KeyActiveRotations  = "active_rotations"
+	KeyServiceHistory   = "service_history_"
+	KeySwap             = "swap_"
+	KeyAutopilotHistory = "autopilot_history_"
+	KeyHolidayCalendar  = "holiday_calendar_"
+	KeyHolidayCalendars = "holiday_calendars"
+	KeyCalendarToken    = "calendar_token_"
+	KeyRotationWebhooks = "rotation_webhooks_"
 )
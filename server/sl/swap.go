@@ -0,0 +1,161 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+const (
+	SwapStatePending  = types.ID("pending")
+	SwapStateAccepted = types.ID("accepted")
+	SwapStateDeclined = types.ID("declined")
+)
+
+// Swap is a proposal to give a task to another user, or to trade it for one
+// of theirs. It is applied once the other user accepts it.
+type Swap struct {
+	PluginVersion        string
+	SwapID               types.ID
+	TaskID               types.ID
+	FromMattermostUserID types.ID
+	ToMattermostUserID   types.ID
+	State                types.ID
+	Created              types.Time
+
+	// TradeTaskID is the task the To user gives to the From user in
+	// exchange, or empty for a giveaway.
+	TradeTaskID types.ID `json:",omitempty"`
+}
+
+// Markdown uses users, if available, to display the user names.
+func (swap *Swap) Markdown(users *Users) md.MD {
+	get := func(id types.ID) *User {
+		if users != nil && users.Contains(id) {
+			return users.Get(id)
+		}
+		return NewUser(id)
+	}
+	out := md.Markdownf("swap %s: %s from %s to %s",
+		swap.SwapID, swap.TaskID, get(swap.FromMattermostUserID).Markdown(), get(swap.ToMattermostUserID).Markdown())
+	if swap.TradeTaskID != "" {
+		out += md.Markdownf(", for %s", swap.TradeTaskID)
+	}
+	return out
+}
+
+var allowedSwapTaskStates = types.NewIDSet(TaskStatePending, TaskStateScheduled)
+
+// checkSwap checks that the task can be handed over from one user to the
+// other at the time. The calendar events for the task excludeTaskID are
+// ignored, since it is being traded away at the same time.
+func checkSwap(r *Rotation, task *Task, from, to *User, excludeTaskID types.ID, now types.Time) error {
+	if !allowedSwapTaskStates.Contains(task.State) {
+		return errors.Errorf("can not swap task %s in state %s", task.Markdown(), task.State)
+	}
+	if !task.MattermostUserIDs.Contains(from.MattermostUserID) {
+		return errors.Errorf("%s is not assigned to %s", from.Markdown(), task.Markdown())
+	}
+	if task.MattermostUserIDs.Contains(to.MattermostUserID) {
+		return errors.Errorf("%s is already assigned to %s", to.Markdown(), task.Markdown())
+	}
+	if !r.MattermostUserIDs.Contains(to.MattermostUserID) {
+		return errors.Errorf("%s is not a member of %s", to.Markdown(), r.Markdown())
+	}
+
+	interval := task.Interval()
+	if !interval.IsEmpty() {
+		for _, u := range r.FindBlocking(to, interval) {
+			if u.TaskID != "" && u.TaskID == excludeTaskID {
+				continue
+			}
+			return errors.Errorf("%s is not available for %s: %s %s", to.Markdown(), task.Markdown(), u.Reason, to.MarkdownInterval(u.Interval))
+		}
+	}
+
+	at := task.SkillsAt(now)
+	before := NewUsers()
+	after := NewUsers()
+	for _, user := range task.Users.AsArray() {
+		before.Set(user)
+		if user.MattermostUserID != from.MattermostUserID {
+			after.Set(user)
+		}
+	}
+
+	if task.Limit != nil {
+		limit := NewNeeds(task.Limit.AsArray()...)
+		for _, user := range after.AsArray() {
+			limit, _, _ = limit.CheckLimits(user, at)
+		}
+		_, _, failed := limit.CheckLimits(to, at)
+		if !failed.IsEmpty() {
+			return errors.Errorf("%s would exceed the limit on %s in %s", to.Markdown(), failed.MarkdownSkillLevels(), task.Markdown())
+		}
+	}
+
+	after.Set(to)
+	if task.Require != nil {
+		unmetBefore := task.Require.Unmet(before, at)
+		for _, need := range task.Require.Unmet(after, at).AsArray() {
+			if need.Count() > 0 && need.Count() > unmetBefore.Get(need.ID).Count() {
+				return errors.Errorf("%s would leave the requirement for %s unmet in %s", to.Markdown(), need.SkillLevel(), task.Markdown())
+			}
+		}
+	}
+
+	// Taking the from user off the task may break the rules for the others,
+	// e.g. leave a mentee unaccompanied; only the new violations count.
+	for _, user := range after.AsArray() {
+		violated := r.TaskSettings.Pairs.Violated(after, user, at)
+		if len(violated) == 0 {
+			continue
+		}
+		if user.MattermostUserID == to.MattermostUserID || len(violated) > len(r.TaskSettings.Pairs.Violated(before, user, at)) {
+			return errors.Errorf("%s would violate pair rules %s in %s", to.Markdown(), violated.Markdown(after), task.Markdown())
+		}
+	}
+
+	// The task traded away does not count towards the workload.
+	workloadUser := to
+	if excludeTaskID != "" {
+		workloadUser = to.Clone()
+		workloadUser.ClearUnavailable(types.Interval{}, "", excludeTaskID)
+	}
+	return checkWorkload(r, task, NewUsers(workloadUser))
+}
+
+// handOver moves the task from one user to the other, and updates their
+// calendars.
+func (sl *sl) handOver(r *Rotation, task *Task, from, to *User) {
+	task.MattermostUserIDs.Delete(from.MattermostUserID)
+	task.MattermostUserIDs.Set(to.MattermostUserID)
+	if task.Users != nil {
+		task.Users.Delete(from.MattermostUserID)
+		task.Users.Set(to)
+	}
+	sl.unmarkUserServed(r, task, from)
+	sl.markUsersServed(r, task, NewUsers(to))
+}
+
+func (sl *sl) loadSwap(swapID types.ID) (*Swap, error) {
+	swap := &Swap{}
+	err := sl.Store.Entity(KeySwap).Load(swapID, swap)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load swap %s", swapID)
+	}
+	return swap, nil
+}
+
+func (sl *sl) storeSwap(swap *Swap) error {
+	swap.PluginVersion = sl.conf.PluginVersion
+	err := sl.Store.Entity(KeySwap).Store(swap.SwapID, swap)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store swap %s", swap.SwapID)
+	}
+	return nil
+}
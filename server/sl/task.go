@@ -18,7 +18,7 @@ var ErrWrongState = errors.New("operation is not allowed in this state")
 const (
 	// New tasks that have been submitted are Pending. There are no restrictions
 	// on assigning, filling, or un-assigning users to pending tasks.
-	// No DMs are sent to users added to or removed from these tasks.
+	// No DMs are sent to users added to these tasks.
 	TaskStatePending = types.ID("pending")
 
 	// Scheduled tasks are normally verified to have met the requirements and
@@ -34,6 +34,11 @@ const (
 
 	// Finished tasks are archived, and are not yet used.
 	TaskStateFinished = types.ID("finished")
+
+	// Cancelled tasks are kept, so that the autopilot does not re-create
+	// the shifts, but are removed from the users' calendars. Pending and
+	// scheduled tasks can be cancelled.
+	TaskStateCancelled = types.ID("cancelled")
 )
 
 type Task struct {
@@ -56,17 +61,41 @@ type Task struct {
 	Require                 *Needs        `json:",omitempty"`
 	Summary                 string        `json:",omitempty"`
 
-	Users *Users `json:"-"`
+	// Holiday is the holiday the shift falls on, for the rotations that mark
+	// the shifts on holidays.
+	Holiday string `json:",omitempty"`
+
+	// ShadowMattermostUserIDs are the trainees shadowing the task. They do
+	// not count towards Require nor Limit.
+	ShadowMattermostUserIDs *types.IDSet `json:",omitempty"`
+
+	// VolunteerMattermostUserIDs are the rotation's users who volunteered
+	// for the pending task, in the order they volunteered.
+	VolunteerMattermostUserIDs *types.IDSet `json:",omitempty"`
+
+	// PriorLastServed are the assigned users' LastServed for the rotation
+	// from before they were assigned, to restore if they are unassigned.
+	PriorLastServed *types.IntSet `json:",omitempty"`
+
+	// Unscheduled is set when a scheduled task is moved back to pending, so
+	// that the autopilot does not schedule it again; scheduling it by hand
+	// clears it.
+	Unscheduled bool `json:",omitempty"`
+
+	Users   *Users `json:"-"`
+	Shadows *Users `json:"-"`
 }
 
 func NewTask(rotationID types.ID) *Task {
 	return &Task{
-		State:             TaskStatePending,
-		RotationID:        rotationID,
-		Require:           NewNeeds(),
-		Limit:             NewNeeds(),
-		MattermostUserIDs: types.NewIDSet(),
-		Users:             NewUsers(),
+		State:                   TaskStatePending,
+		RotationID:              rotationID,
+		Require:                 NewNeeds(),
+		Limit:                   NewNeeds(),
+		MattermostUserIDs:       types.NewIDSet(),
+		ShadowMattermostUserIDs: types.NewIDSet(),
+		Users:                   NewUsers(),
+		Shadows:                 NewUsers(),
 	}
 }
 
@@ -80,13 +109,22 @@ func (t *Task) WrapError(err *error, verb string) {
 	}
 }
 
-func (t Task) MarkdownBullets(rotation *Rotation) md.MD {
+func (t Task) MarkdownBullets() md.MD {
 	out := md.Markdownf("- %s\n", t.Markdown())
 	out += md.Markdownf("  - Status: **%s**\n", t.State)
+	if t.Holiday != "" {
+		out += md.Markdownf("  - Holiday: **%s**\n", t.Holiday)
+	}
 	out += md.Markdownf("  - Users: **%v**\n", t.MattermostUserIDs.Len())
-	// for _, user := range rotation.TaskUsers(&t) {
-	// 	out += fmt.Sprintf("    - %s\n", user.MarkdownWithSkills())
-	// }
+	for _, user := range t.Users.AsArray() {
+		out += md.Markdownf("    - %s\n", user.MarkdownWithSkills())
+	}
+	if !t.ShadowMattermostUserIDs.IsEmpty() {
+		out += md.Markdownf("  - Shadows: **%v**\n", t.ShadowMattermostUserIDs.Len())
+		for _, user := range t.Shadows.AsArray() {
+			out += md.Markdownf("    - %s\n", user.MarkdownWithSkills())
+		}
+	}
 	return out
 }
 
@@ -135,12 +173,26 @@ func (t *Task) Interval() types.Interval {
 	return types.Interval{}
 }
 
+// SkillsAt is the time the users' qualifications must be valid at to serve
+// the task: its expected start, or now if it has started, or has no expected
+// start. A zero now is the current time.
+func (t *Task) SkillsAt(now types.Time) time.Time {
+	at := now.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if t.ExpectedStart.After(at) {
+		return t.ExpectedStart.Time
+	}
+	return at
+}
+
 func (t *Task) isReadyToStart() (ready bool, whyNot string, err error) {
 	if t.State != TaskStatePending && t.State != TaskStateScheduled {
 		return false, "", errors.Wrap(ErrWrongState, string(t.State))
 	}
 
-	unmetNeeds := t.Require.Unmet(t.Users)
+	unmetNeeds := t.Require.Unmet(t.Users, t.SkillsAt(types.Time{}))
 	if unmetNeeds.IsEmpty() {
 		return true, "", nil
 	}
@@ -8,6 +8,7 @@ package test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -47,7 +48,7 @@ func TestQualifiedForNeed(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			ok, need := tc.need.QualifyUser(tc.user)
+			ok, need := tc.need.QualifyUser(tc.user, time.Now())
 			require.Equal(t, tc.expected, ok)
 			if ok {
 				require.Equal(t, tc.need.Count()-1, need.Count())
@@ -76,7 +77,7 @@ func TestUsersQualifiedForNeed(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			qualified, need := tc.need.QualifyUsers(tc.users)
+			qualified, need := tc.need.QualifyUsers(tc.users, time.Now())
 			require.Equal(t, tc.expectedQualified, qualified)
 			require.Equal(t, tc.need.Count()-int64(tc.expectedQualified.Len()), need.Count())
 		})
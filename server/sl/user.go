@@ -20,11 +20,23 @@ type User struct {
 	SkillLevels      *types.IntSet  `json:",omitempty"` // skill (id) -> level
 	LastServed       *types.IntSet  `json:",omitempty"` // Last time completed a task, rotationID -> Unix time.
 	Calendar         []*Unavailable `json:",omitempty"` // Sorted by start date of the events.
+	Shadowed         *types.IntSet  `json:",omitempty"` // Number of tasks shadowed as a trainee, rotationID -> count.
+	WorkingHours     *WorkingHours  `json:",omitempty"`
+
+	// HolidayCalendarID is the user's regional holiday calendar. The user is
+	// unavailable on its holidays.
+	HolidayCalendarID types.ID `json:",omitempty"`
+
+	// SkillGrants records who qualified the user for the skills in
+	// SkillLevels, and when the qualifications expire. skill (id) -> grant
+	SkillGrants map[types.ID]*SkillGrant `json:",omitempty"`
 
 	// private fields
 	loaded         bool
 	mattermostUser *model.User
 	location       *time.Location
+	skillTree      SkillTree
+	holidays       *HolidayCalendar
 }
 
 func NewUser(mattermostUserID types.ID) *User {
@@ -50,6 +62,27 @@ func (user *User) WithSkills(skillLevels *types.IntSet) *User {
 	return user
 }
 
+// Clone returns a copy of the user that can be used to simulate serving
+// tasks, without affecting the original's LastServed and Calendar.
+func (user *User) Clone() *User {
+	newUser := *user
+	newUser.LastServed = types.NewIntSet()
+	if user.LastServed != nil {
+		for _, id := range user.LastServed.IDs() {
+			newUser.LastServed.Set(id, user.LastServed.Get(id))
+		}
+	}
+	newUser.Calendar = append([]*Unavailable{}, user.Calendar...)
+	return &newUser
+}
+
+func (user *User) ShadowedCount(rotationID types.ID) int64 {
+	if user.Shadowed == nil {
+		return 0
+	}
+	return user.Shadowed.Get(rotationID)
+}
+
 func (user *User) String() string {
 	if user.mattermostUser != nil {
 		return fmt.Sprintf("@%s", user.mattermostUser.Username)
@@ -70,7 +103,19 @@ func (user *User) MarkdownUnavailable(u *Unavailable) md.MD {
 	return md.Markdownf("%s: %s", u.Reason, user.MarkdownInterval(u.Interval))
 }
 
+func (user *User) MarkdownUnavailableList(uu []*Unavailable) md.MD {
+	out := []string{}
+	for _, u := range uu {
+		out = append(out, user.MarkdownUnavailable(u).String())
+	}
+	return md.MD(strings.Join(out, ", "))
+}
+
 func (user *User) Time(t types.Time) types.Time {
+	if user.location == nil {
+		// Not expanded, e.g. in unit tests
+		return t
+	}
 	return t.In(user.location)
 }
 
@@ -166,6 +211,7 @@ func (user *User) ScanUnavailable(matchInterval types.Interval, matchRotationID,
 				if nonmatchf != nil {
 					nonmatchf(event)
 				}
+				continue
 			}
 		}
 
@@ -5,8 +5,12 @@ package sl
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
 
 	"github.com/mattermost/mattermost-plugin-solar-lottery/server/constants"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
 )
 
 func (sl *sl) dmUserWelcomeToSolarLottery(user *User) {
@@ -65,11 +69,26 @@ func (sl *sl) dmUserChangedSkill(user *User, skillName string, level int) {
 }
 
 func (sl *sl) dmUserTaskPending(user *User, task *Task) {
-	sl.dmUser(user,
-		fmt.Sprintf("%s opened a new pending task %s.\n"+
-			"Use `TODO` if you would like to participate.\n",
-			sl.actingUser.Markdown(),
-			task.Markdown()))
+	text := fmt.Sprintf("%s opened a new pending task %s.\n"+
+		"Use `/%s task volunteer %s`, or the button below if you would like to participate.\n",
+		sl.actingUser.Markdown(),
+		task.Markdown(),
+		constants.CommandTrigger,
+		task.TaskID)
+	_ = sl.Poster.DMWithAttachments(string(user.MattermostUserID), &model.SlackAttachment{
+		Text: text,
+		Actions: []*model.PostAction{{
+			Name: "Volunteer",
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: sl.conf.PluginURLPath + constants.PathPostAction + constants.PathVolunteer,
+				Context: map[string]interface{}{
+					"task_id": task.TaskID.String(),
+				},
+			},
+		}},
+	})
+	sl.Debugf("DM bot to %s:\n%s", user.Markdown(), text)
 }
 
 func (sl *sl) dmUserTaskStarted(user *User, task *Task) {
@@ -90,6 +109,24 @@ func (sl *sl) dmUserTaskScheduled(user *User, t *Task) {
 			t.Markdown()))
 }
 
+func (sl *sl) dmUserTaskUnscheduled(user *User, t *Task) {
+	sl.dmUser(user,
+		fmt.Sprintf("###### %s is no longer scheduled.\n"+
+			"%s moved %s back to pending, it has been removed from your calendar.",
+			t.Markdown(),
+			sl.actingUser.Markdown(),
+			t.Markdown()))
+}
+
+func (sl *sl) dmUserTaskCancelled(user *User, t *Task) {
+	sl.dmUser(user,
+		fmt.Sprintf("###### %s is cancelled.\n"+
+			"%s cancelled %s, it has been removed from your calendar.",
+			t.Markdown(),
+			sl.actingUser.Markdown(),
+			t.Markdown()))
+}
+
 func (sl *sl) dmUserTaskWillStart(user *User, t *Task) {
 	sl.dmUser(user,
 		fmt.Sprintf("###### Your task %s will start TODO-when.\n"+
@@ -125,6 +162,127 @@ func (sl *sl) dmUserAssignedTask(user *User, task *Task) {
 			task.State))
 }
 
+func (sl *sl) dmUserUnassignedTask(user *User, task *Task) {
+	sl.dmUser(user,
+		fmt.Sprintf("%s unassigned you from %s, which is %s",
+			sl.actingUser.Markdown(),
+			task.Markdown(),
+			task.State))
+}
+
+func (sl *sl) dmLeadReplacement(lead *User, task *Task, removed, replacement *Users, fillErr error) {
+	sl.expandUser(lead)
+	message := fmt.Sprintf("%s unassigned %s from %s, which is %s.\n",
+		sl.actingUser.Markdown(),
+		removed.Markdown(),
+		task.Markdown(),
+		task.State)
+	if fillErr != nil {
+		message += fmt.Sprintf("No replacement was found: %v.\n", fillErr)
+	} else {
+		usernames := []string{}
+		for _, user := range replacement.AsArray() {
+			usernames = append(usernames, user.Markdown().String())
+		}
+		message += fmt.Sprintf("Proposed replacement: %s. To assign, use `/%s task assign %s %s --force`.\n",
+			replacement.MarkdownWithSkills(),
+			constants.CommandTrigger,
+			task.TaskID,
+			strings.Join(usernames, " "))
+	}
+	sl.dmUser(lead, message)
+}
+
+func (sl *sl) dmLeadTraineeGraduated(lead, trainee *User, r *Rotation, count int64) {
+	sl.expandUser(lead)
+	sl.expandUser(trainee)
+	skills := []string{}
+	for _, need := range r.TaskSettings.Require.AsArray() {
+		if need.SkillLevel().Skill != AnySkill {
+			skills = append(skills, need.SkillLevel().Type())
+		}
+	}
+	qualify := ""
+	if len(skills) > 0 {
+		qualify = fmt.Sprintf("`/%s user qualify %s --skills %s`, and ",
+			constants.CommandTrigger, trainee.Markdown(), strings.Join(skills, ","))
+	}
+
+	sl.dmUser(lead,
+		fmt.Sprintf("###### %s is ready to graduate\n"+
+			"%s has shadowed %v tasks in %s. Please consider %s`/%s user join %s --rotation %s`.",
+			trainee.Markdown(),
+			trainee.Markdown(),
+			count,
+			r.Markdown(),
+			qualify,
+			constants.CommandTrigger,
+			trainee.Markdown(),
+			r.RotationID))
+}
+
+func (sl *sl) dmUserSkillsExpiring(user *User, users *Users, expiring []*ExpiringSkill, now types.Time) {
+	sl.expandUser(user)
+	lines := []string{}
+	for _, e := range expiring {
+		lines = append(lines, "- "+e.Markdown(users, now.Time).String())
+	}
+	sl.dmUser(user,
+		fmt.Sprintf("###### Your qualifications are expiring\n"+
+			"%s\nPlease ask to be recertified, with `/%s user qualify %s --skills <skill-level> --expires <time>`.",
+			strings.Join(lines, "\n"),
+			constants.CommandTrigger,
+			user.Markdown()))
+}
+
+func (sl *sl) dmLeadSkillsExpiring(lead *User, r *Rotation, users *Users, expiring []*ExpiringSkill, now types.Time) {
+	sl.expandUser(lead)
+	lines := []string{}
+	for _, e := range expiring {
+		lines = append(lines, "- "+e.Markdown(users, now.Time).String())
+	}
+	sl.dmUser(lead,
+		fmt.Sprintf("###### Qualifications expiring in %s\n"+
+			"%s\nUse `/%s skill audit --rotation %s` for the full list.",
+			r.Markdown(),
+			strings.Join(lines, "\n"),
+			constants.CommandTrigger,
+			r.RotationID))
+}
+
+func (sl *sl) dmUserSwapRequested(user *User, swap *Swap) {
+	sl.expandUser(user)
+	text := fmt.Sprintf("%s would like to give you %s.", sl.actingUser.Markdown(), swap.TaskID)
+	if swap.TradeTaskID != "" {
+		text = fmt.Sprintf("%s would like to trade %s for your %s.", sl.actingUser.Markdown(), swap.TaskID, swap.TradeTaskID)
+	}
+	url := sl.conf.PluginURLPath + constants.PathPostAction + constants.PathSwap
+	action := func(name string, accept bool) *model.PostAction {
+		return &model.PostAction{
+			Name: name,
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Integration: &model.PostActionIntegration{
+				URL: url,
+				Context: map[string]interface{}{
+					"swap_id": swap.SwapID.String(),
+					"accept":  accept,
+				},
+			},
+		}
+	}
+	_ = sl.Poster.DMWithAttachments(string(user.MattermostUserID), &model.SlackAttachment{
+		Title:   "Swap request",
+		Text:    text,
+		Actions: []*model.PostAction{action("Accept", true), action("Decline", false)},
+	})
+	sl.Debugf("DM bot to %s:\n%s", user.Markdown(), text)
+}
+
+func (sl *sl) dmUserSwapResponded(user *User, users *Users, swap *Swap) {
+	sl.dmUser(user,
+		fmt.Sprintf("%s %s your %s.", sl.actingUser.Markdown(), swap.State, swap.Markdown(users)))
+}
+
 func (sl *sl) dmUser(user *User, message string) {
 	sl.Poster.DM(string(user.MattermostUserID), message)
 	sl.Debugf("DM bot to %s:\n%s", user.Markdown(), message)
@@ -141,4 +299,7 @@ func (sl *sl) announceTaskUsers(task *Task, dm func(*User, *Task)) {
 	for _, user := range task.Users.AsArray() {
 		dm(user, task)
 	}
+	for _, user := range task.Shadows.AsArray() {
+		dm(user, task)
+	}
 }
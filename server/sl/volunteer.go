@@ -0,0 +1,41 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// The rotation policies for the users who volunteer for pending tasks.
+const (
+	VolunteerFirstCome = types.ID("first-come")
+	VolunteerWeight    = types.ID("weight")
+)
+
+var VolunteerPolicies = types.NewIDSet(VolunteerFirstCome, VolunteerWeight)
+
+// VolunteerWeightFactor multiplies the volunteers' weights in the lottery,
+// same as if they had waited 3 more periods.
+const VolunteerWeightFactor = 8.0
+
+// VolunteerPolicy returns the rotation's policy for volunteers, weight by
+// default.
+func (r *Rotation) VolunteerPolicy() types.ID {
+	if r.FillSettings.Volunteers == "" {
+		return VolunteerWeight
+	}
+	return r.FillSettings.Volunteers
+}
+
+// Volunteers returns the users who volunteered for the task, in order.
+func (t *Task) Volunteers() []types.ID {
+	if t.VolunteerMattermostUserIDs == nil {
+		return nil
+	}
+	return t.VolunteerMattermostUserIDs.IDs()
+}
+
+func (t *Task) IsVolunteer(mattermostUserID types.ID) bool {
+	return t.VolunteerMattermostUserIDs != nil && t.VolunteerMattermostUserIDs.Contains(mattermostUserID)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/kvstore"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/webhook"
+)
+
+// The webhook events. The task transitions are sent as "task." and the new
+// state, e.g. "task.started".
+const (
+	WebhookEventTaskCreated    = types.ID("task.created")
+	WebhookEventTaskAssigned   = types.ID("task.assigned")
+	WebhookEventTaskUnassigned = types.ID("task.unassigned")
+	WebhookEventTaskFilled     = types.ID("task.filled")
+	WebhookEventJoined         = types.ID("rotation.joined")
+	WebhookEventLeft           = types.ID("rotation.left")
+)
+
+var WebhookEvents = types.NewIDSet(
+	WebhookEventTaskCreated,
+	WebhookEventTaskAssigned,
+	WebhookEventTaskUnassigned,
+	WebhookEventTaskFilled,
+	webhookTaskEvent(TaskStatePending),
+	webhookTaskEvent(TaskStateScheduled),
+	webhookTaskEvent(TaskStateStarted),
+	webhookTaskEvent(TaskStateFinished),
+	webhookTaskEvent(TaskStateCancelled),
+	WebhookEventJoined,
+	WebhookEventLeft,
+)
+
+func webhookTaskEvent(state types.ID) types.ID {
+	return types.ID("task." + string(state))
+}
+
+type Webhook struct {
+	URL    string
+	Secret string `json:",omitempty"`
+
+	// Events are the events sent to the webhook, all if empty.
+	Events *types.IDSet `json:",omitempty"`
+}
+
+func (h *Webhook) Markdown() md.MD {
+	events := "all events"
+	if h.Events != nil && !h.Events.IsEmpty() {
+		ss := []string{}
+		for _, id := range h.Events.IDs() {
+			ss = append(ss, string(id))
+		}
+		events = strings.Join(ss, ", ")
+	}
+	return md.Markdownf("%s: %s", h.URL, events)
+}
+
+func (h *Webhook) wants(event types.ID) bool {
+	return h.Events == nil || h.Events.IsEmpty() || h.Events.Contains(event)
+}
+
+// RotationWebhooks are the rotation's outgoing webhooks. They are stored
+// apart from the Rotation, so that the secrets are not shown with it.
+type RotationWebhooks struct {
+	PluginVersion string
+	RotationID    types.ID
+	Webhooks      []*Webhook
+}
+
+// WebhookUser identifies a user in the webhook payloads.
+type WebhookUser struct {
+	MattermostUserID types.ID
+	Username         string `json:",omitempty"`
+}
+
+// WebhookPayload is the JSON body of the webhook requests.
+type WebhookPayload struct {
+	Event      types.ID
+	Time       types.Time
+	RotationID types.ID
+	Task       *Task `json:",omitempty"`
+
+	// Users are the users the event is about: the ones assigned,
+	// unassigned, filled, joined, or left; for the task transitions, the
+	// task's users.
+	Users []*WebhookUser `json:",omitempty"`
+}
+
+func validateWebhookURL(in string) error {
+	u, err := url.Parse(in)
+	if err != nil {
+		return errors.Wrapf(err, "invalid webhook URL %q", in)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid webhook URL %q, must be http(s)://host/...", in)
+	}
+	return nil
+}
+
+func (sl *sl) loadRotationWebhooks(rotationID types.ID) (*RotationWebhooks, error) {
+	hooks := &RotationWebhooks{}
+	err := sl.Store.Entity(KeyRotationWebhooks).Load(rotationID, hooks)
+	if err == kvstore.ErrNotFound {
+		return &RotationWebhooks{RotationID: rotationID}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load webhooks for %s", rotationID)
+	}
+	return hooks, nil
+}
+
+func (sl *sl) storeRotationWebhooks(hooks *RotationWebhooks) error {
+	hooks.PluginVersion = sl.conf.PluginVersion
+	err := sl.Store.Entity(KeyRotationWebhooks).Store(hooks.RotationID, hooks)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store webhooks for %s", hooks.RotationID)
+	}
+	return nil
+}
+
+// fireWebhooks sends the event to the rotation's webhooks. The deliveries
+// happen in the background, and their failures are only logged.
+func (sl *sl) fireWebhooks(rotationID, event types.ID, now types.Time, task *Task, users *Users) {
+	if sl.Webhooks == nil {
+		return
+	}
+	hooks, err := sl.loadRotationWebhooks(rotationID)
+	if err != nil {
+		sl.Warnf("webhook %s: %v", event, err)
+		return
+	}
+	if len(hooks.Webhooks) == 0 {
+		return
+	}
+
+	payload := &WebhookPayload{
+		Event:      event,
+		Time:       now,
+		RotationID: rotationID,
+		Task:       task,
+	}
+	if users != nil {
+		for _, user := range users.AsArray() {
+			wu := &WebhookUser{MattermostUserID: user.MattermostUserID}
+			if user.mattermostUser != nil {
+				wu.Username = user.mattermostUser.Username
+			}
+			payload.Users = append(payload.Users, wu)
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		sl.Warnf("webhook %s: %v", event, err)
+		return
+	}
+
+	for _, h := range hooks.Webhooks {
+		if !h.wants(event) {
+			continue
+		}
+		sl.Webhooks.Send(&webhook.Request{
+			URL:     h.URL,
+			Secret:  h.Secret,
+			Event:   string(event),
+			Payload: data,
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/md"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// The rotation policies for filling tasks outside of the users' working
+// hours.
+const (
+	WorkingHoursIgnore  = types.ID("ignore")
+	WorkingHoursPrefer  = types.ID("prefer")
+	WorkingHoursRequire = types.ID("require")
+)
+
+var WorkingHoursPolicies = types.NewIDSet(WorkingHoursIgnore, WorkingHoursPrefer, WorkingHoursRequire)
+
+const clockFormat = "15:04"
+
+var DefaultWorkdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri"}
+
+// WorkingHours is the user's weekly working window: from Start to Finish, on
+// each of the Weekdays, in TimeZone. If Finish is not after Start, the window
+// ends on the next day.
+type WorkingHours struct {
+	// TimeZone is the IANA name of the time zone, e.g. "Europe/Berlin".
+	// Defaults to the user's Mattermost time zone.
+	TimeZone string `json:",omitempty"`
+
+	// Start and Finish are the local times of the day, as "09:00".
+	Start  string
+	Finish string
+
+	// Weekdays are the days the window starts on, as "Mon".
+	Weekdays []string
+}
+
+func NewWorkingHours(timeZone, start, finish string, weekdays []string) (*WorkingHours, error) {
+	wh := &WorkingHours{
+		TimeZone: timeZone,
+		Start:    start,
+		Finish:   finish,
+		Weekdays: weekdays,
+	}
+	if len(wh.Weekdays) == 0 {
+		wh.Weekdays = append([]string{}, DefaultWorkdays...)
+	}
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return nil, errors.Wrapf(err, "invalid time zone %s", timeZone)
+		}
+	}
+	for _, clock := range []string{start, finish} {
+		if _, err := time.Parse(clockFormat, clock); err != nil {
+			return nil, errors.Errorf("invalid time of day %q, use the 24h format, as 09:00", clock)
+		}
+	}
+	for i, day := range wh.Weekdays {
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			return nil, errors.Errorf("invalid day of the week %q, use Mon, Tue, etc", day)
+		}
+		wh.Weekdays[i] = weekday.String()[:3]
+	}
+	return wh, nil
+}
+
+func parseWeekday(in string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(in, d.String()[:3]) || strings.EqualFold(in, d.String()) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// Covers is true if every moment of interval is within the working hours.
+// defaultLocation is used if the working hours do not specify the time zone.
+func (wh *WorkingHours) Covers(interval types.Interval, defaultLocation *time.Location) bool {
+	loc := defaultLocation
+	if wh.TimeZone != "" {
+		loc, _ = time.LoadLocation(wh.TimeZone)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	start, err := time.Parse(clockFormat, wh.Start)
+	if err != nil {
+		return false
+	}
+	finish, err := time.Parse(clockFormat, wh.Finish)
+	if err != nil {
+		return false
+	}
+	workdays := map[time.Weekday]bool{}
+	for _, day := range wh.Weekdays {
+		if weekday, ok := parseWeekday(day); ok {
+			workdays[weekday] = true
+		}
+	}
+
+	// Advance through the consecutive working windows; the window that
+	// contains a moment starts on the same day, or on the day before.
+	cursor := interval.Start.In(loc).Time
+	for cursor.Before(interval.Finish.Time) {
+		covered := false
+		for _, back := range []int{0, -1} {
+			day := time.Date(cursor.Year(), cursor.Month(), cursor.Day()+back, 0, 0, 0, 0, loc)
+			if !workdays[day.Weekday()] {
+				continue
+			}
+			windowStart := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+			windowFinish := time.Date(day.Year(), day.Month(), day.Day(), finish.Hour(), finish.Minute(), 0, 0, loc)
+			if !windowFinish.After(windowStart) {
+				windowFinish = windowFinish.AddDate(0, 0, 1)
+			}
+			if !cursor.Before(windowStart) && cursor.Before(windowFinish) {
+				cursor = windowFinish
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func (wh *WorkingHours) Markdown() md.MD {
+	out := md.Markdownf("%s-%s %s", wh.Start, wh.Finish, strings.Join(wh.Weekdays, ","))
+	if wh.TimeZone != "" {
+		out += md.Markdownf(" %s", wh.TimeZone)
+	}
+	return out
+}
+
+// OffHours is true if the user has working hours, and they do not cover
+// interval. The users who have not set their working hours are available at
+// all times.
+func (user *User) OffHours(interval types.Interval) bool {
+	if user.WorkingHours == nil || interval.IsEmpty() {
+		return false
+	}
+	return !user.WorkingHours.Covers(interval, user.location)
+}
+
+// WorkingHoursPolicy returns the rotation's policy for filling tasks outside
+// of the users' working hours, ignore by default.
+func (r *Rotation) WorkingHoursPolicy() types.ID {
+	if r.FillSettings.WorkingHours == "" {
+		return WorkingHoursIgnore
+	}
+	return r.FillSettings.WorkingHours
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestWorkingHoursCovers(t *testing.T) {
+	mustWorkingHours := func(timeZone, start, finish string, days ...string) *WorkingHours {
+		wh, err := NewWorkingHours(timeZone, start, finish, days)
+		require.NoError(t, err)
+		return wh
+	}
+	// 2020-03-02 is a Monday.
+	for _, tc := range []struct {
+		name     string
+		wh       *WorkingHours
+		interval types.Interval
+		expected bool
+	}{
+		{
+			name:     "within",
+			wh:       mustWorkingHours("", "09:00", "17:00"),
+			interval: types.MustParseInterval("2020-03-02T10:00", "2020-03-02T12:00"),
+			expected: true,
+		},
+		{
+			name:     "too early",
+			wh:       mustWorkingHours("", "09:00", "17:00"),
+			interval: types.MustParseInterval("2020-03-02T08:00", "2020-03-02T12:00"),
+		},
+		{
+			name:     "weekend",
+			wh:       mustWorkingHours("", "09:00", "17:00"),
+			interval: types.MustParseInterval("2020-03-07T10:00", "2020-03-07T12:00"),
+		},
+		{
+			name:     "overnight",
+			wh:       mustWorkingHours("", "22:00", "06:00", "Mon"),
+			interval: types.MustParseInterval("2020-03-03T01:00", "2020-03-03T05:00"),
+			expected: true,
+		},
+		{
+			name:     "overnight, the window started on a day off",
+			wh:       mustWorkingHours("", "22:00", "06:00", "Mon"),
+			interval: types.MustParseInterval("2020-03-02T01:00", "2020-03-02T05:00"),
+		},
+		{
+			name:     "around the clock",
+			wh:       mustWorkingHours("", "00:00", "00:00", "Mon", "Tue", "Wed"),
+			interval: types.MustParseInterval("2020-03-02T10:00", "2020-03-04T10:00"),
+			expected: true,
+		},
+		{
+			name:     "in the time zone",
+			wh:       mustWorkingHours("Asia/Tokyo", "09:00", "17:00"),
+			interval: types.MustParseInterval("2020-03-02T00:00", "2020-03-02T08:00"),
+			expected: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.wh.Covers(tc.interval, time.UTC))
+		})
+	}
+
+	_, err := NewWorkingHours("", "9am", "17:00", nil)
+	require.Error(t, err)
+	_, err = NewWorkingHours("Mars/Olympus", "09:00", "17:00", nil)
+	require.Error(t, err)
+	_, err = NewWorkingHours("", "09:00", "17:00", []string{"Someday"})
+	require.Error(t, err)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/config"
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+// Workload caps the number of tasks, or hours, that a user may serve across
+// all rotations, in any rolling Window.
+type Workload struct {
+	MaxTasks int           `json:",omitempty"`
+	MaxHours float64       `json:",omitempty"`
+	Window   time.Duration `json:",omitempty"`
+}
+
+func (w Workload) IsEmpty() bool {
+	return w.Window <= 0 || (w.MaxTasks <= 0 && w.MaxHours <= 0)
+}
+
+func (w Workload) String() string {
+	if w.IsEmpty() {
+		return "none"
+	}
+	caps := []string{}
+	if w.MaxTasks > 0 {
+		caps = append(caps, fmt.Sprintf("%v tasks", w.MaxTasks))
+	}
+	if w.MaxHours > 0 {
+		caps = append(caps, fmt.Sprintf("%v hours", w.MaxHours))
+	}
+	window := w.Window.String()
+	if w.Window%(24*time.Hour) == 0 {
+		window = fmt.Sprintf("%v days", int64(w.Window/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%s per %s", strings.Join(caps, ", "), window)
+}
+
+// Exceeded checks if serving the task in interval would put the user over
+// the cap in any window that overlaps the task. The tasks the user is already
+// scheduled for, in all rotations, are found in the user's calendar. It returns the
+// description of the busiest window, or "" if the cap is not exceeded.
+func (w Workload) Exceeded(user *User, taskID types.ID, interval types.Interval) string {
+	if w.IsEmpty() || interval.IsEmpty() {
+		return ""
+	}
+
+	tasks := []types.Interval{interval}
+	for _, u := range user.Calendar {
+		if u.Reason == ReasonTask && u.TaskID != taskID {
+			tasks = append(tasks, u.Interval)
+		}
+	}
+
+	// The counts only change at the windows that start or end at a task's
+	// start or finish, so it is enough to check those.
+	starts := []time.Time{}
+	for _, task := range tasks {
+		for _, t := range []time.Time{task.Start.Time, task.Finish.Time} {
+			for _, start := range []time.Time{t, t.Add(-w.Window)} {
+				if start.Before(interval.Finish.Time) && start.Add(w.Window).After(interval.Start.Time) {
+					starts = append(starts, start)
+				}
+			}
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	for _, start := range starts {
+		window := types.NewDurationInterval(types.NewTime(start), w.Window)
+		count, hours := 0, 0.0
+		for _, task := range tasks {
+			if !window.Overlaps(task) {
+				continue
+			}
+			count++
+			from, to := task.Start.Time, task.Finish.Time
+			if from.Before(window.Start.Time) {
+				from = window.Start.Time
+			}
+			if to.After(window.Finish.Time) {
+				to = window.Finish.Time
+			}
+			hours += to.Sub(from).Hours()
+		}
+		if (w.MaxTasks > 0 && count > w.MaxTasks) || (w.MaxHours > 0 && hours > w.MaxHours) {
+			return fmt.Sprintf("%v tasks, %.1f hours between %s and %s, over the cap of %s",
+				count, hours, window.Start, window.Finish, w)
+		}
+	}
+	return ""
+}
+
+// Workload returns the rotation's workload cap, or the plugin-wide one if the
+// rotation does not have its own.
+func (r *Rotation) Workload() Workload {
+	if !r.TaskSettings.Workload.IsEmpty() {
+		return r.TaskSettings.Workload
+	}
+	return r.defaultWorkload
+}
+
+// configWorkload parses the plugin-wide workload cap. The values that fail to
+// parse are ignored.
+func configWorkload(c *config.Config) Workload {
+	w := Workload{}
+	if c == nil || c.StoredConfig == nil {
+		return w
+	}
+	w.MaxTasks, _ = strconv.Atoi(strings.TrimSpace(c.WorkloadMaxTasks))
+	w.MaxHours, _ = strconv.ParseFloat(strings.TrimSpace(c.WorkloadMaxHours), 64)
+	days, _ := strconv.Atoi(strings.TrimSpace(c.WorkloadWindowDays))
+	w.Window = time.Duration(days) * 24 * time.Hour
+	return w
+}
+
+func checkWorkload(r *Rotation, task *Task, users *Users) error {
+	w := r.Workload()
+	if w.IsEmpty() {
+		return nil
+	}
+	interval := types.NewDurationInterval(task.ExpectedStart, task.ExpectedDuration)
+	for _, user := range users.AsArray() {
+		if task.MattermostUserIDs.Contains(user.MattermostUserID) {
+			continue
+		}
+		exceeded := w.Exceeded(user, task.TaskID, interval)
+		if exceeded != "" {
+			return errors.Errorf("user %s would exceed the workload cap: %s", user.Markdown(), exceeded)
+		}
+	}
+	return nil
+}
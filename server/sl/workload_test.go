@@ -0,0 +1,71 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package sl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/types"
+)
+
+func TestWorkloadExceeded(t *testing.T) {
+	day := 24 * time.Hour
+	user := NewUser("test-user")
+	user.AddUnavailable(
+		&Unavailable{Reason: ReasonTask, TaskID: "r1#1", RotationID: "r1",
+			Interval: types.MustParseInterval("2020-03-01", "2020-03-02")},
+		&Unavailable{Reason: ReasonTask, TaskID: "r2#1", RotationID: "r2",
+			Interval: types.MustParseInterval("2020-03-05", "2020-03-06")},
+		&Unavailable{Reason: ReasonPersonal,
+			Interval: types.MustParseInterval("2020-03-07", "2020-03-20")},
+	)
+
+	for _, tc := range []struct {
+		name     string
+		workload Workload
+		taskID   types.ID
+		interval types.Interval
+		expected string
+	}{
+		{
+			name:     "no cap",
+			interval: types.MustParseInterval("2020-03-03", "2020-03-04"),
+		},
+		{
+			name:     "within the cap",
+			workload: Workload{MaxTasks: 3, Window: 7 * day},
+			interval: types.MustParseInterval("2020-03-03", "2020-03-04"),
+		},
+		{
+			name:     "too many tasks",
+			workload: Workload{MaxTasks: 2, Window: 7 * day},
+			interval: types.MustParseInterval("2020-03-03", "2020-03-04"),
+			expected: "3 tasks, 72.0 hours between 2020-02-28 and 2020-03-06, over the cap of 2 tasks per 7 days",
+		},
+		{
+			name:     "windows that include only some of the tasks",
+			workload: Workload{MaxTasks: 2, Window: 7 * day},
+			interval: types.MustParseInterval("2020-03-10", "2020-03-11"),
+		},
+		{
+			name:     "too many hours",
+			workload: Workload{MaxHours: 60, Window: 7 * day},
+			interval: types.MustParseInterval("2020-03-03", "2020-03-04"),
+			expected: "3 tasks, 72.0 hours between 2020-02-28 and 2020-03-06, over the cap of 60 hours per 7 days",
+		},
+		{
+			name:     "the task itself is not counted twice",
+			workload: Workload{MaxTasks: 2, Window: 7 * day},
+			taskID:   "r2#1",
+			interval: types.MustParseInterval("2020-03-05", "2020-03-06"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.workload.Exceeded(user, tc.taskID, tc.interval))
+		})
+	}
+}
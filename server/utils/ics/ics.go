@@ -0,0 +1,265 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package ics reads, and writes the events in iCalendar (RFC 5545) files. It
+// supports what the calendar exports commonly have: VEVENTs with UID,
+// SUMMARY, DESCRIPTION, DTSTART, DTEND or DURATION, STATUS, and the free/busy
+// status in TRANSP or X-MICROSOFT-CDO-BUSYSTATUS. Recurrence rules are
+// ignored, only the first occurrence of a recurring event is read.
+package ics
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Event is a calendar event. All-day events start, and finish at midnight in
+// the location they were parsed in.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string `json:",omitempty"`
+	Start       time.Time
+	Finish      time.Time
+	AllDay      bool
+
+	// Free is set for the events that do not block the time, e.g. the ones
+	// marked "show as available".
+	Free bool `json:",omitempty"`
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events from r. The times that have no time zone, and the
+// all-day events, are in loc.
+func Parse(r io.Reader, loc *time.Location) ([]*Event, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*Event{}
+	var event *Event
+	var duration time.Duration
+	cancelled := false
+	depth := 0
+	for n, line := range lines {
+		if line == "" {
+			continue
+		}
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %v", n+1)
+		}
+
+		switch {
+		case p.name == "BEGIN" && strings.ToUpper(p.value) == "VEVENT":
+			event = &Event{}
+			duration = 0
+			cancelled = false
+			continue
+		case p.name == "END" && strings.ToUpper(p.value) == "VEVENT":
+			if event == nil {
+				return nil, errors.Errorf("line %v: END:VEVENT without BEGIN", n+1)
+			}
+			if event.Start.IsZero() {
+				return nil, errors.Errorf("line %v: event %q has no DTSTART", n+1, event.Summary)
+			}
+			if event.Finish.IsZero() {
+				switch {
+				case duration != 0:
+					event.Finish = event.Start.Add(duration)
+				case event.AllDay:
+					event.Finish = event.Start.AddDate(0, 0, 1)
+				default:
+					event.Finish = event.Start
+				}
+			}
+			if !cancelled {
+				events = append(events, event)
+			}
+			event = nil
+			continue
+		case event == nil:
+			continue
+		case p.name == "BEGIN":
+			// Nested components, e.g. VALARM, have properties of their own.
+			depth++
+			continue
+		case p.name == "END":
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		switch p.name {
+		case "UID":
+			event.UID = p.value
+		case "SUMMARY":
+			event.Summary = unescape(p.value)
+		case "DESCRIPTION":
+			event.Description = unescape(p.value)
+		case "STATUS":
+			cancelled = strings.ToUpper(p.value) == "CANCELLED"
+		case "TRANSP":
+			event.Free = strings.ToUpper(p.value) == "TRANSPARENT"
+		case "X-MICROSOFT-CDO-BUSYSTATUS":
+			event.Free = strings.ToUpper(p.value) == "FREE"
+		case "DTSTART":
+			event.Start, event.AllDay, err = parseTime(p, loc)
+		case "DTEND":
+			event.Finish, _, err = parseTime(p, loc)
+		case "DURATION":
+			duration, err = parseDuration(p.value)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %v: invalid %s", n+1, p.name)
+		}
+	}
+	return events, nil
+}
+
+// unfold joins the continuation lines, that start with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read calendar")
+	}
+	return lines, nil
+}
+
+func parseProperty(line string) (*property, error) {
+	// The parameter values may be quoted, and contain ':'.
+	i, quoted := 0, false
+	for ; i < len(line); i++ {
+		if line[i] == '"' {
+			quoted = !quoted
+		}
+		if line[i] == ':' && !quoted {
+			break
+		}
+	}
+	if i == len(line) {
+		return nil, errors.Errorf("invalid content line %q", line)
+	}
+
+	parts := strings.Split(line[:i], ";")
+	p := &property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[i+1:],
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return p, nil
+}
+
+func parseTime(p *property, loc *time.Location) (t time.Time, allDay bool, err error) {
+	if tzid := p.params["TZID"]; tzid != "" {
+		loc, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+	}
+	value := p.value
+	switch {
+	case strings.ToUpper(p.params["VALUE"]) == "DATE" || len(value) == len("20060102"):
+		t, err = time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+// parseDuration parses the durations as P1D, PT1H30M, or P2W.
+func parseDuration(in string) (time.Duration, error) {
+	s := strings.ToUpper(in)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return 0, errors.Errorf("invalid duration %q", in)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	n := 0
+	digits := false
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			n = n*10 + int(c-'0')
+			digits = true
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, errors.Errorf("invalid duration %q", in)
+		}
+		unit := time.Duration(0)
+		switch {
+		case c == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case c == 'H' && inTime:
+			unit = time.Hour
+		case c == 'M' && inTime:
+			unit = time.Minute
+		case c == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, errors.Errorf("invalid duration %q", in)
+		}
+		d += time.Duration(n) * unit
+		n, digits = 0, false
+	}
+	if digits {
+		return 0, errors.Errorf("invalid duration %q", in)
+	}
+	return sign * d, nil
+}
+
+var unescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescape(in string) string {
+	return unescaper.Replace(in)
+}
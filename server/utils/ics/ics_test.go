@@ -0,0 +1,101 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//test//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:christmas@test\r\n" +
+	"DTSTART;VALUE=DATE:20201225\r\n" +
+	"SUMMARY:Christmas Day\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:offsite@test\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20201110T090000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20201112T170000\r\n" +
+	"SUMMARY:Team offsite\\, Berlin\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"SUMMARY:not the event's\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@test\r\n" +
+	"DTSTART:20201102T170000Z\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"TRANSP:TRANSPARENT\r\n" +
+	"SUMMARY:A very long summary that is folded \r\n" +
+	" over two lines\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@test\r\n" +
+	"DTSTART;VALUE=DATE:20201126\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	pst, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	events, err := Parse(strings.NewReader(testCalendar), pst)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	require.Equal(t, "christmas@test", events[0].UID)
+	require.Equal(t, "Christmas Day", events[0].Summary)
+	require.True(t, events[0].AllDay)
+	require.Equal(t, "2020-12-25T08:00:00Z", events[0].Start.UTC().Format(time.RFC3339))
+	require.Equal(t, "2020-12-26T08:00:00Z", events[0].Finish.UTC().Format(time.RFC3339))
+
+	require.Equal(t, "Team offsite, Berlin", events[1].Summary)
+	require.False(t, events[1].AllDay)
+	require.Equal(t, "2020-11-10T08:00:00Z", events[1].Start.UTC().Format(time.RFC3339))
+	require.Equal(t, "2020-11-12T16:00:00Z", events[1].Finish.UTC().Format(time.RFC3339))
+
+	require.Equal(t, "A very long summary that is folded over two lines", events[2].Summary)
+	require.Equal(t, "2020-11-02T17:00:00Z", events[2].Start.UTC().Format(time.RFC3339))
+	require.Equal(t, 90*time.Minute, events[2].Finish.Sub(events[2].Start))
+	require.True(t, events[2].Free)
+	require.False(t, events[1].Free)
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, in := range map[string]string{
+		"no DTSTART":    "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n",
+		"bad DTSTART":   "BEGIN:VEVENT\nDTSTART:2020-12-25\nEND:VEVENT\n",
+		"bad DURATION":  "BEGIN:VEVENT\nDTSTART:20201225T100000Z\nDURATION:1H\nEND:VEVENT\n",
+		"bad line":      "BEGIN:VEVENT\nDTSTART\nEND:VEVENT\n",
+		"unknown TZID":  "BEGIN:VEVENT\nDTSTART;TZID=Nowhere/Special:20201225T100000\nEND:VEVENT\n",
+		"unmatched END": "END:VEVENT\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(in), nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for in, expected := range map[string]time.Duration{
+		"P1D":     24 * time.Hour,
+		"P2W":     14 * 24 * time.Hour,
+		"PT1H30M": 90 * time.Minute,
+		"P1DT12H": 36 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+		"PT45S":   45 * time.Second,
+	} {
+		d, err := parseDuration(in)
+		require.NoError(t, err, in)
+		require.Equal(t, expected, d, in)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package ics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ProdID identifies the plugin as the producer of the calendars.
+const ProdID = "-//Mattermost//Solar Lottery//EN"
+
+// maxLineLength is the limit of the content lines, in octets, before they
+// are folded.
+const maxLineLength = 75
+
+// Write writes the events as a calendar named name. The times are written in
+// UTC, the all-day events as dates.
+func Write(w io.Writer, name string, events []*Event) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + ProdID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escape(name),
+	}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+e.UID,
+			// The events are generated, use the start for a stable DTSTAMP.
+			"DTSTAMP:"+formatUTC(e.Start),
+		)
+		if e.AllDay {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+e.Start.Format("20060102"),
+				"DTEND;VALUE=DATE:"+e.Finish.Format("20060102"))
+		} else {
+			lines = append(lines,
+				"DTSTART:"+formatUTC(e.Start),
+				"DTEND:"+formatUTC(e.Finish))
+		}
+		lines = append(lines, "SUMMARY:"+escape(e.Summary))
+		if e.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escape(e.Description))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		_, err := io.WriteString(w, fold(line))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(in string) string {
+	return escaper.Replace(in)
+}
+
+// fold breaks the line into CRLF-terminated lines of up to maxLineLength
+// octets, without splitting the UTF-8 characters.
+func fold(line string) string {
+	out := ""
+	limit := maxLineLength
+	for len(line) > limit {
+		i := limit
+		for i > 0 && line[i]&0xC0 == 0x80 {
+			i--
+		}
+		out += fmt.Sprintf("%s\r\n ", line[:i])
+		line = line[i:]
+		// The continuation lines start with a space.
+		limit = maxLineLength - 1
+	}
+	return out + line + "\r\n"
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	events := []*Event{
+		{
+			UID:         "shift-1@test",
+			Summary:     "test-rotation#1: @user1, @user2",
+			Description: "Status: scheduled\nHoliday: Christmas; Day",
+			Start:       time.Date(2020, 12, 24, 9, 0, 0, 0, berlin),
+			Finish:      time.Date(2020, 12, 24, 17, 0, 0, 0, berlin),
+		},
+		{
+			UID:     "day@test",
+			Summary: "Offsite",
+			Start:   time.Date(2020, 11, 10, 0, 0, 0, 0, time.UTC),
+			Finish:  time.Date(2020, 11, 12, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	err = Write(buf, "Rotation test-rotation", events)
+	require.NoError(t, err)
+	require.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:-//Mattermost//Solar Lottery//EN\r\n"+
+		"CALSCALE:GREGORIAN\r\n"+
+		"METHOD:PUBLISH\r\n"+
+		"X-WR-CALNAME:Rotation test-rotation\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:shift-1@test\r\n"+
+		"DTSTAMP:20201224T080000Z\r\n"+
+		"DTSTART:20201224T080000Z\r\n"+
+		"DTEND:20201224T160000Z\r\n"+
+		"SUMMARY:test-rotation#1: @user1\\, @user2\r\n"+
+		"DESCRIPTION:Status: scheduled\\nHoliday: Christmas\\; Day\r\n"+
+		"END:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:day@test\r\n"+
+		"DTSTAMP:20201110T000000Z\r\n"+
+		"DTSTART;VALUE=DATE:20201110\r\n"+
+		"DTEND;VALUE=DATE:20201112\r\n"+
+		"SUMMARY:Offsite\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n", buf.String())
+
+	parsed, err := Parse(buf, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, parsed, 2)
+	require.Equal(t, events[0].Description, parsed[0].Description)
+	require.True(t, events[0].Start.Equal(parsed[0].Start))
+	require.Equal(t, events[1].Finish, parsed[1].Finish)
+}
+
+func TestFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("ü", 50)
+	folded := fold(line)
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n ")
+	require.Len(t, lines, 2)
+	for _, l := range lines {
+		require.True(t, len(l) <= maxLineLength)
+	}
+	require.Equal(t, line, strings.Join(lines, ""))
+
+	require.Equal(t, "SUMMARY:short\r\n", fold("SUMMARY:short"))
+}
@@ -14,6 +14,9 @@ import (
 type Period struct {
 	Period   string
 	Duration time.Duration `json:",omitempty"`
+
+	// RRule is the recurrence rule for EveryRRule, as "FREQ=WEEKLY;BYDAY=MO".
+	RRule string `json:",omitempty"`
 }
 
 var _ pflag.Value = (*Period)(nil)
@@ -24,9 +27,16 @@ const (
 	EveryTwoWeeks = "everyTwoWeeks"
 	EveryMonth    = "everyMonth"
 	EveryDuration = "everyDuration"
+	EveryRRule    = "everyRRule"
 )
 
+// Workdays is the RRULE for the "workday" period.
+const Workdays = "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR"
+
 func (p *Period) String() string {
+	if p.Period == EveryRRule {
+		return p.RRule
+	}
 	return p.Period
 }
 
@@ -44,10 +54,22 @@ func (p *Period) Set(in string) error {
 		p.Period = EveryTwoWeeks
 	case EveryMonth, "m", "month", "monthly":
 		p.Period = EveryMonth
+	case "workday", "workdays", "weekdays":
+		return p.Set(Workdays)
 	default:
+		lower := strings.ToLower(in)
+		if strings.HasPrefix(lower, "rrule:") || strings.HasPrefix(lower, "freq=") {
+			rule, err := ParseRRule(in)
+			if err != nil {
+				return err
+			}
+			p.Period = EveryRRule
+			p.RRule = rule.String()
+			return nil
+		}
 		Duration, err := time.ParseDuration(in)
-		if err != nil {
-			return errors.New(`period must be "daily", "workday", "weekly", "biweekly", "monthly", or a valid go duration`)
+		if err != nil || Duration <= 0 {
+			return errors.New(`period must be "daily", "workday", "weekly", "biweekly", "monthly", an RRULE as "FREQ=WEEKLY;BYDAY=MO,TH", or a valid go duration`)
 		}
 		p.Period = EveryDuration
 		p.Duration = Duration
@@ -59,8 +81,14 @@ func (p *Period) ForNumber(beginning Time, num int) Time {
 	days, months := 0, 0
 	switch p.Period {
 	case EveryDuration:
-		reduced := beginning.Add(-p.Duration / 2)
-		return NewTime(reduced.Round(p.Duration))
+		return NewTime(beginning.Add(time.Duration(num) * p.Duration))
+
+	case EveryRRule:
+		rule, err := ParseRRule(p.RRule)
+		if err != nil {
+			return Time{}
+		}
+		return NewTime(rule.Occurrence(beginning.Time, num))
 
 	case EveryDay:
 		days = 1 * num
@@ -79,13 +107,15 @@ func (p *Period) ForNumber(beginning Time, num int) Time {
 }
 
 func (p *Period) ForTime(beginning, forTime Time) (int, Time) {
-	if forTime.Before(beginning.Time) {
+	// The beginning may not be an occurrence of a recurrence rule.
+	beginning = p.ForNumber(beginning, 0)
+	if beginning.IsZero() || forTime.Before(beginning.Time) {
 		return -1, Time{}
 	}
 
 	last := beginning
 	for n := 0; ; n++ {
-		if forTime.Before(beginning.Time) {
+		if beginning.IsZero() || forTime.Before(beginning.Time) {
 			return n - 1, last
 		}
 		last = beginning
@@ -94,6 +124,13 @@ func (p *Period) ForTime(beginning, forTime Time) (int, Time) {
 }
 
 func (p *Period) AverageDuration() time.Duration {
+	if p.Period == EveryRRule {
+		rule, err := ParseRRule(p.RRule)
+		if err != nil {
+			return 0
+		}
+		return rule.AverageDuration()
+	}
 	return map[string]time.Duration{
 		EveryDuration: p.Duration,
 		EveryDay:      24 * time.Hour,
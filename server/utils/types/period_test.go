@@ -5,6 +5,7 @@ package types
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -81,6 +82,27 @@ func TestPeriodForNumber(t *testing.T) {
 			steps:    4,
 			expected: MustParseTime("2020-05-04T16:00").In(PST),
 		},
+		{
+			name:     "every 3 days",
+			period:   Period{Period: EveryDuration, Duration: 72 * time.Hour},
+			start:    MustParseTime("2020-02-04T17:00").In(PST),
+			steps:    2,
+			expected: MustParseTime("2020-02-10T17:00").In(PST),
+		},
+		{
+			name:     "workdays over a weekend",
+			period:   mustParsePeriod("workdays"),
+			start:    MustParseTime("2020-02-06T09:00").In(PST),
+			steps:    2,
+			expected: MustParseTime("2020-02-10T09:00").In(PST),
+		},
+		{
+			name:     "first Monday of the month",
+			period:   mustParsePeriod("FREQ=MONTHLY;BYDAY=1MO"),
+			start:    MustParseTime("2020-02-03T09:00").In(PST),
+			steps:    2,
+			expected: MustParseTime("2020-04-06T08:00").In(PST),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			tt := tc.period.ForNumber(tc.start, tc.steps)
@@ -140,6 +162,20 @@ func TestPeriodForTime(t *testing.T) {
 			now:           MustParseTime("2020-12-01T17:00").In(PST),
 			expectedNum:   21,
 			expectedStart: MustParseTime("2020-11-24T17:00").In(PST),
+		}, {
+			name:          "workdays on Saturday",
+			period:        mustParsePeriod("workdays"),
+			start:         MustParseTime("2020-02-03T09:00").In(PST),
+			now:           MustParseTime("2020-02-08T12:00").In(PST),
+			expectedNum:   4,
+			expectedStart: MustParseTime("2020-02-07T09:00").In(PST),
+		}, {
+			name:          "business hours, start not an occurrence",
+			period:        mustParsePeriod("FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,13"),
+			start:         MustParseTime("2020-02-01T00:00").In(PST),
+			now:           MustParseTime("2020-02-04T18:00").In(PST),
+			expectedNum:   2,
+			expectedStart: MustParseTime("2020-02-04T17:00").In(PST),
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -149,3 +185,45 @@ func TestPeriodForTime(t *testing.T) {
 		})
 	}
 }
+
+func TestPeriodSet(t *testing.T) {
+	for _, tc := range []struct {
+		in            string
+		expected      string
+		expectedError string
+	}{
+		{in: "weekly", expected: EveryWeek},
+		{in: "72h", expected: EveryDuration},
+		{in: "weekdays", expected: Workdays},
+		{in: "rrule:FREQ=WEEKLY;BYDAY=TU", expected: "FREQ=WEEKLY;BYDAY=TU"},
+		{in: "-1h", expectedError: `period must be "daily", "workday", "weekly", "biweekly", "monthly", an RRULE as "FREQ=WEEKLY;BYDAY=MO,TH", or a valid go duration`},
+		{in: "FREQ=YEARLY", expectedError: "unsupported FREQ YEARLY, use DAILY, WEEKLY, or MONTHLY"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			p := Period{}
+			err := p.Set(tc.in)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, p.String())
+		})
+	}
+}
+
+func TestPeriodAverageDuration(t *testing.T) {
+	weekly := Period{Period: EveryWeek}
+	require.Equal(t, 7*24*time.Hour, weekly.AverageDuration())
+	workdays := mustParsePeriod("workdays")
+	require.InDelta(t, 33.6, workdays.AverageDuration().Hours(), 0.5)
+}
+
+func mustParsePeriod(in string) Period {
+	p := Period{}
+	err := p.Set(in)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
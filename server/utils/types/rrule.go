@@ -0,0 +1,344 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The supported RRULE frequencies.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// RRuleDay is a BYDAY entry: a weekday, and for the monthly rules, an
+// optional ordinal, e.g. 1MO for the first Monday, -1FR for the last Friday.
+type RRuleDay struct {
+	N       int
+	Weekday time.Weekday
+}
+
+func (d RRuleDay) String() string {
+	if d.N == 0 {
+		return rruleWeekdays[d.Weekday]
+	}
+	return fmt.Sprintf("%d%s", d.N, rruleWeekdays[d.Weekday])
+}
+
+// RRule is a subset of the RFC 5545 recurrence rule: FREQ (DAILY, WEEKLY, or
+// MONTHLY), INTERVAL, BYDAY, BYHOUR, and BYMINUTE. The occurrences are
+// anchored at a start time that provides the defaults: the weekday for the
+// weekly rules, the day of the month for the monthly rules, and the time of
+// the day. Weeks start on Monday.
+type RRule struct {
+	Freq     string
+	Interval int
+	ByDay    []RRuleDay
+	ByHour   []int
+	ByMinute []int
+}
+
+func ParseRRule(in string) (*RRule, error) {
+	in = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(in)), "RRULE:")
+	rule := &RRule{
+		Interval: 1,
+	}
+	for _, part := range strings.Split(in, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, errors.Errorf("invalid RRULE part %q", part)
+		}
+		key, value := kv[0], kv[1]
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				rule.Freq = value
+			default:
+				return nil, errors.Errorf("unsupported FREQ %s, use %s, %s, or %s", value, FreqDaily, FreqWeekly, FreqMonthly)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err != nil || rule.Interval < 1 {
+				return nil, errors.Errorf("invalid INTERVAL %s", value)
+			}
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				d, err := parseRRuleDay(v)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, d)
+			}
+		case "BYHOUR":
+			rule.ByHour, err = parseRRuleInts(value, 0, 23)
+		case "BYMINUTE":
+			rule.ByMinute, err = parseRRuleInts(value, 0, 59)
+		case "WKST":
+			if value != "MO" {
+				return nil, errors.New("only WKST=MO is supported")
+			}
+		default:
+			return nil, errors.Errorf("unsupported RRULE part %s", key)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", key)
+		}
+	}
+	if rule.Freq == "" {
+		return nil, errors.New("RRULE must have FREQ")
+	}
+	if rule.Freq != FreqMonthly {
+		for _, d := range rule.ByDay {
+			if d.N != 0 {
+				return nil, errors.Errorf("BYDAY %s is only allowed with FREQ=%s", d, FreqMonthly)
+			}
+		}
+	}
+	return rule, nil
+}
+
+func parseRRuleDay(in string) (RRuleDay, error) {
+	if len(in) < 2 {
+		return RRuleDay{}, errors.Errorf("invalid BYDAY %q", in)
+	}
+	d := RRuleDay{}
+	name := in[len(in)-2:]
+	found := false
+	for i, wd := range rruleWeekdays {
+		if wd == name {
+			d.Weekday = time.Weekday(i)
+			found = true
+		}
+	}
+	if !found {
+		return RRuleDay{}, errors.Errorf("invalid BYDAY %q", in)
+	}
+	if n := in[:len(in)-2]; n != "" {
+		var err error
+		d.N, err = strconv.Atoi(n)
+		if err != nil || d.N == 0 || d.N < -5 || d.N > 5 {
+			return RRuleDay{}, errors.Errorf("invalid BYDAY %q", in)
+		}
+	}
+	return d, nil
+}
+
+func parseRRuleInts(in string, min, max int) ([]int, error) {
+	out := []int{}
+	for _, v := range strings.Split(in, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < min || n > max {
+			return nil, errors.Errorf("%q must be %v-%v", v, min, max)
+		}
+		out = append(out, n)
+	}
+	sort.Ints(out)
+	return out, nil
+}
+
+func (rule *RRule) String() string {
+	parts := []string{"FREQ=" + rule.Freq}
+	if rule.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%v", rule.Interval))
+	}
+	if len(rule.ByDay) > 0 {
+		days := []string{}
+		for _, d := range rule.ByDay {
+			days = append(days, d.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	for _, by := range []struct {
+		name   string
+		values []int
+	}{
+		{"BYHOUR", rule.ByHour},
+		{"BYMINUTE", rule.ByMinute},
+	} {
+		if len(by.values) == 0 {
+			continue
+		}
+		vv := []string{}
+		for _, v := range by.values {
+			vv = append(vv, strconv.Itoa(v))
+		}
+		parts = append(parts, by.name+"="+strings.Join(vv, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrence returns the num-th (0-based) occurrence of the rule anchored at
+// dtstart. The 0th occurrence is the first one at, or after dtstart.
+func (rule *RRule) Occurrence(dtstart time.Time, num int) time.Time {
+	t := rule.next(dtstart, dtstart.Add(-time.Nanosecond))
+	for i := 0; i < num && !t.IsZero(); i++ {
+		t = rule.next(dtstart, t)
+	}
+	return t
+}
+
+// maxRRulePeriods bounds the search for the next occurrence, for the rules
+// that match rarely, or never, e.g. the 5th Monday of every 12th month.
+const maxRRulePeriods = 1000
+
+// next returns the first occurrence after t, or zero time if there is none.
+func (rule *RRule) next(dtstart, t time.Time) time.Time {
+	first := rule.periodStart(dtstart, 0)
+
+	// Skip the periods that end before t.
+	k := 0
+	if t.After(first) {
+		switch rule.Freq {
+		case FreqDaily:
+			k = int(t.Sub(first).Hours()/24) / rule.Interval
+		case FreqWeekly:
+			k = int(t.Sub(first).Hours()/24/7) / rule.Interval
+		case FreqMonthly:
+			k = ((t.Year()-first.Year())*12 + int(t.Month()-first.Month())) / rule.Interval
+		}
+		k--
+		if k < 0 {
+			k = 0
+		}
+	}
+
+	for end := k + maxRRulePeriods; k < end; k++ {
+		for _, c := range rule.candidates(dtstart, rule.periodStart(dtstart, k)) {
+			if !c.Before(dtstart) && c.After(t) {
+				return c
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// periodStart returns the midnight that starts the k-th period of the rule.
+func (rule *RRule) periodStart(dtstart time.Time, k int) time.Time {
+	y, m, d := dtstart.Date()
+	loc := dtstart.Location()
+	switch rule.Freq {
+	case FreqWeekly:
+		monday := d - (int(dtstart.Weekday())+6)%7
+		return time.Date(y, m, monday+7*k*rule.Interval, 0, 0, 0, 0, loc)
+	case FreqMonthly:
+		return time.Date(y, m+time.Month(k*rule.Interval), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, m, d+k*rule.Interval, 0, 0, 0, 0, loc)
+	}
+}
+
+// candidates returns the occurrences within the period, in order.
+func (rule *RRule) candidates(dtstart, start time.Time) []time.Time {
+	days := []time.Time{}
+	matchesWeekday := func(day time.Time) bool {
+		if len(rule.ByDay) == 0 {
+			return rule.Freq != FreqWeekly || day.Weekday() == dtstart.Weekday()
+		}
+		for _, d := range rule.ByDay {
+			if d.Weekday == day.Weekday() {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch rule.Freq {
+	case FreqDaily:
+		if matchesWeekday(start) {
+			days = append(days, start)
+		}
+
+	case FreqWeekly:
+		for i := 0; i < 7; i++ {
+			day := start.AddDate(0, 0, i)
+			if matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+
+	case FreqMonthly:
+		monthDays := []time.Time{}
+		for day := start; day.Month() == start.Month(); day = day.AddDate(0, 0, 1) {
+			monthDays = append(monthDays, day)
+		}
+		if len(rule.ByDay) == 0 {
+			if dtstart.Day() <= len(monthDays) {
+				days = append(days, monthDays[dtstart.Day()-1])
+			}
+			break
+		}
+		for _, day := range monthDays {
+			for _, d := range rule.ByDay {
+				if d.Weekday != day.Weekday() {
+					continue
+				}
+				nth := (day.Day()-1)/7 + 1
+				nthFromEnd := -((len(monthDays)-day.Day())/7 + 1)
+				if d.N == 0 || d.N == nth || d.N == nthFromEnd {
+					days = append(days, day)
+					break
+				}
+			}
+		}
+	}
+
+	hours := rule.ByHour
+	if len(hours) == 0 {
+		hours = []int{dtstart.Hour()}
+	}
+	minutes := rule.ByMinute
+	if len(minutes) == 0 {
+		minutes = []int{dtstart.Minute()}
+	}
+	out := []time.Time{}
+	for _, day := range days {
+		for _, h := range hours {
+			for _, m := range minutes {
+				out = append(out, time.Date(day.Year(), day.Month(), day.Day(), h, m, dtstart.Second(), 0, dtstart.Location()))
+			}
+		}
+	}
+	return out
+}
+
+// AverageDuration returns the average time between the occurrences, over a
+// year, or over the interval if it is longer.
+func (rule *RRule) AverageDuration() time.Duration {
+	// A Monday, so that the weekly rules start on a period boundary.
+	dtstart := time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)
+	span := 366 * 24 * time.Hour
+	if rule.Freq == FreqMonthly {
+		span *= time.Duration(rule.Interval)
+	}
+	first := rule.Occurrence(dtstart, 0)
+	if first.IsZero() {
+		return 0
+	}
+	last := first
+	n := 0
+	for last.Sub(first) < span {
+		next := rule.next(dtstart, last)
+		if next.IsZero() {
+			break
+		}
+		last = next
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return last.Sub(first) / time.Duration(n)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRRule(t *testing.T) {
+	for _, tc := range []struct {
+		in            string
+		expected      string
+		expectedError string
+	}{
+		{in: "FREQ=DAILY", expected: "FREQ=DAILY"},
+		{in: "rrule:freq=weekly;interval=2;byday=mo,th", expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{in: "FREQ=MONTHLY;BYDAY=1MO,-1FR", expected: "FREQ=MONTHLY;BYDAY=1MO,-1FR"},
+		{in: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=17,9;BYMINUTE=30", expected: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,17;BYMINUTE=30"},
+		{in: "BYDAY=MO", expectedError: "RRULE must have FREQ"},
+		{in: "FREQ=YEARLY", expectedError: "unsupported FREQ YEARLY, use DAILY, WEEKLY, or MONTHLY"},
+		{in: "FREQ=WEEKLY;BYDAY=1MO", expectedError: "BYDAY 1MO is only allowed with FREQ=MONTHLY"},
+		{in: "FREQ=DAILY;BYHOUR=24", expectedError: `invalid BYHOUR: "24" must be 0-23`},
+		{in: "FREQ=DAILY;COUNT=3", expectedError: "unsupported RRULE part COUNT"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			rule, err := ParseRRule(tc.in)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rule.String())
+		})
+	}
+}
+
+func TestRRuleOccurrence(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		rule     string
+		dtstart  string
+		expected []string
+	}{
+		{
+			name:    "workdays",
+			rule:    Workdays,
+			dtstart: "2020-03-05T09:00", // Thursday
+			expected: []string{
+				"2020-03-05T09:00", "2020-03-06T09:00", "2020-03-09T09:00", "2020-03-10T09:00",
+			},
+		},
+		{
+			name:    "workdays from Saturday",
+			rule:    Workdays,
+			dtstart: "2020-03-07T09:00",
+			expected: []string{
+				"2020-03-09T09:00", "2020-03-10T09:00",
+			},
+		},
+		{
+			name:    "every other week on Monday and Thursday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			dtstart: "2020-03-03T10:00", // Tuesday
+			expected: []string{
+				"2020-03-05T10:00", "2020-03-16T10:00", "2020-03-19T10:00", "2020-03-30T10:00",
+			},
+		},
+		{
+			name:    "first Monday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=1MO",
+			dtstart: "2020-01-01T09:00",
+			expected: []string{
+				"2020-01-06T09:00", "2020-02-03T09:00", "2020-03-02T09:00", "2020-04-06T09:00",
+			},
+		},
+		{
+			name:    "last Friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: "2020-01-01",
+			expected: []string{
+				"2020-01-31", "2020-02-28", "2020-03-27",
+			},
+		},
+		{
+			name:    "monthly on the 31st skips short months",
+			rule:    "FREQ=MONTHLY",
+			dtstart: "2020-01-31",
+			expected: []string{
+				"2020-01-31", "2020-03-31", "2020-05-31",
+			},
+		},
+		{
+			name:    "business hours, twice a day",
+			rule:    "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,13",
+			dtstart: "2020-03-06", // Friday
+			expected: []string{
+				"2020-03-06T09:00", "2020-03-06T13:00", "2020-03-09T09:00", "2020-03-09T13:00",
+			},
+		},
+		{
+			name:    "every 3 days",
+			rule:    "FREQ=DAILY;INTERVAL=3",
+			dtstart: "2020-02-27T08:00",
+			expected: []string{
+				"2020-02-27T08:00", "2020-03-01T08:00", "2020-03-04T08:00",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseRRule(tc.rule)
+			require.NoError(t, err)
+			dtstart := MustParseTime(tc.dtstart).Time
+			for n, expected := range tc.expected {
+				require.Equal(t, expected, NewTime(rule.Occurrence(dtstart, n)).String(), n)
+			}
+		})
+	}
+}
+
+func TestRRuleAverageDuration(t *testing.T) {
+	for rule, expected := range map[string]time.Duration{
+		"FREQ=DAILY":                  24 * time.Hour,
+		"FREQ=WEEKLY;INTERVAL=2":      14 * 24 * time.Hour,
+		Workdays:                      (7 * 24 * time.Hour) / 5,
+		"FREQ=WEEKLY;BYDAY=MO,WE,FR":  (7 * 24 * time.Hour) / 3,
+		"FREQ=MONTHLY;BYDAY=1MO":      730 * time.Hour,
+		"FREQ=DAILY;BYHOUR=0,6,12,18": 6 * time.Hour,
+	} {
+		t.Run(rule, func(t *testing.T) {
+			r, err := ParseRRule(rule)
+			require.NoError(t, err)
+			require.InDelta(t, expected.Hours(), r.AverageDuration().Hours(), expected.Hours()*0.02)
+		})
+	}
+}
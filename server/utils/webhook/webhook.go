@@ -0,0 +1,195 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+// Package webhook delivers JSON payloads to outgoing webhooks. The payloads
+// are signed with HMAC-SHA256 of the webhook's secret, and the failed
+// deliveries are retried with an exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+)
+
+const (
+	HeaderEvent     = "X-Solar-Lottery-Event"
+	HeaderDelivery  = "X-Solar-Lottery-Delivery"
+	HeaderSignature = "X-Solar-Lottery-Signature"
+
+	signaturePrefix = "sha256="
+	queueSize       = 1000
+	idleTimeout     = time.Minute
+)
+
+// Request is a payload to deliver to a webhook.
+type Request struct {
+	URL     string
+	Secret  string
+	Event   string
+	Payload []byte
+}
+
+// Sign returns the signature of the payload, as sent in HeaderSignature.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of the payload, for the receivers written in Go.
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
+
+// Sender delivers the requests in the background. Each URL has its own
+// queue, delivered one request at a time in the order they were sent, so
+// that the receivers see the events in the order they happened, and a slow
+// or failing receiver does not hold up the others.
+type Sender struct {
+	Client *http.Client
+	Logger bot.Logger
+
+	// Retries is the number of times a failed delivery is retried; the
+	// delay before a retry starts at Backoff, and doubles each time.
+	Retries int
+	Backoff time.Duration
+
+	lock      sync.Mutex
+	queues    map[string]chan *Request
+	done      chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+func NewSender(logger bot.Logger) *Sender {
+	return &Sender{
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Logger:  logger,
+		Retries: 5,
+		Backoff: time.Second,
+	}
+}
+
+// Send queues the request for delivery, and does not wait for it. The
+// requests sent after Close, or when the URL's queue is full, are dropped.
+func (s *Sender) Send(req *Request) {
+	s.start()
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	queue := s.queues[req.URL]
+	if queue == nil {
+		queue = make(chan *Request, queueSize)
+		s.queues[req.URL] = queue
+		go s.run(req.URL, queue)
+	}
+	select {
+	case queue <- req:
+	default:
+		s.Logger.Errorf("webhook %s to %s dropped: too many pending deliveries", req.Event, req.URL)
+	}
+}
+
+// Close stops the deliveries, the pending ones are dropped.
+func (s *Sender) Close() {
+	s.start()
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *Sender) start() {
+	s.startOnce.Do(func() {
+		s.queues = map[string]chan *Request{}
+		s.done = make(chan struct{})
+	})
+}
+
+// run delivers the URL's queue. It exits once the queue has been idle for
+// idleTimeout, and Send starts a new one when needed.
+func (s *Sender) run(url string, queue chan *Request) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case req := <-queue:
+			err := s.deliver(req)
+			if err != nil {
+				s.Logger.Warnf("webhook %s to %s failed: %v", req.Event, req.URL, err)
+			}
+		case <-time.After(idleTimeout):
+			// Send holds the lock while queueing, so nothing can be queued
+			// once the queue is removed.
+			s.lock.Lock()
+			if len(queue) == 0 {
+				delete(s.queues, url)
+				s.lock.Unlock()
+				return
+			}
+			s.lock.Unlock()
+		}
+	}
+}
+
+func (s *Sender) deliver(req *Request) error {
+	// The retries have the same delivery ID, for the receivers to ignore
+	// the duplicates.
+	deliveryID := model.NewId()
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(req, deliveryID)
+		if err == nil || !retry || attempt >= s.Retries {
+			return err
+		}
+		select {
+		case <-time.After(s.Backoff << uint(attempt)):
+		case <-s.done:
+			return err
+		}
+	}
+}
+
+// post makes one delivery attempt. The network errors, 429, and 5xx
+// responses are retried; the other responses are final.
+func (s *Sender) post(req *Request, deliveryID string) (retry bool, err error) {
+	httpReq, err := http.NewRequest(http.MethodPost, req.URL, bytes.NewReader(req.Payload))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, deliveryID)
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, req.Payload))
+
+	resp, err := s.Client.Do(httpReq)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, errors.Errorf("response %s", resp.Status)
+	default:
+		return false, errors.Errorf("response %s", resp.Status)
+	}
+}
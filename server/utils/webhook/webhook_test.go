@@ -0,0 +1,133 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-solar-lottery/server/utils/bot"
+)
+
+type received struct {
+	event      string
+	deliveryID string
+	signature  string
+	payload    string
+}
+
+func testServer(statuses ...int) (*httptest.Server, chan received) {
+	ch := make(chan received, 10)
+	n := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := ioutil.ReadAll(r.Body)
+		ch <- received{
+			event:      r.Header.Get(HeaderEvent),
+			deliveryID: r.Header.Get(HeaderDelivery),
+			signature:  r.Header.Get(HeaderSignature),
+			payload:    string(payload),
+		}
+		status := http.StatusOK
+		if n < len(statuses) {
+			status = statuses[n]
+		}
+		n++
+		w.WriteHeader(status)
+	}))
+	return server, ch
+}
+
+func receive(t *testing.T, ch chan received) received {
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "webhook not received")
+	}
+	return received{}
+}
+
+func testSender() *Sender {
+	s := NewSender(&bot.NilLogger{})
+	s.Backoff = time.Millisecond
+	s.Retries = 2
+	return s
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac secret
+	sig := Sign("secret", []byte(`{"a":1}`))
+	require.Equal(t, "sha256=aa9e2e3575f5d7098b6caccd790888c36d5fdb63342a73bada2d6a51747a8494", sig)
+	require.True(t, Verify("secret", []byte(`{"a":1}`), sig))
+	require.False(t, Verify("other", []byte(`{"a":1}`), sig))
+}
+
+func TestSender(t *testing.T) {
+	t.Run("in order", func(t *testing.T) {
+		server, ch := testServer()
+		defer server.Close()
+		s := testSender()
+		defer s.Close()
+
+		s.Send(&Request{URL: server.URL, Secret: "s", Event: "one", Payload: []byte(`1`)})
+		s.Send(&Request{URL: server.URL, Secret: "s", Event: "two", Payload: []byte(`2`)})
+		r := receive(t, ch)
+		require.Equal(t, "one", r.event)
+		require.Equal(t, "1", r.payload)
+		require.True(t, Verify("s", []byte(r.payload), r.signature))
+		r = receive(t, ch)
+		require.Equal(t, "two", r.event)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		server, ch := testServer(http.StatusInternalServerError, http.StatusTooManyRequests)
+		defer server.Close()
+		s := testSender()
+		defer s.Close()
+
+		s.Send(&Request{URL: server.URL, Secret: "s", Event: "one", Payload: []byte(`1`)})
+		first := receive(t, ch)
+		require.NotEmpty(t, first.deliveryID)
+		require.Equal(t, first, receive(t, ch))
+		require.Equal(t, first, receive(t, ch))
+		select {
+		case <-ch:
+			require.FailNow(t, "delivered after success")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("failing URL does not block others", func(t *testing.T) {
+		failing, failed := testServer(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+		defer failing.Close()
+		server, ch := testServer()
+		defer server.Close()
+		s := testSender()
+		s.Backoff = time.Hour
+		defer s.Close()
+
+		s.Send(&Request{URL: failing.URL, Secret: "s", Event: "one", Payload: []byte(`1`)})
+		require.Equal(t, "one", receive(t, failed).event)
+		// The failing URL waits for an hour to retry.
+		s.Send(&Request{URL: server.URL, Secret: "s", Event: "two", Payload: []byte(`2`)})
+		require.Equal(t, "two", receive(t, ch).event)
+	})
+
+	t.Run("no retry on 4xx", func(t *testing.T) {
+		server, ch := testServer(http.StatusBadRequest)
+		defer server.Close()
+		s := testSender()
+		defer s.Close()
+
+		s.Send(&Request{URL: server.URL, Secret: "s", Event: "one", Payload: []byte(`1`)})
+		s.Send(&Request{URL: server.URL, Secret: "s", Event: "two", Payload: []byte(`2`)})
+		require.Equal(t, "one", receive(t, ch).event)
+		require.Equal(t, "two", receive(t, ch).event)
+	})
+}